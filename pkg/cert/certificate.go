@@ -17,16 +17,17 @@
 package cert
 
 import (
+	"bytes"
 	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
 	"fmt"
 	"time"
 
 	"k8s.io/client-go/util/cert"
 	"k8s.io/client-go/util/keyutil"
-	"k8s.io/kubernetes/cmd/kubeadm/app/util/pkiutil"
 )
 
 type info struct {
@@ -34,6 +35,8 @@ type info struct {
 	key    []byte
 	cacert []byte
 	cakey  []byte
+
+	previous []Generation
 }
 
 func (this *info) Cert() []byte {
@@ -52,6 +55,10 @@ func (this *info) CAKey() []byte {
 	return this.cakey
 }
 
+func (this *info) Previous() []Generation {
+	return this.previous
+}
+
 func NewCertInfo(cert []byte, key []byte, cacert []byte, cakey []byte) CertificateInfo {
 	return &info{
 		cert:   cert,
@@ -61,34 +68,120 @@ func NewCertInfo(cert []byte, key []byte, cacert []byte, cakey []byte) Certifica
 	}
 }
 
-func newPrivateKey() (*rsa.PrivateKey, error) {
-	signer, err := pkiutil.NewPrivateKey()
-	if err != nil {
-		return nil, err
-	}
-	key, ok := signer.(*rsa.PrivateKey)
-	if !ok {
-		return nil, fmt.Errorf("not a private key: %t", key)
+// NewCertInfoWithHistory creates a certificate info keeping the given
+// previous certificate generations (latest first).
+func NewCertInfoWithHistory(cert []byte, key []byte, cacert []byte, cakey []byte, previous []Generation) CertificateInfo {
+	return &info{
+		cert:     cert,
+		key:      key,
+		cacert:   cacert,
+		cakey:    cakey,
+		previous: previous,
 	}
-	return key, nil
+}
+
+func newPrivateKey(env *Environment) (*rsa.PrivateKey, error) {
+	return rsa.GenerateKey(env.Reader(), 2048)
 }
 
 // EncodePrivateKeyPEM returns PEM-encoded private key data
-func encodePrivateKeyPEM(key *rsa.PrivateKey) []byte {
+func encodePrivateKeyPEM(key *rsa.PrivateKey, pkcs8 bool) ([]byte, error) {
+	if pkcs8 {
+		bytes, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		block := pem.Block{
+			Type:  keyutil.PrivateKeyBlockType,
+			Bytes: bytes,
+		}
+		return pem.EncodeToMemory(&block), nil
+	}
 	block := pem.Block{
-		Type:  pkiutil.RSAPrivateKeyBlockType,
+		Type:  keyutil.RSAPrivateKeyBlockType,
 		Bytes: x509.MarshalPKCS1PrivateKey(key),
 	}
-	return pem.EncodeToMemory(&block)
+	return pem.EncodeToMemory(&block), nil
+}
+
+// Settings describe the attributes used to generate a new certificate
+type Settings struct {
+	CommonName string
+	DNSName    string
+
+	// DNSNames are additional DNS names of the server certificate
+	DNSNames []string
+
+	// CAOverlap is the period the previous CA certificate is kept in the
+	// CA bundle after a CA rollover. During this period clients
+	// may still use the old CA bundle to verify newly issued certificates.
+	CAOverlap time.Duration
+
+	// CARest is the minimal remaining validity period of the CA
+	// certificate. A CA certificate expiring earlier is replaced by the
+	// renewal (default 5 days).
+	CARest time.Duration
+
+	// PKCS8 selects the PKCS#8 encoding (PRIVATE KEY) instead of
+	// PKCS#1 (RSA PRIVATE KEY) for generated private keys.
+	// Existing keys are accepted in both encodings.
+	PKCS8 bool
+
+	// Subject contains the additional subject fields (Organization,
+	// OrganizationalUnit, Country, Locality, ...) used for the CA and the
+	// server certificate. Its CommonName is ignored, the common names are
+	// derived from the CommonName setting.
+	Subject pkix.Name
+
+	// Extensions are additional extensions added to the server certificate
+	Extensions []pkix.Extension
+
+	// Environment provides the clock and randomness used for the
+	// certificate generation (nil for the system defaults)
+	Environment *Environment
+
+	// KeepGenerations is the number of previous server certificates (and
+	// keys) retained in the certificate info after a renewal.
+	KeepGenerations int
+}
+
+// AllDNSNames returns the DNS name and all additional DNS names
+func (this *Settings) AllDNSNames() []string {
+	var names []string
+	if this.DNSName != "" {
+		names = append(names, this.DNSName)
+	}
+	return append(names, this.DNSNames...)
+}
+
+func (this *Settings) subject(commonname string) pkix.Name {
+	name := this.Subject
+	name.CommonName = commonname
+	return name
 }
 
 func UpdateCertificate(old CertificateInfo, commonname, dnsname string, duration time.Duration) (CertificateInfo, error) {
+	if old != nil && IsValid(old, dnsname, duration) {
+		return old, nil
+	}
+	return RenewCertificate(old, &Settings{CommonName: commonname, DNSName: dnsname})
+}
+
+// RenewCertificate generates a new server certificate. The CA of the old
+// certificate info is reused as long as it is still valid.
+func RenewCertificate(old CertificateInfo, settings *Settings) (CertificateInfo, error) {
 	new := &info{}
 	if old != nil {
 		new.cert = old.Cert()
 		new.key = old.Key()
 		new.cacert = old.CACert()
 		new.cakey = old.CAKey()
+		if settings.KeepGenerations > 0 && len(old.Cert()) > 0 {
+			new.previous = append([]Generation{{Cert: old.Cert(), Key: old.Key()}}, PreviousGenerations(old)...)
+			if len(new.previous) > settings.KeepGenerations {
+				new.previous = new.previous[:settings.KeepGenerations]
+			}
+		}
 	}
 
 	var caKey *rsa.PrivateKey
@@ -98,65 +191,77 @@ func UpdateCertificate(old CertificateInfo, commonname, dnsname string, duration
 	var err error
 	var ok bool
 
-	if !IsValid(new, dnsname, duration) {
-		fmt.Printf("not valid\n")
-		if new.cacert != nil {
-			fmt.Printf("cacert found\n")
-			ok = Valid(new.cakey, new.cacert, new.cacert, "", 5*time.Hour*24)
-			if ok {
-				fmt.Printf("cacert not valid\n")
-				k, err := keyutil.ParsePrivateKeyPEM(new.cakey)
-				if err != nil {
-					ok = false
-				} else {
-					caKey, ok = k.(*rsa.PrivateKey)
-				}
-				certs, err := cert.ParseCertsPEM(new.cacert)
-				if err != nil {
-					ok = false
-				} else {
-					caCert = certs[0]
-				}
-			}
+	env := settings.Environment
+	if new.cacert != nil {
+		fmt.Printf("cacert found\n")
+		rest := settings.CARest
+		if rest <= 0 {
+			rest = 5 * time.Hour * 24
 		}
-		if new.cacert == nil || !ok {
-			fmt.Printf("generate cacert\n")
-
-			caKey, err = newPrivateKey()
+		ok = valid(env, new.cakey, new.cacert, new.cacert, "", rest)
+		if ok {
+			fmt.Printf("cacert not valid\n")
+			k, err := keyutil.ParsePrivateKeyPEM(new.cakey)
 			if err != nil {
-				return nil, fmt.Errorf("failed to create the CA key pair: %s", err)
+				ok = false
+			} else {
+				caKey, ok = k.(*rsa.PrivateKey)
 			}
-			new.cakey = encodePrivateKeyPEM(caKey)
-			caCert, err = cert.NewSelfSignedCACert(cert.Config{CommonName: "webhook-cert-ca:" + commonname}, caKey)
+			certs, err := cert.ParseCertsPEM(new.cacert)
 			if err != nil {
-				return nil, fmt.Errorf("failed to create the CA cert: %s", err)
+				ok = false
+			} else {
+				caCert = certs[0]
 			}
-			new.cacert = pkiutil.EncodeCertPEM(caCert)
 		}
+	}
+	if new.cacert == nil || !ok {
+		fmt.Printf("generate cacert\n")
+		prev := new.cacert
 
-		fmt.Printf("generate key\n")
-		newKey, err = newPrivateKey()
+		caKey, err = newPrivateKey(env)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create the server key pair: %s", err)
+			return nil, fmt.Errorf("failed to create the CA key pair: %s", err)
 		}
-		new.key = encodePrivateKeyPEM(newKey)
-		fmt.Printf("generate cert\n")
-		newCert, err = pkiutil.NewSignedCert(
-			&cert.Config{
-				CommonName: "client:" + commonname,
-				AltNames: cert.AltNames{
-					DNSNames: []string{dnsname},
-				},
-				Usages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
-			},
-			newKey, caCert, caKey)
+		new.cakey, err = encodePrivateKeyPEM(caKey, settings.PKCS8)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create the server cert: %s", err)
+			return nil, fmt.Errorf("failed to encode the CA key: %s", err)
+		}
+		caCert, err = NewSelfSignedCACert(env, settings.subject("webhook-cert-ca:"+settings.CommonName), caKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the CA cert: %s", err)
+		}
+		new.cacert = EncodeCertPEM(caCert)
+		if settings.CAOverlap > 0 && prev != nil {
+			new.cacert = append(new.cacert, prev...)
 		}
-		new.cert = pkiutil.EncodeCertPEM(newCert)
-		return new, nil
 	}
-	return old, nil
+	new.cacert = PruneCABundle(env, new.cacert, settings.CAOverlap)
+
+	fmt.Printf("generate key\n")
+	newKey, err = newPrivateKey(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the server key pair: %s", err)
+	}
+	new.key, err = encodePrivateKeyPEM(newKey, settings.PKCS8)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the server key: %s", err)
+	}
+	fmt.Printf("generate cert\n")
+	newCert, err = NewSignedCert(env,
+		&CertConfig{
+			Subject:  settings.subject("client:" + settings.CommonName),
+			DNSNames: settings.AllDNSNames(),
+			Usages:   []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+
+			Extensions: settings.Extensions,
+		},
+		newKey, caCert, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the server cert: %s", err)
+	}
+	new.cert = EncodeCertPEM(newCert)
+	return new, nil
 }
 
 func IsValid(info CertificateInfo, dnsname string, duration time.Duration) bool {
@@ -172,6 +277,10 @@ func IsValid(info CertificateInfo, dnsname string, duration time.Duration) bool
 }
 
 func Valid(key []byte, cert []byte, cacert []byte, dnsname string, duration time.Duration) bool {
+	return valid(nil, key, cert, cacert, dnsname, duration)
+}
+
+func valid(env *Environment, key []byte, cert []byte, cacert []byte, dnsname string, duration time.Duration) bool {
 
 	if len(cert) == 0 || len(key) == 0 || len(cacert) == 0 {
 		fmt.Printf("something empty\n")
@@ -199,12 +308,60 @@ func Valid(key []byte, cert []byte, cacert []byte, dnsname string, duration time
 		fmt.Printf("cannot parse cert\n")
 		return false
 	}
+	// critical extensions unknown to the x509 package are application
+	// specific and must not invalidate the certificate
+	c.UnhandledCriticalExtensions = nil
 	ops := x509.VerifyOptions{
 		DNSName:     dnsname,
 		Roots:       pool,
-		CurrentTime: time.Now().Add(duration),
+		CurrentTime: env.Now().Add(duration),
 	}
 	_, err = c.Verify(ops)
 	fmt.Printf("val: %s\n", err)
 	return err == nil
 }
+
+// PruneCABundle removes all previous CA certificates from a CA bundle
+// whose overlap period with the actual (first) CA certificate is exceeded.
+func PruneCABundle(env *Environment, bundle []byte, overlap time.Duration) []byte {
+	certs, err := cert.ParseCertsPEM(bundle)
+	if err != nil || len(certs) < 2 {
+		return bundle
+	}
+	now := env.Now()
+	limit := certs[0].NotBefore.Add(overlap)
+	result := EncodeCertPEM(certs[0])
+	for _, c := range certs[1:] {
+		if now.Before(limit) && now.Before(c.NotAfter) {
+			result = append(result, EncodeCertPEM(c)...)
+		}
+	}
+	return result
+}
+
+// HasRemainingLifetime checks whether the given certificate still has at least
+// the given percentage of its total lifetime left.
+func HasRemainingLifetime(env *Environment, cert []byte, percent int) bool {
+	block, _ := pem.Decode(cert)
+	if block == nil {
+		return false
+	}
+	c, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false
+	}
+	lifetime := c.NotAfter.Sub(c.NotBefore)
+	rest := c.NotAfter.Sub(env.Now())
+	return rest*100 >= lifetime*time.Duration(percent)
+}
+
+// HasExtension checks whether the given certificate contains the given
+// extension with the same value.
+func HasExtension(c *x509.Certificate, ext pkix.Extension) bool {
+	for _, e := range c.Extensions {
+		if e.Id.Equal(ext.Id) && e.Critical == ext.Critical && bytes.Equal(e.Value, ext.Value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package cert
+
+import (
+	cryptorand "crypto/rand"
+	"io"
+	"time"
+)
+
+// Clock provides the actual time
+type Clock interface {
+	Now() time.Time
+}
+
+// Environment provides the clock and the source of randomness used for
+// certificate generation and validation. A nil environment or unset
+// fields use the system clock and crypto/rand.
+type Environment struct {
+	Clock Clock
+	Rand  io.Reader
+}
+
+func (this *Environment) Now() time.Time {
+	if this == nil || this.Clock == nil {
+		return time.Now()
+	}
+	return this.Clock.Now()
+}
+
+func (this *Environment) Reader() io.Reader {
+	if this == nil || this.Rand == nil {
+		return cryptorand.Reader
+	}
+	return this.Rand
+}
@@ -22,3 +22,24 @@ type CertificateInfo interface {
 	CACert() []byte
 	CAKey() []byte
 }
+
+// Generation is a previously used server certificate together with its key.
+type Generation struct {
+	Cert []byte
+	Key  []byte
+}
+
+// CertificateHistory is optionally implemented by a CertificateInfo
+// keeping previous certificate generations (latest first).
+type CertificateHistory interface {
+	Previous() []Generation
+}
+
+// PreviousGenerations returns the previous generations kept by a
+// certificate info, if it supports a certificate history.
+func PreviousGenerations(info CertificateInfo) []Generation {
+	if h, ok := info.(CertificateHistory); ok {
+		return h.Previous()
+	}
+	return nil
+}
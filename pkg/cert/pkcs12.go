@@ -0,0 +1,105 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package cert
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// EncodePKCS12 exports the server certificate, its private key and the CA
+// certificates of a certificate info as password protected PKCS#12 bundle.
+// The CA key is never exported. Keys and certificates are encrypted with
+// pbeWithSHAAnd3-KeyTripleDES-CBC and the integrity is protected by a
+// HMAC-SHA1 MAC, the format accepted by all Java versions.
+func EncodePKCS12(env *Environment, info CertificateInfo, password string) ([]byte, error) {
+	return encodePKCS12(pkcs12.LegacyDES, env, info, password)
+}
+
+// EncodeModernPKCS12 exports a certificate info like EncodePKCS12, but
+// uses PBES2 with AES-256-CBC and a HMAC-SHA256 MAC, the default of
+// OpenSSL 3 and recent Java versions (Java 8u301, 11.0.12 or newer).
+func EncodeModernPKCS12(env *Environment, info CertificateInfo, password string) ([]byte, error) {
+	return encodePKCS12(pkcs12.Modern2023, env, info, password)
+}
+
+func encodePKCS12(encoder *pkcs12.Encoder, env *Environment, info CertificateInfo, password string) ([]byte, error) {
+	certs, err := cert.ParseCertsPEM(info.Cert())
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %s", err)
+	}
+	key, err := keyutil.ParsePrivateKeyPEM(info.Key())
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %s", err)
+	}
+	if len(info.CACert()) > 0 {
+		cacerts, err := cert.ParseCertsPEM(info.CACert())
+		if err != nil {
+			return nil, fmt.Errorf("invalid CA certificate: %s", err)
+		}
+		certs = append(certs, cacerts...)
+	}
+	data, err := encoder.WithRand(env.Reader()).Encode(key, certs[0], certs[1:], password)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode PKCS#12 data: %s", err)
+	}
+	return data, nil
+}
+
+// DecodePKCS12 parses a password protected PKCS#12 bundle, for example
+// created by EncodePKCS12, EncodeModernPKCS12, OpenSSL or the Java keytool.
+// The first certificate matching the private key is used as server
+// certificate, all other certificates are treated as CA certificates.
+func DecodePKCS12(data []byte, password string) (CertificateInfo, error) {
+	key, leaf, cacerts, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PKCS#12 data: %s", err)
+	}
+	keyData, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	var certPEM, caPEM []byte
+	for _, c := range append([]*x509.Certificate{leaf}, cacerts...) {
+		block := pem.EncodeToMemory(&pem.Block{Type: cert.CertificateBlockType, Bytes: c.Raw})
+		if certPEM == nil && matchesKey(c, key) {
+			certPEM = block
+		} else {
+			caPEM = append(caPEM, block...)
+		}
+	}
+	if certPEM == nil {
+		return nil, fmt.Errorf("no certificate found for private key in PKCS#12 data")
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: keyutil.PrivateKeyBlockType, Bytes: keyData})
+	return NewCertInfo(certPEM, keyPEM, caPEM, nil), nil
+}
+
+func matchesKey(c *x509.Certificate, key interface{}) bool {
+	pub, ok := key.(interface{ Public() crypto.PublicKey })
+	if !ok {
+		return false
+	}
+	eq, ok := pub.Public().(interface{ Equal(crypto.PublicKey) bool })
+	return ok && eq.Equal(c.PublicKey)
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package cert
+
+import (
+	"crypto"
+	cryptorand "crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"k8s.io/client-go/util/cert"
+)
+
+const (
+	// CAValidity is the validity period of generated CA certificates
+	CAValidity = 10 * 365 * 24 * time.Hour
+	// CertificateValidity is the validity period of generated server certificates
+	CertificateValidity = 365 * 24 * time.Hour
+)
+
+// CertConfig describes a certificate to be signed by a CA
+type CertConfig struct {
+	Subject  pkix.Name
+	DNSNames []string
+	Usages   []x509.ExtKeyUsage
+	// Extensions are additional extensions added to the certificate
+	Extensions []pkix.Extension
+}
+
+// NewSelfSignedCACert creates a CA certificate for the given subject
+func NewSelfSignedCACert(env *Environment, subject pkix.Name, key crypto.Signer) (*x509.Certificate, error) {
+	now := env.Now()
+	tmpl := x509.Certificate{
+		SerialNumber:          new(big.Int).SetInt64(0),
+		Subject:               subject,
+		NotBefore:             now.UTC(),
+		NotAfter:              now.Add(CAValidity).UTC(),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	certDERBytes, err := x509.CreateCertificate(env.Reader(), &tmpl, &tmpl, key.Public(), key)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(certDERBytes)
+}
+
+// NewSignedCert creates a certificate signed by the given CA
+func NewSignedCert(env *Environment, cfg *CertConfig, key crypto.Signer, caCert *x509.Certificate, caKey crypto.Signer) (*x509.Certificate, error) {
+	serial, err := cryptorand.Int(env.Reader(), new(big.Int).SetInt64(math.MaxInt64))
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Subject.CommonName) == 0 {
+		return nil, fmt.Errorf("must specify a CommonName")
+	}
+	if len(cfg.Usages) == 0 {
+		return nil, fmt.Errorf("must specify at least one ExtKeyUsage")
+	}
+
+	tmpl := x509.Certificate{
+		Subject:      cfg.Subject,
+		DNSNames:     cfg.DNSNames,
+		SerialNumber: serial,
+		NotBefore:    caCert.NotBefore,
+		NotAfter:     env.Now().Add(CertificateValidity).UTC(),
+		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  cfg.Usages,
+
+		ExtraExtensions: cfg.Extensions,
+	}
+	certDERBytes, err := x509.CreateCertificate(env.Reader(), &tmpl, caCert, key.Public(), caKey)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(certDERBytes)
+}
+
+// EncodeCertPEM returns PEM-encoded certificate data
+func EncodeCertPEM(c *x509.Certificate) []byte {
+	block := pem.Block{
+		Type:  cert.CertificateBlockType,
+		Bytes: c.Raw,
+	}
+	return pem.EncodeToMemory(&block)
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package access
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/cert"
+	"github.com/gardener/controller-manager-library/pkg/certs"
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/certmgmt"
+	"github.com/gardener/controller-manager-library/pkg/logger"
+)
+
+// PollInterval is the interval used to check the certificate access
+// for required renewals or external updates.
+const PollInterval = 10 * time.Minute
+
+// AccessSource is a certificate source based on a certificate access.
+// The certificate is periodically checked and renewed if required.
+// If the access supports change notifications the certificate is
+// reread immediately on external changes.
+type AccessSource struct {
+	certs.Notifiers
+	certs.CABundleChannels
+	lock    sync.RWMutex
+	logger  logger.LogContext
+	access  certmgmt.CertificateAccess
+	config  *certmgmt.Config
+	info    cert.CertificateInfo
+	current *tls.Certificate
+	trigger chan struct{}
+}
+
+var _ certs.NotifyingCertificateSource = &AccessSource{}
+var _ certs.CertificateAuthoritySource = &AccessSource{}
+
+func New(ctx context.Context, logger logger.LogContext, access certmgmt.CertificateAccess, cfg *certmgmt.Config) (*AccessSource, error) {
+	this := &AccessSource{
+		logger:  logger,
+		access:  access,
+		config:  cfg,
+		trigger: make(chan struct{}, 1),
+	}
+	this.Instrument(cfg.CommonName)
+	err := this.ReadCertificate()
+	if err != nil {
+		return nil, err
+	}
+	if w, ok := access.(certmgmt.WatchableCertificateAccess); ok {
+		if err := w.Watch(this.Trigger); err != nil {
+			return nil, fmt.Errorf("cannot watch certificate access: %s", err)
+		}
+	}
+	go this.run(ctx)
+	return this, nil
+}
+
+// Trigger requests an asynchronous reread of the certificate.
+func (this *AccessSource) Trigger() {
+	select {
+	case this.trigger <- struct{}{}:
+	default:
+	}
+}
+
+func (this *AccessSource) run(ctx context.Context) {
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-this.trigger:
+		}
+		if err := this.ReadCertificate(); err != nil {
+			this.logger.Errorf("cannot update certificate: %s", err)
+			this.NotifyFailure()
+		}
+	}
+}
+
+func (this *AccessSource) ReadCertificate() error {
+	info, err := certmgmt.GetCertificate(this.logger, this.access, this.config)
+	if err != nil {
+		return err
+	}
+	tlscert, err := tls.X509KeyPair(info.Cert(), info.Key())
+	if err != nil {
+		return fmt.Errorf("invalid certificate: %s", err)
+	}
+
+	this.lock.Lock()
+	changed := this.info == nil || !bytes.Equal(this.info.Cert(), info.Cert()) || !bytes.Equal(this.info.Key(), info.Key())
+	cachanged := this.info == nil || !bytes.Equal(this.info.CACert(), info.CACert())
+	this.info = info
+	this.current = &tlscert
+	this.lock.Unlock()
+
+	if changed {
+		this.logger.Infof("updated current TLS certificate: %s", certmgmt.Describe(info))
+		this.Notify(info)
+	}
+	if cachanged {
+		this.NotifyCA(info.CACert())
+	}
+	return nil
+}
+
+func (this *AccessSource) GetCertificateInfo() cert.CertificateInfo {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.info
+}
+
+func (this *AccessSource) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.current, nil
+}
+
+func (this *AccessSource) GetCABundle() []byte {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	if this.info == nil {
+		return nil
+	}
+	return this.info.CACert()
+}
+
+func (this *AccessSource) GetCACertificates() []*x509.Certificate {
+	return certs.ParseCABundle(this.GetCABundle())
+}
+
+// WaitForCertificate waits until a valid certificate is available
+func (this *AccessSource) WaitForCertificate(ctx context.Context) error {
+	return certs.WaitForCertificate(ctx, this)
+}
@@ -0,0 +1,352 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package acme
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Minimal ACME (RFC 8555) client supporting the account, order,
+// authorization and finalization flow used by the ACME source.
+
+const (
+	statusPending    = "pending"
+	statusProcessing = "processing"
+	statusReady      = "ready"
+	statusValid      = "valid"
+	statusInvalid    = "invalid"
+)
+
+type directory struct {
+	NewNonce   string `json:"newNonce"`
+	NewAccount string `json:"newAccount"`
+	NewOrder   string `json:"newOrder"`
+}
+
+type identifier struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type problem struct {
+	Type   string `json:"type"`
+	Detail string `json:"detail"`
+	Status int    `json:"status"`
+}
+
+func (this *problem) Error() string {
+	return fmt.Sprintf("acme error %s: %s", this.Type, this.Detail)
+}
+
+type order struct {
+	Status         string       `json:"status"`
+	Identifiers    []identifier `json:"identifiers"`
+	Authorizations []string     `json:"authorizations"`
+	Finalize       string       `json:"finalize"`
+	Certificate    string       `json:"certificate"`
+	Error          *problem     `json:"error"`
+}
+
+type challenge struct {
+	Type   string   `json:"type"`
+	URL    string   `json:"url"`
+	Token  string   `json:"token"`
+	Status string   `json:"status"`
+	Error  *problem `json:"error"`
+}
+
+type authorization struct {
+	Identifier identifier  `json:"identifier"`
+	Status     string      `json:"status"`
+	Wildcard   bool        `json:"wildcard"`
+	Challenges []challenge `json:"challenges"`
+}
+
+type client struct {
+	lock      sync.Mutex
+	http      *http.Client
+	key       *ecdsa.PrivateKey
+	dir       *directory
+	directory string
+	kid       string
+	nonces    []string
+}
+
+func newClient(directoryURL string, key *ecdsa.PrivateKey) *client {
+	return &client{
+		http:      &http.Client{Timeout: 30 * time.Second},
+		key:       key,
+		directory: directoryURL,
+	}
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func (this *client) discover() error {
+	if this.dir != nil {
+		return nil
+	}
+	resp, err := this.http.Get(this.directory)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cannot get ACME directory %s: %s", this.directory, resp.Status)
+	}
+	dir := &directory{}
+	if err := json.NewDecoder(resp.Body).Decode(dir); err != nil {
+		return fmt.Errorf("invalid ACME directory: %s", err)
+	}
+	this.dir = dir
+	return nil
+}
+
+func (this *client) nonce() (string, error) {
+	this.lock.Lock()
+	if n := len(this.nonces); n > 0 {
+		nonce := this.nonces[n-1]
+		this.nonces = this.nonces[:n-1]
+		this.lock.Unlock()
+		return nonce, nil
+	}
+	this.lock.Unlock()
+	resp, err := this.http.Head(this.dir.NewNonce)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	nonce := resp.Header.Get("Replay-Nonce")
+	if nonce == "" {
+		return "", fmt.Errorf("no nonce provided by ACME server")
+	}
+	return nonce, nil
+}
+
+func (this *client) addNonce(resp *http.Response) {
+	if nonce := resp.Header.Get("Replay-Nonce"); nonce != "" {
+		this.lock.Lock()
+		this.nonces = append(this.nonces, nonce)
+		this.lock.Unlock()
+	}
+}
+
+func (this *client) jwk() map[string]string {
+	size := (this.key.Curve.Params().BitSize + 7) / 8
+	return map[string]string{
+		"crv": this.key.Curve.Params().Name,
+		"kty": "EC",
+		"x":   b64(pad(this.key.X, size)),
+		"y":   b64(pad(this.key.Y, size)),
+	}
+}
+
+// thumbprint is the JWK thumbprint (RFC 7638) of the account key
+func (this *client) thumbprint() string {
+	jwk := this.jwk()
+	data := fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, jwk["crv"], jwk["x"], jwk["y"])
+	sum := sha256.Sum256([]byte(data))
+	return b64(sum[:])
+}
+
+func (this *client) keyAuthorization(token string) string {
+	return token + "." + this.thumbprint()
+}
+
+func pad(i *big.Int, size int) []byte {
+	b := i.Bytes()
+	if len(b) >= size {
+		return b
+	}
+	return append(make([]byte, size-len(b)), b...)
+}
+
+func (this *client) sign(url string, payload interface{}) ([]byte, error) {
+	nonce, err := this.nonce()
+	if err != nil {
+		return nil, err
+	}
+	protected := map[string]interface{}{
+		"alg":   "ES256",
+		"nonce": nonce,
+		"url":   url,
+	}
+	if this.kid != "" {
+		protected["kid"] = this.kid
+	} else {
+		protected["jwk"] = this.jwk()
+	}
+	header, err := json.Marshal(protected)
+	if err != nil {
+		return nil, err
+	}
+	body := ""
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		body = b64(data)
+	}
+	input := b64(header) + "." + body
+	digest := sha256.Sum256([]byte(input))
+	r, s, err := ecdsa.Sign(rand.Reader, this.key, digest[:])
+	if err != nil {
+		return nil, err
+	}
+	signature := append(pad(r, 32), pad(s, 32)...)
+	return json.Marshal(map[string]string{
+		"protected": b64(header),
+		"payload":   body,
+		"signature": b64(signature),
+	})
+}
+
+// post sends a signed request. A nil payload results in a POST-as-GET
+// request. Bad nonce errors are retried once.
+func (this *client) post(url string, payload interface{}, result interface{}) (*http.Response, []byte, error) {
+	for retry := 0; ; retry++ {
+		body, err := this.sign(url, payload)
+		if err != nil {
+			return nil, nil, err
+		}
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Header.Set("Content-Type", "application/jose+json")
+		resp, err := this.http.Do(req)
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		this.addNonce(resp)
+		if resp.StatusCode >= 400 {
+			p := &problem{}
+			if json.Unmarshal(data, p) != nil || p.Type == "" {
+				return nil, nil, fmt.Errorf("ACME request %s failed: %s", url, resp.Status)
+			}
+			if strings.HasSuffix(p.Type, ":badNonce") && retry == 0 {
+				continue
+			}
+			return nil, nil, p
+		}
+		if result != nil {
+			if err := json.Unmarshal(data, result); err != nil {
+				return nil, nil, fmt.Errorf("invalid ACME response from %s: %s", url, err)
+			}
+		}
+		return resp, data, nil
+	}
+}
+
+func (this *client) register(email string) error {
+	if err := this.discover(); err != nil {
+		return err
+	}
+	if this.kid != "" {
+		return nil
+	}
+	account := map[string]interface{}{
+		"termsOfServiceAgreed": true,
+	}
+	if email != "" {
+		account["contact"] = []string{"mailto:" + email}
+	}
+	resp, _, err := this.post(this.dir.NewAccount, account, nil)
+	if err != nil {
+		return fmt.Errorf("ACME account registration failed: %s", err)
+	}
+	this.kid = resp.Header.Get("Location")
+	if this.kid == "" {
+		return fmt.Errorf("no account URL provided by ACME server")
+	}
+	return nil
+}
+
+func (this *client) newOrder(domains []string) (*order, string, error) {
+	ids := []identifier{}
+	for _, d := range domains {
+		ids = append(ids, identifier{Type: "dns", Value: d})
+	}
+	o := &order{}
+	resp, _, err := this.post(this.dir.NewOrder, map[string]interface{}{"identifiers": ids}, o)
+	if err != nil {
+		return nil, "", err
+	}
+	return o, resp.Header.Get("Location"), nil
+}
+
+func (this *client) getAuthorization(url string) (*authorization, error) {
+	a := &authorization{}
+	_, _, err := this.post(url, nil, a)
+	return a, err
+}
+
+func (this *client) getOrder(url string) (*order, error) {
+	o := &order{}
+	_, _, err := this.post(url, nil, o)
+	return o, err
+}
+
+func (this *client) accept(c *challenge) error {
+	_, _, err := this.post(c.URL, struct{}{}, nil)
+	return err
+}
+
+func (this *client) finalize(o *order, csr []byte) error {
+	_, _, err := this.post(o.Finalize, map[string]string{"csr": b64(csr)}, nil)
+	return err
+}
+
+func (this *client) certificate(url string) ([]byte, error) {
+	_, data, err := this.post(url, nil, nil)
+	return data, err
+}
+
+// poll calls f until it reports to be done or the timeout is exceeded.
+func poll(timeout time.Duration, f func() (bool, error)) error {
+	limit := time.Now().Add(timeout)
+	for {
+		done, err := f()
+		if err != nil || done {
+			return err
+		}
+		if time.Now().After(limit) {
+			return fmt.Errorf("timeout")
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
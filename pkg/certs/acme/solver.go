@@ -0,0 +1,81 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package acme
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ChallengePath is the URL path prefix of HTTP-01 challenges
+const ChallengePath = "/.well-known/acme-challenge/"
+
+// HTTP01Solver answers HTTP-01 challenges. It must be served on port 80
+// of all requested domains under ChallengePath.
+type HTTP01Solver struct {
+	lock   sync.RWMutex
+	tokens map[string]string
+}
+
+var _ http.Handler = &HTTP01Solver{}
+
+func NewHTTP01Solver() *HTTP01Solver {
+	return &HTTP01Solver{tokens: map[string]string{}}
+}
+
+func (this *HTTP01Solver) present(token, keyAuth string) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.tokens[token] = keyAuth
+}
+
+func (this *HTTP01Solver) cleanup(token string) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	delete(this.tokens, token)
+}
+
+func (this *HTTP01Solver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, ChallengePath) {
+		http.NotFound(w, r)
+		return
+	}
+	this.lock.RLock()
+	keyAuth, ok := this.tokens[strings.TrimPrefix(r.URL.Path, ChallengePath)]
+	this.lock.RUnlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(keyAuth))
+}
+
+// DNSProvider manages the TXT records required for DNS-01 challenges.
+// The record must be created for the fully qualified name
+// _acme-challenge.<domain>. with the given value.
+type DNSProvider interface {
+	Present(domain, fqdn, value string) error
+	CleanUp(domain, fqdn, value string) error
+}
+
+func dns01Record(domain, keyAuth string) (string, string) {
+	sum := sha256.Sum256([]byte(keyAuth))
+	return "_acme-challenge." + strings.TrimPrefix(domain, "*.") + ".", b64(sum[:])
+}
@@ -0,0 +1,398 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package acme
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/cert"
+	"github.com/gardener/controller-manager-library/pkg/certs"
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/certmgmt"
+	"github.com/gardener/controller-manager-library/pkg/logger"
+
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+)
+
+const (
+	// LetsEncryptURL is the directory URL of the Let's Encrypt production environment
+	LetsEncryptURL = "https://acme-v02.api.letsencrypt.org/directory"
+	// LetsEncryptStagingURL is the directory URL of the Let's Encrypt staging environment
+	LetsEncryptStagingURL = "https://acme-staging-v02.api.letsencrypt.org/directory"
+)
+
+// Config describes the certificate requested from an ACME server.
+type Config struct {
+	// DirectoryURL is the ACME directory (default LetsEncryptURL)
+	DirectoryURL string
+	Email        string
+	Domains      []string
+
+	// HTTP01 and DNS01 are the challenge solvers. At least one is required.
+	HTTP01 *HTTP01Solver
+	DNS01  DNSProvider
+
+	// Access persists the certificate, its key and the issuer chain (as CA).
+	Access certmgmt.CertificateAccess
+	// AccountAccess persists the account key (as key of the certificate info).
+	AccountAccess certmgmt.CertificateAccess
+
+	// RenewBefore is the remaining validity the certificate is renewed (default 30 days)
+	RenewBefore time.Duration
+	// Timeout is the maximum duration for validation and issuance (default 5 minutes)
+	Timeout time.Duration
+}
+
+// ACMESource is a certificate source requesting publicly trusted
+// certificates from an ACME server like Let's Encrypt.
+type ACMESource struct {
+	certs.Notifiers
+	certs.CABundleChannels
+	lock    sync.RWMutex
+	logger  logger.LogContext
+	config  Config
+	client  *client
+	info    cert.CertificateInfo
+	current *tls.Certificate
+}
+
+var _ certs.NotifyingCertificateSource = &ACMESource{}
+var _ certs.CertificateAuthoritySource = &ACMESource{}
+
+func New(ctx context.Context, logger logger.LogContext, cfg Config) (*ACMESource, error) {
+	if len(cfg.Domains) == 0 {
+		return nil, fmt.Errorf("no domains configured for ACME certificate")
+	}
+	if cfg.HTTP01 == nil && cfg.DNS01 == nil {
+		return nil, fmt.Errorf("no ACME challenge solver configured")
+	}
+	if cfg.Access == nil || cfg.AccountAccess == nil {
+		return nil, fmt.Errorf("certificate and account access required for ACME source")
+	}
+	if cfg.DirectoryURL == "" {
+		cfg.DirectoryURL = LetsEncryptURL
+	}
+	if cfg.RenewBefore <= 0 {
+		cfg.RenewBefore = 30 * 24 * time.Hour
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Minute
+	}
+	this := &ACMESource{
+		logger: logger,
+		config: cfg,
+	}
+	this.Instrument(cfg.Domains[0])
+	key, err := this.accountKey()
+	if err != nil {
+		return nil, err
+	}
+	this.client = newClient(cfg.DirectoryURL, key)
+
+	info, err := cfg.Access.Get(logger)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read ACME certificate: %s", err)
+	}
+	if info != nil && this.valid(info) {
+		if err := this.set(info); err != nil {
+			logger.Warnf("ignoring stored ACME certificate: %s", err)
+		}
+	}
+	if this.GetCertificateInfo() == nil {
+		if err := this.Renew(); err != nil {
+			return nil, err
+		}
+	}
+	go this.run(ctx)
+	return this, nil
+}
+
+func (this *ACMESource) accountKey() (*ecdsa.PrivateKey, error) {
+	info, err := this.config.AccountAccess.Get(this.logger)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read ACME account key: %s", err)
+	}
+	if info != nil && len(info.Key()) > 0 {
+		k, err := keyutil.ParsePrivateKeyPEM(info.Key())
+		if err != nil {
+			return nil, fmt.Errorf("invalid ACME account key: %s", err)
+		}
+		key, ok := k.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("ACME account key must be an ECDSA key")
+		}
+		return key, nil
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	data, err := encodeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := this.config.AccountAccess.Set(this.logger, cert.NewCertInfo(nil, data, nil, nil)); err != nil {
+		return nil, fmt.Errorf("cannot store ACME account key: %s", err)
+	}
+	return key, nil
+}
+
+func encodeKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: keyutil.ECPrivateKeyBlockType, Bytes: der}), nil
+}
+
+// valid checks whether the certificate covers all domains and
+// does not need to be renewed yet.
+func (this *ACMESource) valid(info cert.CertificateInfo) bool {
+	chain, err := certutil.ParseCertsPEM(info.Cert())
+	if err != nil {
+		return false
+	}
+	c := chain[0]
+	if time.Now().Add(this.config.RenewBefore).After(c.NotAfter) {
+		return false
+	}
+	names := append([]string{}, c.DNSNames...)
+	sort.Strings(names)
+	for _, d := range this.config.Domains {
+		i := sort.SearchStrings(names, d)
+		if i >= len(names) || names[i] != d {
+			return false
+		}
+	}
+	return true
+}
+
+func (this *ACMESource) run(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if info := this.GetCertificateInfo(); info != nil && this.valid(info) {
+			continue
+		}
+		if err := this.Renew(); err != nil {
+			this.logger.Errorf("ACME certificate renewal failed: %s", err)
+			this.NotifyFailure()
+		}
+	}
+}
+
+// Renew orders a new certificate for the configured domains.
+func (this *ACMESource) Renew() error {
+	if err := this.client.register(this.config.Email); err != nil {
+		return err
+	}
+	o, url, err := this.client.newOrder(this.config.Domains)
+	if err != nil {
+		return fmt.Errorf("cannot create ACME order: %s", err)
+	}
+	for _, a := range o.Authorizations {
+		if err := this.authorize(a); err != nil {
+			return err
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: this.config.Domains[0]},
+		DNSNames: this.config.Domains,
+	}, key)
+	if err != nil {
+		return err
+	}
+	err = poll(this.config.Timeout, func() (bool, error) {
+		o, err = this.client.getOrder(url)
+		if err != nil {
+			return false, err
+		}
+		if o.Status == statusInvalid {
+			return false, fmt.Errorf("ACME order invalid: %v", o.Error)
+		}
+		return o.Status != statusPending, nil
+	})
+	if err != nil {
+		return err
+	}
+	if o.Status == statusReady {
+		if err := this.client.finalize(o, csr); err != nil {
+			return fmt.Errorf("cannot finalize ACME order: %s", err)
+		}
+	}
+	err = poll(this.config.Timeout, func() (bool, error) {
+		o, err = this.client.getOrder(url)
+		if err != nil {
+			return false, err
+		}
+		if o.Status == statusInvalid {
+			return false, fmt.Errorf("ACME order invalid: %v", o.Error)
+		}
+		return o.Status == statusValid, nil
+	})
+	if err != nil {
+		return fmt.Errorf("ACME certificate not issued: %s", err)
+	}
+	chain, err := this.client.certificate(o.Certificate)
+	if err != nil {
+		return fmt.Errorf("cannot download ACME certificate: %s", err)
+	}
+
+	keyData, err := encodeKey(key)
+	if err != nil {
+		return err
+	}
+	certData, caData := splitChain(chain)
+	info := cert.NewCertInfo(certData, keyData, caData, nil)
+	if err := this.set(info); err != nil {
+		return err
+	}
+	if err := this.config.Access.Set(this.logger, info); err != nil {
+		this.logger.Errorf("cannot store ACME certificate: %s", err)
+	}
+	return nil
+}
+
+func (this *ACMESource) authorize(url string) error {
+	a, err := this.client.getAuthorization(url)
+	if err != nil {
+		return fmt.Errorf("cannot get ACME authorization: %s", err)
+	}
+	if a.Status == statusValid {
+		return nil
+	}
+	var selected *challenge
+	for i, c := range a.Challenges {
+		if (c.Type == "http-01" && this.config.HTTP01 != nil && !a.Wildcard) || (c.Type == "dns-01" && this.config.DNS01 != nil) {
+			selected = &a.Challenges[i]
+			break
+		}
+	}
+	if selected == nil {
+		return fmt.Errorf("no supported ACME challenge for %s", a.Identifier.Value)
+	}
+	domain := a.Identifier.Value
+	keyAuth := this.client.keyAuthorization(selected.Token)
+	switch selected.Type {
+	case "http-01":
+		this.config.HTTP01.present(selected.Token, keyAuth)
+		defer this.config.HTTP01.cleanup(selected.Token)
+	case "dns-01":
+		fqdn, value := dns01Record(domain, keyAuth)
+		if err := this.config.DNS01.Present(domain, fqdn, value); err != nil {
+			return fmt.Errorf("cannot present DNS challenge for %s: %s", domain, err)
+		}
+		defer func() {
+			if err := this.config.DNS01.CleanUp(domain, fqdn, value); err != nil {
+				this.logger.Warnf("cannot clean up DNS challenge for %s: %s", domain, err)
+			}
+		}()
+	}
+	this.logger.Infof("solving ACME %s challenge for %s", selected.Type, domain)
+	if err := this.client.accept(selected); err != nil {
+		return fmt.Errorf("cannot accept ACME challenge for %s: %s", domain, err)
+	}
+	return poll(this.config.Timeout, func() (bool, error) {
+		a, err := this.client.getAuthorization(url)
+		if err != nil {
+			return false, err
+		}
+		switch a.Status {
+		case statusValid:
+			return true, nil
+		case statusPending, statusProcessing:
+			return false, nil
+		}
+		for _, c := range a.Challenges {
+			if c.Error != nil {
+				return false, fmt.Errorf("ACME authorization for %s failed: %s", domain, c.Error)
+			}
+		}
+		return false, fmt.Errorf("ACME authorization for %s failed: %s", domain, a.Status)
+	})
+}
+
+func splitChain(chain []byte) ([]byte, []byte) {
+	block, rest := pem.Decode(chain)
+	if block == nil {
+		return chain, nil
+	}
+	return pem.EncodeToMemory(block), bytes.TrimSpace(rest)
+}
+
+func (this *ACMESource) set(info cert.CertificateInfo) error {
+	tlscert, err := tls.X509KeyPair(append(append([]byte{}, info.Cert()...), info.CACert()...), info.Key())
+	if err != nil {
+		return fmt.Errorf("invalid certificate: %s", err)
+	}
+	this.lock.Lock()
+	this.info = info
+	this.current = &tlscert
+	this.lock.Unlock()
+
+	this.logger.Infof("updated current TLS certificate: %s", certmgmt.Describe(info))
+	this.Notify(info)
+	this.NotifyCA(info.CACert())
+	return nil
+}
+
+func (this *ACMESource) GetCertificateInfo() cert.CertificateInfo {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.info
+}
+
+func (this *ACMESource) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.current, nil
+}
+
+func (this *ACMESource) GetCABundle() []byte {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	if this.info == nil {
+		return nil
+	}
+	return this.info.CACert()
+}
+
+func (this *ACMESource) GetCACertificates() []*x509.Certificate {
+	return certs.ParseCABundle(this.GetCABundle())
+}
@@ -0,0 +1,205 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package cabundle
+
+import (
+	"bytes"
+	"crypto/x509"
+	"fmt"
+	"sync"
+
+	"github.com/gardener/controller-manager-library/pkg/certs"
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/cluster"
+	"github.com/gardener/controller-manager-library/pkg/logger"
+	"github.com/gardener/controller-manager-library/pkg/resources"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+// DefaultKey is the default config map key used to store the CA bundle
+const DefaultKey = "ca.crt"
+
+// ConfigMapSource maintains a CA bundle stored in a config map and
+// keeps track of changes of the config map.
+type ConfigMapSource struct {
+	certs.CABundleChannels
+	lock      sync.RWMutex
+	logger    logger.LogContext
+	cluster   cluster.Interface
+	name      resources.ObjectName
+	key       string
+	bundle    []byte
+	pool      *x509.CertPool
+	notifiers []func(*x509.CertPool)
+}
+
+var _ certs.CABundleSource = &ConfigMapSource{}
+
+// NewConfigMapSource creates a CA bundle source for the given config map.
+// The actual content is read and the config map is watched for changes.
+func NewConfigMapSource(logger logger.LogContext, cluster cluster.Interface, name resources.ObjectName, key string) (*ConfigMapSource, error) {
+	if key == "" {
+		key = DefaultKey
+	}
+	this := &ConfigMapSource{
+		logger:  logger,
+		cluster: cluster,
+		name:    name,
+		key:     key,
+		pool:    x509.NewCertPool(),
+	}
+	r, err := this.resource()
+	if err != nil {
+		return nil, err
+	}
+	o, err := r.GetInto(name, &corev1.ConfigMap{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+	} else {
+		this.update(o)
+	}
+	err = r.AddSelectedEventHandler(resources.ResourceEventHandlerFuncs{
+		AddFunc: this.update,
+		UpdateFunc: func(old, new resources.Object) {
+			this.update(new)
+		},
+		DeleteFunc: func(obj resources.Object) {
+			if obj.GetNamespace() == this.name.Namespace() && obj.GetName() == this.name.Name() {
+				this.set(nil, x509.NewCertPool())
+			}
+		},
+	}, name.Namespace(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot watch config map %s: %s", name, err)
+	}
+	return this, nil
+}
+
+func (this *ConfigMapSource) resource() (resources.Interface, error) {
+	return this.cluster.GetResource(schema.GroupKind{Group: corev1.GroupName, Kind: "ConfigMap"})
+}
+
+func (this *ConfigMapSource) update(obj resources.Object) {
+	if obj.GetNamespace() != this.name.Namespace() || obj.GetName() != this.name.Name() {
+		return
+	}
+	cm, ok := obj.Data().(*corev1.ConfigMap)
+	if !ok {
+		return
+	}
+	bundle := []byte(cm.Data[this.key])
+	pool := x509.NewCertPool()
+	if len(bundle) > 0 {
+		certs, err := certutil.ParseCertsPEM(bundle)
+		if err != nil {
+			this.logger.Errorf("invalid CA bundle in config map %s: %s", this.name, err)
+			return
+		}
+		for _, c := range certs {
+			pool.AddCert(c)
+		}
+	}
+	this.set(bundle, pool)
+}
+
+func (this *ConfigMapSource) set(bundle []byte, pool *x509.CertPool) {
+	this.lock.Lock()
+	if bytes.Equal(this.bundle, bundle) {
+		this.lock.Unlock()
+		return
+	}
+	this.logger.Infof("CA bundle in config map %s changed", this.name)
+	this.bundle = bundle
+	this.pool = pool
+	notifiers := append([]func(*x509.CertPool){}, this.notifiers...)
+	this.lock.Unlock()
+
+	this.NotifyCA(bundle)
+	for _, n := range notifiers {
+		n(pool)
+	}
+}
+
+// GetCABundle returns the PEM encoded CA bundle
+func (this *ConfigMapSource) GetCABundle() []byte {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.bundle
+}
+
+// GetCACertificates returns the certificates of the actual CA bundle
+func (this *ConfigMapSource) GetCACertificates() []*x509.Certificate {
+	return certs.ParseCABundle(this.GetCABundle())
+}
+
+// GetCertPool returns the actual CA bundle as certificate pool
+func (this *ConfigMapSource) GetCertPool() *x509.CertPool {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.pool
+}
+
+// RegisterNotifier registers a function called for every change
+// of the CA bundle.
+func (this *ConfigMapSource) RegisterNotifier(n func(*x509.CertPool)) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.notifiers = append(this.notifiers, n)
+}
+
+// SetCABundle stores the given PEM encoded CA bundle in the config map.
+// The config map is created if it does not exist yet, other keys are
+// left untouched.
+func (this *ConfigMapSource) SetCABundle(bundle []byte) error {
+	certs, err := certutil.ParseCertsPEM(bundle)
+	if err != nil {
+		return fmt.Errorf("invalid CA bundle: %s", err)
+	}
+	r, err := this.resource()
+	if err != nil {
+		return err
+	}
+	mod, err := resources.CreateOrModify(r.New(this.name), func(mod *resources.ModificationState) error {
+		cm := mod.Data().(*corev1.ConfigMap)
+		if cm.Data[this.key] == string(bundle) {
+			return nil
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[this.key] = string(bundle)
+		mod.Modify(true)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if mod {
+		this.logger.Infof("CA bundle in config map %s updated", this.name)
+	}
+	pool := x509.NewCertPool()
+	for _, c := range certs {
+		pool.AddCert(c)
+	}
+	this.set(bundle, pool)
+	return nil
+}
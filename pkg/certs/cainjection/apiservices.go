@@ -0,0 +1,75 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package cainjection
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/gardener/controller-manager-library/pkg/resources"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// APIServices injects the CA bundle into APIService objects of the
+// aggregation layer. The api registration types are not part of the
+// resource scheme, therefore the objects are handled unstructured
+// using the preferred version of the cluster.
+// Local services and services skipping the TLS verification are
+// left untouched.
+var APIServices Target = &apiServices{}
+
+type apiServices struct{}
+
+func (this *apiServices) GroupKind() schema.GroupKind {
+	return schema.GroupKind{Group: "apiregistration.k8s.io", Kind: "APIService"}
+}
+
+func (this *apiServices) Unstructured() bool {
+	return true
+}
+
+func (this *apiServices) Inject(obj resources.ObjectData, bundle []byte) (bool, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return false, fmt.Errorf("unexpected type %T", obj)
+	}
+	svc, _, err := unstructured.NestedMap(u.Object, "spec", "service")
+	if err != nil {
+		return false, err
+	}
+	if svc == nil {
+		return false, nil
+	}
+	skip, _, err := unstructured.NestedBool(u.Object, "spec", "insecureSkipTLSVerify")
+	if err != nil {
+		return false, err
+	}
+	if skip {
+		return false, nil
+	}
+	encoded := base64.StdEncoding.EncodeToString(bundle)
+	old, _, err := unstructured.NestedString(u.Object, "spec", "caBundle")
+	if err != nil {
+		return false, err
+	}
+	if old == encoded {
+		return false, nil
+	}
+	return true, unstructured.SetNestedField(u.Object, encoded, "spec", "caBundle")
+}
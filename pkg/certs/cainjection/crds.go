@@ -0,0 +1,50 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package cainjection
+
+import (
+	"fmt"
+
+	"github.com/gardener/controller-manager-library/pkg/resources"
+	_ "github.com/gardener/controller-manager-library/pkg/resources/apiextensions"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CustomResourceDefinitions injects the CA bundle into the conversion
+// webhook client config of custom resource definitions. Definitions
+// without a webhook conversion are left untouched.
+var CustomResourceDefinitions Target = &crds{}
+
+type crds struct{}
+
+func (this *crds) GroupKind() schema.GroupKind {
+	return schema.GroupKind{Group: v1beta1.GroupName, Kind: "CustomResourceDefinition"}
+}
+
+func (this *crds) Inject(obj resources.ObjectData, bundle []byte) (bool, error) {
+	crd, ok := obj.(*v1beta1.CustomResourceDefinition)
+	if !ok {
+		return false, fmt.Errorf("unexpected type %T", obj)
+	}
+	conv := crd.Spec.Conversion
+	if conv == nil || conv.WebhookClientConfig == nil {
+		return false, nil
+	}
+	return setBundle(&conv.WebhookClientConfig.CABundle, bundle), nil
+}
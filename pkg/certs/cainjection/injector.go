@@ -0,0 +1,222 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package cainjection
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/gardener/controller-manager-library/pkg/certs"
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/cluster"
+	"github.com/gardener/controller-manager-library/pkg/logger"
+	"github.com/gardener/controller-manager-library/pkg/resources"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// Target describes a kind of cluster scoped objects containing
+// CA bundle fields to be kept in sync with a CA bundle source.
+type Target interface {
+	GroupKind() schema.GroupKind
+	// Inject sets the CA bundle in the given object and reports
+	// whether the object has been modified.
+	Inject(obj resources.ObjectData, bundle []byte) (bool, error)
+}
+
+// UnstructuredTarget is implemented by targets handling kinds not
+// registered in the resource scheme. Their objects are passed to
+// Inject as *unstructured.Unstructured.
+type UnstructuredTarget interface {
+	Target
+	Unstructured() bool
+}
+
+type key struct {
+	gk   schema.GroupKind
+	name string
+}
+
+// Injector keeps the CA bundle fields of selected objects in sync with
+// the actual CA bundle of a CA bundle source. The objects are updated
+// on every CA rotation and whenever a drift is detected for an object.
+type Injector struct {
+	lock    sync.RWMutex
+	logger  logger.LogContext
+	cluster cluster.Interface
+	source  certs.CABundleSource
+	targets map[schema.GroupKind]Target
+	names   map[schema.GroupKind]map[string]bool
+	queue   workqueue.RateLimitingInterface
+}
+
+func New(logger logger.LogContext, cluster cluster.Interface, source certs.CABundleSource) *Injector {
+	return &Injector{
+		logger:  logger,
+		cluster: cluster,
+		source:  source,
+		targets: map[schema.GroupKind]Target{},
+		names:   map[schema.GroupKind]map[string]bool{},
+		queue:   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "cainjection"),
+	}
+}
+
+// Add selects objects of a target kind for the injection.
+// It must be called before the injector is started.
+func (this *Injector) Add(target Target, names ...string) *Injector {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	gk := target.GroupKind()
+	this.targets[gk] = target
+	if this.names[gk] == nil {
+		this.names[gk] = map[string]bool{}
+	}
+	for _, n := range names {
+		this.names[gk][n] = true
+	}
+	return this
+}
+
+func (this *Injector) selected(gk schema.GroupKind, name string) bool {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.names[gk][name]
+}
+
+// Start watches the selected objects and the CA bundle source and
+// processes the required updates until the context is done.
+func (this *Injector) Start(ctx context.Context) error {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	for gk, target := range this.targets {
+		r, err := this.resource(target)
+		if err != nil {
+			return fmt.Errorf("cannot get resource %s: %s", gk, err)
+		}
+		kind := gk
+		handler := func(obj resources.Object) {
+			if this.selected(kind, obj.GetName()) {
+				this.queue.Add(key{kind, obj.GetName()})
+			}
+		}
+		err = r.AddEventHandler(resources.ResourceEventHandlerFuncs{
+			AddFunc:    handler,
+			UpdateFunc: func(old, new resources.Object) { handler(new) },
+			DeleteFunc: func(obj resources.Object) {},
+		})
+		if err != nil {
+			return fmt.Errorf("cannot watch %s: %s", gk, err)
+		}
+		for name := range this.names[gk] {
+			this.queue.Add(key{gk, name})
+		}
+	}
+
+	changes := this.source.CAChanges()
+	go func() {
+		<-ctx.Done()
+		this.queue.ShutDown()
+	}()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-changes:
+				this.logger.Infof("CA bundle changed: updating all selected objects")
+				this.enqueueAll()
+			}
+		}
+	}()
+	go this.work()
+	return nil
+}
+
+func (this *Injector) resource(target Target) (resources.Interface, error) {
+	if u, ok := target.(UnstructuredTarget); ok && u.Unstructured() {
+		return this.cluster.Resources().GetUnstructuredByGK(target.GroupKind())
+	}
+	return this.cluster.GetResource(target.GroupKind())
+}
+
+func (this *Injector) enqueueAll() {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	for gk, names := range this.names {
+		for name := range names {
+			this.queue.Add(key{gk, name})
+		}
+	}
+}
+
+func (this *Injector) work() {
+	for {
+		item, shutdown := this.queue.Get()
+		if shutdown {
+			return
+		}
+		k := item.(key)
+		if err := this.inject(k); err != nil {
+			this.logger.Warnf("CA bundle injection for %s %s failed (retrying): %s", k.gk.Kind, k.name, err)
+			this.queue.AddRateLimited(item)
+		} else {
+			this.queue.Forget(item)
+		}
+		this.queue.Done(item)
+	}
+}
+
+func (this *Injector) inject(k key) error {
+	bundle := this.source.GetCABundle()
+	if len(bundle) == 0 {
+		return fmt.Errorf("no CA bundle available")
+	}
+	this.lock.RLock()
+	target := this.targets[k.gk]
+	this.lock.RUnlock()
+
+	r, err := this.resource(target)
+	if err != nil {
+		return err
+	}
+	o, err := r.GetCached(k.name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// not existing objects are handled by the add event
+			return nil
+		}
+		return err
+	}
+	// check the cached state first to avoid unnecessary requests
+	if m, err := target.Inject(o.Data().DeepCopyObject().(resources.ObjectData), bundle); err != nil || !m {
+		return err
+	}
+	mod, err := resources.Modify(o, func(mod *resources.ModificationState) error {
+		m, err := target.Inject(mod.Data(), bundle)
+		mod.Modify(m)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if mod {
+		this.logger.Infof("updated CA bundle of %s %s", k.gk.Kind, k.name)
+	}
+	return nil
+}
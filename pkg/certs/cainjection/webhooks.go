@@ -0,0 +1,76 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package cainjection
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/gardener/controller-manager-library/pkg/resources"
+
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	MutatingWebhookConfigurations   Target = &mutatingWebhooks{}
+	ValidatingWebhookConfigurations Target = &validatingWebhooks{}
+)
+
+type mutatingWebhooks struct{}
+
+func (this *mutatingWebhooks) GroupKind() schema.GroupKind {
+	return schema.GroupKind{Group: admissionregistration.GroupName, Kind: "MutatingWebhookConfiguration"}
+}
+
+func (this *mutatingWebhooks) Inject(obj resources.ObjectData, bundle []byte) (bool, error) {
+	cfg, ok := obj.(*admissionregistration.MutatingWebhookConfiguration)
+	if !ok {
+		return false, fmt.Errorf("unexpected type %T", obj)
+	}
+	mod := false
+	for i := range cfg.Webhooks {
+		mod = setBundle(&cfg.Webhooks[i].ClientConfig.CABundle, bundle) || mod
+	}
+	return mod, nil
+}
+
+type validatingWebhooks struct{}
+
+func (this *validatingWebhooks) GroupKind() schema.GroupKind {
+	return schema.GroupKind{Group: admissionregistration.GroupName, Kind: "ValidatingWebhookConfiguration"}
+}
+
+func (this *validatingWebhooks) Inject(obj resources.ObjectData, bundle []byte) (bool, error) {
+	cfg, ok := obj.(*admissionregistration.ValidatingWebhookConfiguration)
+	if !ok {
+		return false, fmt.Errorf("unexpected type %T", obj)
+	}
+	mod := false
+	for i := range cfg.Webhooks {
+		mod = setBundle(&cfg.Webhooks[i].ClientConfig.CABundle, bundle) || mod
+	}
+	return mod, nil
+}
+
+func setBundle(field *[]byte, bundle []byte) bool {
+	if bytes.Equal(*field, bundle) {
+		return false
+	}
+	*field = append([]byte{}, bundle...)
+	return true
+}
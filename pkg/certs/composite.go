@@ -0,0 +1,208 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/cert"
+	"github.com/gardener/controller-manager-library/pkg/logger"
+
+	"k8s.io/client-go/util/keyutil"
+)
+
+// CompositeSource serves the certificate of the first healthy source
+// of a list of certificate sources ordered by priority. A source is
+// healthy if it provides a currently valid certificate. The active
+// source is reevaluated periodically (CompositeCheckInterval) and
+// whenever a source notifies a rotation. TLS handshakes are served with
+// the certificate of the last evaluation.
+type CompositeSource struct {
+	Notifiers
+	CABundleChannels
+	lock    sync.RWMutex
+	logger  logger.LogContext
+	sources []CertificateSource
+	active  int
+	current *tls.Certificate
+}
+
+var _ NotifyingCertificateSource = &CompositeSource{}
+var _ CertificateAuthoritySource = &CompositeSource{}
+
+// CompositeCheckInterval is the period the health of the sources of
+// a composite source is reevaluated.
+var CompositeCheckInterval = 30 * time.Second
+
+func NewCompositeSource(ctx context.Context, logger logger.LogContext, sources ...CertificateSource) *CompositeSource {
+	this := &CompositeSource{
+		logger:  logger,
+		sources: sources,
+		active:  -1,
+	}
+	for i, s := range sources {
+		index := i
+		if n, ok := s.(NotifyingCertificateSource); ok {
+			n.RegisterNotifier(func(info cert.CertificateInfo) {
+				if active, switched := this.evaluate(); active == index && !switched {
+					this.Notify(info)
+				}
+			})
+		}
+		if c, ok := s.(CABundleSource); ok {
+			changes := c.CAChanges()
+			go func() {
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case bundle := <-changes:
+						if active, switched := this.evaluate(); active == index && !switched {
+							this.NotifyCA(bundle)
+						}
+					}
+				}
+			}()
+		}
+	}
+	this.evaluate()
+	go this.run(ctx)
+	return this
+}
+
+func (this *CompositeSource) run(ctx context.Context) {
+	ticker := time.NewTicker(CompositeCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			this.evaluate()
+		}
+	}
+}
+
+// evaluate determines the active source and returns its index (-1 if
+// no source is healthy) and whether the active source has been switched.
+// A switch is propagated to the registered notifiers.
+func (this *CompositeSource) evaluate() (int, bool) {
+	index := -1
+	var found *tls.Certificate
+	for i, s := range this.sources {
+		c, err := s.GetCertificate(nil)
+		if err == nil && healthy(c) {
+			index, found = i, c
+			break
+		}
+	}
+
+	this.lock.Lock()
+	old := this.active
+	this.active = index
+	this.current = found
+	this.lock.Unlock()
+
+	if old != index {
+		if index < 0 {
+			this.logger.Errorf("no healthy certificate source found")
+		} else {
+			this.logger.Infof("switching to certificate source %d (%T)", index, this.sources[index])
+			if old >= 0 {
+				if info := this.info(index, found); info != nil {
+					this.Notify(info)
+				}
+			}
+			if c, ok := this.sources[index].(CABundleSource); ok {
+				this.NotifyCA(c.GetCABundle())
+			}
+		}
+	}
+	return index, old != index
+}
+
+// info returns the complete certificate info of the certificate of a
+// source or nil if the private key is not available.
+func (this *CompositeSource) info(index int, c *tls.Certificate) cert.CertificateInfo {
+	if s, ok := this.sources[index].(CertificateInfoSource); ok {
+		if info := s.GetCertificateInfo(); info != nil {
+			return info
+		}
+	}
+	key, err := x509.MarshalPKCS8PrivateKey(c.PrivateKey)
+	if err != nil {
+		this.logger.Warnf("cannot encode private key of certificate source %d: %s", index, err)
+		return nil
+	}
+	var data []byte
+	for _, raw := range c.Certificate {
+		data = append(data, cert.EncodeCertPEM(&x509.Certificate{Raw: raw})...)
+	}
+	var ca []byte
+	if s, ok := this.sources[index].(CABundleSource); ok {
+		ca = s.GetCABundle()
+	}
+	return cert.NewCertInfo(data, pem.EncodeToMemory(&pem.Block{Type: keyutil.PrivateKeyBlockType, Bytes: key}), ca, nil)
+}
+
+func healthy(c *tls.Certificate) bool {
+	if c == nil || len(c.Certificate) == 0 {
+		return false
+	}
+	leaf, err := leafOf(c)
+	if err != nil {
+		return false
+	}
+	now := time.Now()
+	return now.After(leaf.NotBefore) && now.Before(leaf.NotAfter)
+}
+
+// Active returns the actually used source or nil.
+func (this *CompositeSource) Active() CertificateSource {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	if this.active < 0 {
+		return nil
+	}
+	return this.sources[this.active]
+}
+
+func (this *CompositeSource) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	if this.current == nil {
+		return nil, fmt.Errorf("no healthy certificate source available")
+	}
+	return this.current, nil
+}
+
+func (this *CompositeSource) GetCABundle() []byte {
+	if c, ok := this.Active().(CABundleSource); ok {
+		return c.GetCABundle()
+	}
+	return nil
+}
+
+func (this *CompositeSource) GetCACertificates() []*x509.Certificate {
+	return ParseCABundle(this.GetCABundle())
+}
@@ -0,0 +1,285 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package csr
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/cert"
+	"github.com/gardener/controller-manager-library/pkg/certs"
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/certmgmt"
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/cluster"
+	"github.com/gardener/controller-manager-library/pkg/logger"
+
+	certificates "k8s.io/api/certificates/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	certclient "k8s.io/client-go/kubernetes/typed/certificates/v1beta1"
+	"k8s.io/client-go/rest"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+)
+
+// Config describes the certificate requested from the Kubernetes
+// certificates API.
+type Config struct {
+	// Name is used as name prefix for the created CSR objects
+	Name         string
+	CommonName   string
+	Organization []string
+	DNSNames     []string
+	// SignerName is the requested signer (spec.signerName). It is only
+	// evaluated by Kubernetes versions supporting signer names.
+	SignerName string
+	// Usages default to digital signature, key encipherment and server auth
+	Usages []certificates.KeyUsage
+	// AutoApprove approves the created CSR. This requires the permission
+	// to update the approval subresource and to approve for the signer.
+	AutoApprove bool
+	// RenewBeforePercent is the percentage of the certificate lifetime
+	// left when the certificate is renewed (default 30).
+	RenewBeforePercent int
+	// Timeout is the maximum period to wait for an issued
+	// certificate (default 5 minutes).
+	Timeout time.Duration
+}
+
+// CSRSource is a certificate source requesting the certificate via
+// a CertificateSigningRequest. The private key never leaves the process.
+// The certificate is renewed automatically before it expires.
+type CSRSource struct {
+	certs.Notifiers
+	lock    sync.RWMutex
+	logger  logger.LogContext
+	client  certclient.CertificateSigningRequestInterface
+	rest    rest.Interface
+	config  Config
+	info    cert.CertificateInfo
+	current *tls.Certificate
+}
+
+var _ certs.NotifyingCertificateSource = &CSRSource{}
+var _ certs.ClientCertificateSource = &CSRSource{}
+
+// New requests an initial certificate and starts the renewal handling.
+func New(ctx context.Context, logger logger.LogContext, cluster cluster.Interface, cfg Config) (*CSRSource, error) {
+	restcfg := cluster.Config()
+	client, err := certclient.NewForConfig(&restcfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Name == "" {
+		cfg.Name = cfg.CommonName
+	}
+	if len(cfg.Usages) == 0 {
+		cfg.Usages = []certificates.KeyUsage{certificates.UsageDigitalSignature, certificates.UsageKeyEncipherment, certificates.UsageServerAuth}
+	}
+	if cfg.RenewBeforePercent <= 0 {
+		cfg.RenewBeforePercent = 30
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Minute
+	}
+	this := &CSRSource{
+		logger: logger,
+		client: client.CertificateSigningRequests(),
+		rest:   client.RESTClient(),
+		config: cfg,
+	}
+	this.Instrument(cfg.Name)
+	if err := this.Renew(ctx); err != nil {
+		return nil, err
+	}
+	go this.run(ctx)
+	return this, nil
+}
+
+func (this *CSRSource) run(ctx context.Context) {
+	for {
+		wait := time.Minute
+		if info := this.GetCertificateInfo(); info != nil {
+			if c, err := certutil.ParseCertsPEM(info.Cert()); err == nil {
+				lifetime := c[0].NotAfter.Sub(c[0].NotBefore)
+				renew := c[0].NotAfter.Add(-lifetime * time.Duration(this.config.RenewBeforePercent) / 100)
+				wait = time.Until(renew)
+			}
+		}
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+		if err := this.Renew(ctx); err != nil {
+			this.logger.Errorf("certificate renewal failed: %s", err)
+			this.NotifyFailure()
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Minute):
+			}
+		}
+	}
+}
+
+// Renew requests a new certificate for a new private key.
+func (this *CSRSource) Renew(ctx context.Context) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return fmt.Errorf("cannot generate private key: %s", err)
+	}
+	request, err := certutil.MakeCSR(key, &pkix.Name{CommonName: this.config.CommonName, Organization: this.config.Organization}, this.config.DNSNames, nil)
+	if err != nil {
+		return fmt.Errorf("cannot create certificate request: %s", err)
+	}
+	csr, err := this.create(request)
+	if err != nil {
+		return fmt.Errorf("cannot create certificate signing request: %s", err)
+	}
+	this.logger.Infof("created certificate signing request %s", csr.Name)
+
+	if this.config.AutoApprove {
+		csr.Status.Conditions = append(csr.Status.Conditions, certificates.CertificateSigningRequestCondition{
+			Type:           certificates.CertificateApproved,
+			Reason:         "AutoApproved",
+			Message:        "approved by requesting controller",
+			LastUpdateTime: metav1.Now(),
+		})
+		if _, err := this.client.UpdateApproval(csr); err != nil {
+			return fmt.Errorf("cannot approve certificate signing request %s: %s", csr.Name, err)
+		}
+	}
+
+	var issued []byte
+	err = wait.PollImmediate(2*time.Second, this.config.Timeout, func() (bool, error) {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		csr, err := this.client.Get(csr.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, c := range csr.Status.Conditions {
+			if c.Type == certificates.CertificateDenied {
+				return false, fmt.Errorf("certificate signing request %s denied: %s", csr.Name, c.Message)
+			}
+		}
+		issued = csr.Status.Certificate
+		return len(issued) > 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("waiting for certificate failed: %s", err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: keyutil.PrivateKeyBlockType, Bytes: der})
+	tlscert, err := tls.X509KeyPair(issued, keyPEM)
+	if err != nil {
+		return fmt.Errorf("invalid issued certificate: %s", err)
+	}
+	info := cert.NewCertInfo(issued, keyPEM, nil, nil)
+
+	this.lock.Lock()
+	this.info = info
+	this.current = &tlscert
+	this.lock.Unlock()
+
+	this.logger.Infof("updated current TLS certificate: %s", certmgmt.Describe(info))
+	this.Notify(info)
+	return nil
+}
+
+// create creates the CSR object. The signer name is added to the raw
+// object, because it is not part of the typed v1beta1 API version
+// used by this library.
+func (this *CSRSource) create(request []byte) (*certificates.CertificateSigningRequest, error) {
+	csr := &certificates.CertificateSigningRequest{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: certificates.SchemeGroupVersion.String(),
+			Kind:       "CertificateSigningRequest",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: this.config.Name + "-",
+		},
+		Spec: certificates.CertificateSigningRequestSpec{
+			Request: request,
+			Usages:  this.config.Usages,
+		},
+	}
+	if this.config.SignerName == "" {
+		return this.client.Create(csr)
+	}
+
+	data, err := json.Marshal(csr)
+	if err != nil {
+		return nil, err
+	}
+	raw := map[string]interface{}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	raw["spec"].(map[string]interface{})["signerName"] = this.config.SignerName
+	data, err = json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	result := &certificates.CertificateSigningRequest{}
+	err = this.rest.Post().
+		Resource("certificatesigningrequests").
+		SetHeader("Content-Type", "application/json").
+		Body(data).
+		Do().
+		Into(result)
+	return result, err
+}
+
+func (this *CSRSource) GetCertificateInfo() cert.CertificateInfo {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.info
+}
+
+func (this *CSRSource) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.current, nil
+}
+
+func (this *CSRSource) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	c, _ := this.GetCertificate(nil)
+	if c == nil {
+		return &tls.Certificate{}, nil
+	}
+	return c, nil
+}
@@ -0,0 +1,79 @@
+//go:build linux
+// +build linux
+
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package file
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+)
+
+const watchMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE |
+	syscall.IN_MOVED_TO | syscall.IN_MOVED_FROM | syscall.IN_ATTRIB | syscall.IN_DELETE_SELF | syscall.IN_MOVE_SELF
+
+// watchDirs watches the given directories with inotify. Any change of
+// a directory entry (including renames and chmods) results in a signal
+// on the returned channel. The channel is closed when the context is done.
+func watchDirs(ctx context.Context, dirs []string) (<-chan struct{}, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("inotify init failed: %s", err)
+	}
+	var wds []int
+	for _, d := range dirs {
+		wd, err := syscall.InotifyAddWatch(fd, d, watchMask)
+		if err != nil {
+			syscall.Close(fd)
+			return nil, fmt.Errorf("cannot watch %q: %s", d, err)
+		}
+		wds = append(wds, wd)
+	}
+
+	events := make(chan struct{}, 1)
+	go func() {
+		<-ctx.Done()
+		// removing the watches wakes up the reader with IN_IGNORED events
+		for _, wd := range wds {
+			syscall.InotifyRmWatch(fd, uint32(wd))
+		}
+	}()
+	go func() {
+		defer close(events)
+		defer syscall.Close(fd)
+		buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+		for {
+			_, err := syscall.Read(fd, buf)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				if err == syscall.EINTR {
+					continue
+				}
+				return
+			}
+			select {
+			case events <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return events, nil
+}
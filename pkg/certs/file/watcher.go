@@ -0,0 +1,262 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package file
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/cert"
+	"github.com/gardener/controller-manager-library/pkg/certs"
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/certmgmt"
+	"github.com/gardener/controller-manager-library/pkg/logger"
+)
+
+const (
+	// DefaultPollInterval is the interval used to reread the files if
+	// file system notifications are not available.
+	DefaultPollInterval = time.Minute
+	// DefaultDebounce is the default period file system events are
+	// coalesced before the files are reread.
+	DefaultDebounce = 100 * time.Millisecond
+)
+
+// Options configure the change detection of a CertWatcher.
+type Options struct {
+	// PollInterval enables a periodic reread of the files. If no
+	// file system notifications are available DefaultPollInterval is
+	// used if not set.
+	PollInterval time.Duration
+	// Debounce is the period file system events are collected before
+	// the files are reread (DefaultDebounce if not set, a negative
+	// value disables the debouncing).
+	Debounce time.Duration
+}
+
+// CertWatcher is a certificate source reading the certificate and key
+// from files. The parent directories of the files are watched, so that
+// updates of Kubernetes volumes (atomic swaps of the ..data symlink)
+// are reliably detected. Additionally the files can be reread
+// periodically for file systems without change notifications.
+type CertWatcher struct {
+	certs.Notifiers
+	lock     sync.RWMutex
+	logger   logger.LogContext
+	certPath string
+	keyPath  string
+
+	hash    [sha256.Size]byte
+	current *tls.Certificate
+}
+
+var _ certs.NotifyingCertificateSource = &CertWatcher{}
+var _ certs.ClientCertificateSource = &CertWatcher{}
+
+func New(ctx context.Context, logger logger.LogContext, certPath, keyPath string) (*CertWatcher, error) {
+	return NewWithOptions(ctx, logger, certPath, keyPath, Options{})
+}
+
+// NewWithPolling creates a CertWatcher additionally rereading the files
+// with the given interval. If no file system notifications are available
+// polling is used even if no interval is given.
+func NewWithPolling(ctx context.Context, logger logger.LogContext, certPath, keyPath string, interval time.Duration) (*CertWatcher, error) {
+	return NewWithOptions(ctx, logger, certPath, keyPath, Options{PollInterval: interval})
+}
+
+// NewWithOptions creates a CertWatcher with explicit change detection options.
+func NewWithOptions(ctx context.Context, logger logger.LogContext, certPath, keyPath string, opts Options) (*CertWatcher, error) {
+	interval := opts.PollInterval
+	this := &CertWatcher{
+		logger:   logger,
+		certPath: certPath,
+		keyPath:  keyPath,
+	}
+	this.Instrument(certPath)
+	if err := this.ReadCertificate(); err != nil {
+		return nil, err
+	}
+	dirs := []string{filepath.Dir(certPath)}
+	if d := filepath.Dir(keyPath); d != dirs[0] {
+		dirs = append(dirs, d)
+	}
+	events, err := watchDirs(ctx, dirs)
+	if err != nil {
+		if interval <= 0 {
+			interval = DefaultPollInterval
+		}
+		logger.Warnf("cannot watch certificate files (%s): using polling every %s", err, interval)
+	} else {
+		debounce := opts.Debounce
+		if debounce == 0 {
+			debounce = DefaultDebounce
+		}
+		if debounce > 0 {
+			events = coalesce(ctx, events, debounce)
+		}
+		go this.reload(events)
+	}
+	if interval > 0 {
+		go this.reload(poll(ctx, interval))
+	}
+	return this, nil
+}
+
+func (this *CertWatcher) reload(events <-chan struct{}) {
+	for range events {
+		if err := this.ReadCertificate(); err != nil {
+			this.logger.Errorf("cannot reload certificate: %s", err)
+			this.NotifyFailure()
+		}
+	}
+}
+
+// coalesce forwards a single event after no further event has been
+// received for the debounce period.
+func coalesce(ctx context.Context, events <-chan struct{}, debounce time.Duration) <-chan struct{} {
+	result := make(chan struct{})
+	go func() {
+		defer close(result)
+		var timer *time.Timer
+		var fire <-chan time.Time
+		for {
+			select {
+			case _, ok := <-events:
+				if !ok {
+					return
+				}
+				if timer == nil {
+					timer = time.NewTimer(debounce)
+				} else {
+					if !timer.Stop() {
+						select {
+						case <-timer.C:
+						default:
+						}
+					}
+					timer.Reset(debounce)
+				}
+				fire = timer.C
+			case <-fire:
+				fire = nil
+				select {
+				case result <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return result
+}
+
+func poll(ctx context.Context, interval time.Duration) <-chan struct{} {
+	events := make(chan struct{})
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				select {
+				case events <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events
+}
+
+// ReadCertificate reads the certificate files. The current certificate
+// is only replaced if the content of the files changed.
+func (this *CertWatcher) ReadCertificate() error {
+	certData, err := readFile(this.certPath)
+	if err != nil {
+		return err
+	}
+	keyData, err := readFile(this.keyPath)
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.Sum256(append(append([]byte{}, certData...), keyData...))
+
+	this.lock.Lock()
+	if this.current != nil && hash == this.hash {
+		this.lock.Unlock()
+		return nil
+	}
+	tlscert, err := tls.X509KeyPair(certData, keyData)
+	if err != nil {
+		this.lock.Unlock()
+		// files may be updated one after the other, keep the old one
+		return fmt.Errorf("invalid certificate in %q: %s", this.certPath, err)
+	}
+	this.hash = hash
+	this.current = &tlscert
+	this.lock.Unlock()
+
+	info := cert.NewCertInfo(certData, keyData, nil, nil)
+	this.logger.Infof("updated current TLS certificate: %s", certmgmt.Describe(info))
+	this.Notify(info)
+	return nil
+}
+
+func (this *CertWatcher) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.current, nil
+}
+
+// GetClientCertificate provides the certificate for usage as client
+// certificate in a tls.Config.
+func (this *CertWatcher) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	c, _ := this.GetCertificate(nil)
+	if c == nil {
+		return &tls.Certificate{}, nil
+	}
+	return c, nil
+}
+
+// readFile reads a file by resolving all symbolic links first, to
+// read a consistent version of a kubelet managed volume.
+func readFile(path string) ([]byte, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %q: %s", path, err)
+	}
+	data, err := ioutil.ReadFile(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %q: %s", path, err)
+	}
+	return data, nil
+}
+
+// WaitForCertificate waits until a valid certificate is available
+func (this *CertWatcher) WaitForCertificate(ctx context.Context) error {
+	return certs.WaitForCertificate(ctx, this)
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+
+	"github.com/gardener/controller-manager-library/pkg/cert"
+)
+
+// CertificateSource provides the actual TLS certificate
+// for a TLS server.
+type CertificateSource interface {
+	GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error)
+}
+
+// NotifyingCertificateSource is a certificate source notifying
+// about certificate rotations.
+type NotifyingCertificateSource interface {
+	CertificateSource
+	RegisterNotifier(Notifier)
+	Changes() <-chan cert.CertificateInfo
+}
+
+// CertificateInfoSource provides the complete certificate info (including
+// the private key) of the actual certificate.
+type CertificateInfoSource interface {
+	GetCertificateInfo() cert.CertificateInfo
+}
+
+// CABundleSource provides the actual CA certificates
+// used to verify served certificates.
+type CABundleSource interface {
+	GetCABundle() []byte
+	GetCACertificates() []*x509.Certificate
+	CAChanges() <-chan []byte
+}
+
+// CertificateAuthoritySource is a certificate source also providing
+// the CA bundle required by clients to verify the certificate.
+type CertificateAuthoritySource interface {
+	CertificateSource
+	CABundleSource
+}
+
+// ClientCertificateSource provides the actual client certificate
+// for outbound mutual TLS connections.
+type ClientCertificateSource interface {
+	GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error)
+}
+
+type clientSource struct {
+	source CertificateSource
+}
+
+// ClientCertificateSourceFor uses the certificate of a certificate source
+// as client certificate.
+func ClientCertificateSourceFor(source CertificateSource) ClientCertificateSource {
+	if c, ok := source.(ClientCertificateSource); ok {
+		return c
+	}
+	return &clientSource{source}
+}
+
+func (this *clientSource) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	c, err := this.source.GetCertificate(nil)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		// no certificate is sent to the server
+		return &tls.Certificate{}, nil
+	}
+	return c, nil
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package certs
+
+import (
+	"crypto/x509"
+	"sync"
+
+	"github.com/gardener/controller-manager-library/pkg/cert"
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/certmgmt"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+// Notifier is called with the new certificate info after a rotation
+type Notifier func(cert.CertificateInfo)
+
+// Notifiers manages the rotation notifiers and channels of a
+// certificate source. It is intended to be embedded into
+// certificate source implementations.
+// If a metrics name is set with Instrument, notified certificates
+// and failures are reported by the certificate metrics.
+type Notifiers struct {
+	lock      sync.Mutex
+	name      string
+	reported  bool
+	notifiers []Notifier
+	channels  []chan cert.CertificateInfo
+}
+
+// Instrument enables the certificate expiry and rotation metrics
+// for the source using the given name as metrics label.
+func (this *Notifiers) Instrument(name string) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.name = name
+}
+
+// NotifyFailure reports a failed certificate rotation.
+func (this *Notifiers) NotifyFailure() {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.name != "" {
+		certmgmt.ReportRotationFailure(this.name)
+	}
+}
+
+// RegisterNotifier registers a function called for every certificate change.
+func (this *Notifiers) RegisterNotifier(n Notifier) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.notifiers = append(this.notifiers, n)
+}
+
+// Changes returns a new channel receiving the certificate infos of
+// certificate changes. If the receiver is slow only the latest
+// certificate info is kept.
+func (this *Notifiers) Changes() <-chan cert.CertificateInfo {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	c := make(chan cert.CertificateInfo, 1)
+	this.channels = append(this.channels, c)
+	return c
+}
+
+// Notify propagates a certificate change to all registered notifiers
+// and channels.
+func (this *Notifiers) Notify(info cert.CertificateInfo) {
+	this.lock.Lock()
+	if this.name != "" {
+		// the initial certificate is no rotation
+		if this.reported {
+			certmgmt.ReportRotation(this.name, info)
+		} else {
+			certmgmt.ReportCertificate(this.name, info)
+		}
+		this.reported = true
+	}
+	notifiers := append([]Notifier{}, this.notifiers...)
+	for _, c := range this.channels {
+		for {
+			select {
+			case c <- info:
+			default:
+				select {
+				case <-c:
+				default:
+				}
+				continue
+			}
+			break
+		}
+	}
+	this.lock.Unlock()
+
+	for _, n := range notifiers {
+		n(info)
+	}
+}
+
+// CABundleChannels manages the CA bundle change channels of a
+// CA bundle source. It is intended to be embedded into source
+// implementations.
+type CABundleChannels struct {
+	lock     sync.Mutex
+	channels []chan []byte
+}
+
+// CAChanges returns a new channel receiving the PEM encoded CA bundle
+// for every change. If the receiver is slow only the latest bundle is kept.
+func (this *CABundleChannels) CAChanges() <-chan []byte {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	c := make(chan []byte, 1)
+	this.channels = append(this.channels, c)
+	return c
+}
+
+// NotifyCA propagates a CA bundle change to all channels.
+func (this *CABundleChannels) NotifyCA(bundle []byte) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	for _, c := range this.channels {
+		for {
+			select {
+			case c <- bundle:
+			default:
+				select {
+				case <-c:
+				default:
+				}
+				continue
+			}
+			break
+		}
+	}
+}
+
+// ParseCABundle returns the certificates of a PEM encoded CA bundle.
+// Invalid bundles result in an empty list.
+func ParseCABundle(bundle []byte) []*x509.Certificate {
+	if len(bundle) == 0 {
+		return nil
+	}
+	certs, err := certutil.ParseCertsPEM(bundle)
+	if err != nil {
+		return nil
+	}
+	return certs
+}
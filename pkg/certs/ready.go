@@ -0,0 +1,85 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/server/readyz"
+)
+
+// CheckCertificate checks whether a certificate source provides
+// a currently valid certificate.
+func CheckCertificate(source CertificateSource) error {
+	c, err := source.GetCertificate(nil)
+	if err != nil {
+		return err
+	}
+	if c == nil || len(c.Certificate) == 0 {
+		return fmt.Errorf("no certificate available")
+	}
+	leaf, err := leafOf(c)
+	if err != nil {
+		return fmt.Errorf("invalid certificate: %s", err)
+	}
+	now := time.Now()
+	if now.Before(leaf.NotBefore) {
+		return fmt.Errorf("certificate not valid before %s", leaf.NotBefore)
+	}
+	if now.After(leaf.NotAfter) {
+		return fmt.Errorf("certificate expired at %s", leaf.NotAfter)
+	}
+	return nil
+}
+
+func leafOf(c *tls.Certificate) (*x509.Certificate, error) {
+	if c.Leaf != nil {
+		return c.Leaf, nil
+	}
+	return x509.ParseCertificate(c.Certificate[0])
+}
+
+// WaitForCertificate waits until the certificate source provides a
+// currently valid certificate or the context is done.
+func WaitForCertificate(ctx context.Context, source CertificateSource) error {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		err := CheckCertificate(source)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("no valid certificate: %s", err)
+		case <-ticker.C:
+		}
+	}
+}
+
+// RegisterReadinessCheck contributes a readiness check for a certificate
+// source to the readyz endpoint. It reports NotReady as long as no
+// valid certificate is available.
+func RegisterReadinessCheck(key string, source CertificateSource) {
+	readyz.Register(key, func() error {
+		return CheckCertificate(source)
+	})
+}
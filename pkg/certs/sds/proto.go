@@ -0,0 +1,194 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package sds
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Minimal protobuf encoding and decoding of the xDS messages used
+// for secret discovery:
+//
+//   message DiscoveryRequest {
+//     string version_info = 1;
+//     Node node = 2;
+//     repeated string resource_names = 3;
+//     string type_url = 4;
+//     string response_nonce = 5;
+//     google.rpc.Status error_detail = 6;
+//   }
+//   message DiscoveryResponse {
+//     string version_info = 1;
+//     repeated google.protobuf.Any resources = 2;
+//     string type_url = 4;
+//     string nonce = 5;
+//   }
+//   message Secret {
+//     string name = 1;
+//     TlsCertificate tls_certificate = 2;
+//     CertificateValidationContext validation_context = 4;
+//   }
+//   message TlsCertificate {
+//     DataSource certificate_chain = 1;
+//     DataSource private_key = 2;
+//   }
+//   message CertificateValidationContext {
+//     DataSource trusted_ca = 1;
+//   }
+//   message DataSource {
+//     bytes inline_bytes = 2;
+//   }
+
+const (
+	wireVarint = 0
+	wire64Bit  = 1
+	wireBytes  = 2
+	wire32Bit  = 5
+)
+
+type discoveryRequest struct {
+	VersionInfo   string
+	ResourceNames []string
+	TypeURL       string
+	ResponseNonce string
+	ErrorMessage  string
+}
+
+type discoveryResponse struct {
+	VersionInfo string
+	Resources   [][]byte
+	TypeURL     string
+	Nonce       string
+}
+
+type secret struct {
+	Name             string
+	CertificateChain []byte
+	PrivateKey       []byte
+	TrustedCA        []byte
+}
+
+// fields calls f for every field of a protobuf message. Only length
+// delimited values are passed, other wire types are skipped.
+func fields(data []byte, f func(num int, value []byte) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("invalid protobuf field key")
+		}
+		data = data[n:]
+		num, wire := int(key>>3), int(key&7)
+		switch wire {
+		case wireVarint:
+			_, n = binary.Uvarint(data)
+			if n <= 0 {
+				return fmt.Errorf("invalid protobuf varint")
+			}
+			data = data[n:]
+		case wire64Bit:
+			if len(data) < 8 {
+				return fmt.Errorf("truncated protobuf message")
+			}
+			data = data[8:]
+		case wire32Bit:
+			if len(data) < 4 {
+				return fmt.Errorf("truncated protobuf message")
+			}
+			data = data[4:]
+		case wireBytes:
+			l, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < l {
+				return fmt.Errorf("truncated protobuf message")
+			}
+			value := data[n : n+int(l)]
+			data = data[n+int(l):]
+			if err := f(num, value); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", wire)
+		}
+	}
+	return nil
+}
+
+// field appends a length delimited field to a protobuf message.
+func field(data []byte, num int, value []byte) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(buf, uint64(num<<3|wireBytes))
+	data = append(data, buf[:n]...)
+	n = binary.PutUvarint(buf, uint64(len(value)))
+	data = append(data, buf[:n]...)
+	return append(data, value...)
+}
+
+func decodeDiscoveryRequest(data []byte) (*discoveryRequest, error) {
+	req := &discoveryRequest{}
+	err := fields(data, func(num int, value []byte) error {
+		switch num {
+		case 1:
+			req.VersionInfo = string(value)
+		case 3:
+			req.ResourceNames = append(req.ResourceNames, string(value))
+		case 4:
+			req.TypeURL = string(value)
+		case 5:
+			req.ResponseNonce = string(value)
+		case 6:
+			// google.rpc.Status: message = 2
+			req.ErrorMessage = "unknown error"
+			return fields(value, func(num int, value []byte) error {
+				if num == 2 {
+					req.ErrorMessage = string(value)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	return req, err
+}
+
+func (this *discoveryResponse) encode() []byte {
+	var data []byte
+	data = field(data, 1, []byte(this.VersionInfo))
+	for _, r := range this.Resources {
+		// google.protobuf.Any
+		var any []byte
+		any = field(any, 1, []byte(SecretTypeURL))
+		any = field(any, 2, r)
+		data = field(data, 2, any)
+	}
+	data = field(data, 4, []byte(this.TypeURL))
+	return field(data, 5, []byte(this.Nonce))
+}
+
+func (this *secret) encode() []byte {
+	var data []byte
+	data = field(data, 1, []byte(this.Name))
+	if this.CertificateChain != nil {
+		var tls []byte
+		tls = field(tls, 1, field(nil, 2, this.CertificateChain))
+		tls = field(tls, 2, field(nil, 2, this.PrivateKey))
+		data = field(data, 2, tls)
+	}
+	if this.TrustedCA != nil {
+		data = field(data, 4, field(nil, 1, field(nil, 2, this.TrustedCA)))
+	}
+	return data
+}
@@ -0,0 +1,363 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package sds
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gardener/controller-manager-library/pkg/cert"
+	"github.com/gardener/controller-manager-library/pkg/certs"
+	"github.com/gardener/controller-manager-library/pkg/logger"
+
+	"golang.org/x/net/http2"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+)
+
+// Envoy Secret Discovery Service (SDS) serving the bidirectional
+// streaming gRPC call SecretDiscoveryService/StreamSecrets (api_type: GRPC)
+// over plaintext HTTP/2. Updated secrets are pushed on the stream
+// whenever the certificate source rotates its certificate or CA bundle.
+// Because the responses contain the private key, the server must only be
+// served on a local endpoint (for example a unix domain socket used as
+// pipe address by Envoy).
+
+const (
+	// Path is the gRPC method path for streaming secret discovery
+	Path = "/envoy.service.secret.v3.SecretDiscoveryService/StreamSecrets"
+	// SecretTypeURL is the xDS type url of SDS secrets
+	SecretTypeURL = "type.googleapis.com/envoy.extensions.transport_sockets.tls.v3.Secret"
+
+	// DefaultCertificateName is the default name of the TLS certificate secret
+	DefaultCertificateName = "default"
+	// DefaultValidationContextName is the default name of the validation context secret
+	DefaultValidationContextName = "ROOTCA"
+)
+
+// gRPC status codes
+const (
+	grpcOK              = 0
+	grpcInvalidArgument = 3
+	grpcInternal        = 13
+)
+
+// Handler serves the certificate of a certificate source as SDS secrets.
+// The CA bundle is provided as validation context if the source
+// implements certs.CABundleSource.
+type Handler struct {
+	logger                logger.LogContext
+	source                certs.CertificateSource
+	CertificateName       string
+	ValidationContextName string
+
+	lock    sync.Mutex
+	changed chan struct{}
+}
+
+var _ http.Handler = &Handler{}
+
+func NewHandler(logger logger.LogContext, source certs.CertificateSource) *Handler {
+	this := &Handler{
+		logger:                logger,
+		source:                source,
+		CertificateName:       DefaultCertificateName,
+		ValidationContextName: DefaultValidationContextName,
+		changed:               make(chan struct{}),
+	}
+	if n, ok := source.(certs.NotifyingCertificateSource); ok {
+		n.RegisterNotifier(func(_ cert.CertificateInfo) { this.notify() })
+	}
+	return this
+}
+
+// signal returns a channel closed on the next change of the source.
+func (this *Handler) signal() <-chan struct{} {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.changed
+}
+
+func (this *Handler) notify() {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	close(this.changed)
+	this.changed = make(chan struct{})
+}
+
+// watchCA propagates CA bundle changes to the open streams until the
+// context is done.
+func (this *Handler) watchCA(ctx context.Context) {
+	ca, ok := this.source.(certs.CABundleSource)
+	if !ok {
+		return
+	}
+	changes := ca.CAChanges()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-changes:
+				this.notify()
+			}
+		}
+	}()
+}
+
+func (this *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	code, msg := this.stream(r, func(data []byte) error {
+		frame := make([]byte, 5, 5+len(data))
+		binary.BigEndian.PutUint32(frame[1:], uint32(len(data)))
+		if _, err := w.Write(append(frame, data...)); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+	w.Header().Set("Grpc-Status", fmt.Sprintf("%d", code))
+	if msg != "" {
+		w.Header().Set("Grpc-Message", msg)
+	}
+}
+
+// stream handles the discovery requests of a stream and pushes a
+// discovery response whenever the requested secrets change. It returns
+// the final gRPC status of the stream.
+func (this *Handler) stream(r *http.Request, send func([]byte) error) (int, string) {
+	done := make(chan struct{})
+	defer close(done)
+	requests := make(chan *discoveryRequest)
+	failed := make(chan error, 1)
+	go func() {
+		header := make([]byte, 5)
+		for {
+			if _, err := io.ReadFull(r.Body, header); err != nil {
+				failed <- err
+				return
+			}
+			if header[0] != 0 {
+				failed <- fmt.Errorf("compressed discovery requests not supported")
+				return
+			}
+			data := make([]byte, binary.BigEndian.Uint32(header[1:]))
+			if _, err := io.ReadFull(r.Body, data); err != nil {
+				failed <- err
+				return
+			}
+			req, err := decodeDiscoveryRequest(data)
+			if err != nil {
+				failed <- fmt.Errorf("invalid discovery request: %s", err)
+				return
+			}
+			select {
+			case requests <- req:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var names []string
+	var sent string
+	nonce := 0
+	update := func() error {
+		secrets, err := this.secrets(names)
+		if err != nil {
+			// retried with the next change of the source
+			this.logger.Errorf("sds: %s", err)
+			return nil
+		}
+		resp := &discoveryResponse{
+			TypeURL: SecretTypeURL,
+		}
+		for _, s := range secrets {
+			resp.Resources = append(resp.Resources, s.encode())
+		}
+		resp.VersionInfo = version(resp.Resources)
+		if resp.VersionInfo == sent {
+			return nil
+		}
+		nonce++
+		resp.Nonce = fmt.Sprintf("%d", nonce)
+		if err := send(resp.encode()); err != nil {
+			return err
+		}
+		sent = resp.VersionInfo
+		return nil
+	}
+
+	changed := this.signal()
+	for {
+		select {
+		case <-r.Context().Done():
+			return grpcOK, ""
+		case err := <-failed:
+			if err == io.EOF {
+				return grpcOK, ""
+			}
+			return grpcInvalidArgument, err.Error()
+		case req := <-requests:
+			if req.TypeURL != "" && req.TypeURL != SecretTypeURL {
+				return grpcInvalidArgument, fmt.Sprintf("unsupported type url %q", req.TypeURL)
+			}
+			if req.ErrorMessage != "" {
+				this.logger.Warnf("sds: secrets version %s rejected: %s", sent, req.ErrorMessage)
+			}
+			names = req.ResourceNames
+		case <-changed:
+		}
+		changed = this.signal()
+		if err := update(); err != nil {
+			return grpcInternal, err.Error()
+		}
+	}
+}
+
+func (this *Handler) secrets(names []string) ([]*secret, error) {
+	requested := func(name string) bool {
+		if len(names) == 0 {
+			return true
+		}
+		for _, n := range names {
+			if n == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	var result []*secret
+	if requested(this.CertificateName) {
+		c, err := this.source.GetCertificate(nil)
+		if err != nil {
+			return nil, err
+		}
+		if c == nil {
+			return nil, fmt.Errorf("no certificate available")
+		}
+		var chain []byte
+		for _, raw := range c.Certificate {
+			chain = append(chain, pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: raw})...)
+		}
+		key, err := x509.MarshalPKCS8PrivateKey(c.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode private key: %s", err)
+		}
+		result = append(result, &secret{
+			Name:             this.CertificateName,
+			CertificateChain: chain,
+			PrivateKey:       pem.EncodeToMemory(&pem.Block{Type: keyutil.PrivateKeyBlockType, Bytes: key}),
+		})
+	}
+	if ca, ok := this.source.(certs.CABundleSource); ok && requested(this.ValidationContextName) {
+		if bundle := ca.GetCABundle(); len(bundle) > 0 {
+			result = append(result, &secret{
+				Name:      this.ValidationContextName,
+				TrustedCA: bundle,
+			})
+		}
+	}
+	return result, nil
+}
+
+func version(resources [][]byte) string {
+	hash := sha256.New()
+	for _, r := range resources {
+		hash.Write(r)
+	}
+	return hex.EncodeToString(hash.Sum(nil)[:8])
+}
+
+// Serve serves the SDS handler with plaintext HTTP/2 (h2c with prior
+// knowledge, as used by the Envoy gRPC client) on the given listener
+// until the context is done.
+func Serve(ctx context.Context, logger logger.LogContext, listener net.Listener, handler *Handler) {
+	mux := http.NewServeMux()
+	mux.Handle(Path, handler)
+	server := &http2.Server{}
+	opts := &http2.ServeConnOpts{Handler: mux}
+
+	lock := sync.Mutex{}
+	conns := map[net.Conn]struct{}{}
+
+	handler.watchCA(ctx)
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+		lock.Lock()
+		defer lock.Unlock()
+		for c := range conns {
+			c.Close()
+		}
+	}()
+	go func() {
+		logger.Infof("SDS server started (serving on %s)", listener.Addr())
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.Errorf("SDS server failed: %s", err)
+				}
+				return
+			}
+			lock.Lock()
+			if ctx.Err() != nil {
+				lock.Unlock()
+				conn.Close()
+				return
+			}
+			conns[conn] = struct{}{}
+			lock.Unlock()
+			go func() {
+				server.ServeConn(conn, opts)
+				conn.Close()
+				lock.Lock()
+				delete(conns, conn)
+				lock.Unlock()
+			}()
+		}
+	}()
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package secret
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"sync"
+
+	"github.com/gardener/controller-manager-library/pkg/cert"
+	"github.com/gardener/controller-manager-library/pkg/certs"
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/certmgmt"
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/cluster"
+	"github.com/gardener/controller-manager-library/pkg/logger"
+	"github.com/gardener/controller-manager-library/pkg/resources"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CACertKey is the secret key of the optional CA bundle
+const CACertKey = "ca.crt"
+
+// SecretSource is a certificate source reading an externally maintained
+// certificate (for example a kubernetes.io/tls secret) from a secret.
+// The secret is watched and the certificate is reloaded on every change.
+// It can be used as server or as client certificate source.
+type SecretSource struct {
+	certs.Notifiers
+	certs.CABundleChannels
+	lock    sync.RWMutex
+	logger  logger.LogContext
+	name    resources.ObjectName
+	info    cert.CertificateInfo
+	current *tls.Certificate
+}
+
+var _ certs.NotifyingCertificateSource = &SecretSource{}
+var _ certs.CertificateAuthoritySource = &SecretSource{}
+var _ certs.ClientCertificateSource = &SecretSource{}
+
+func New(logger logger.LogContext, cluster cluster.Interface, name resources.ObjectName) (*SecretSource, error) {
+	this := &SecretSource{
+		logger: logger,
+		name:   name,
+	}
+	this.Instrument(name.String())
+	secret, err := resources.GetSecret(cluster, name.Namespace(), name.Name())
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+		logger.Warnf("certificate secret %s not found", name)
+	} else {
+		if err := this.update(secret.Secret()); err != nil {
+			return nil, err
+		}
+	}
+
+	r, err := cluster.GetResource(schema.GroupKind{Group: corev1.GroupName, Kind: "Secret"})
+	if err != nil {
+		return nil, err
+	}
+	matches := func(obj resources.Object) bool {
+		return obj.GetNamespace() == name.Namespace() && obj.GetName() == name.Name()
+	}
+	err = r.AddSelectedEventHandler(resources.ResourceEventHandlerFuncs{
+		AddFunc: func(obj resources.Object) {
+			if matches(obj) {
+				this.handle(obj)
+			}
+		},
+		UpdateFunc: func(old, new resources.Object) {
+			if matches(new) {
+				this.handle(new)
+			}
+		},
+		DeleteFunc: func(obj resources.Object) {
+			if matches(obj) {
+				this.logger.Warnf("certificate secret %s deleted, keeping current certificate", name)
+			}
+		},
+	}, name.Namespace(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot watch secret %s: %s", name, err)
+	}
+	return this, nil
+}
+
+func (this *SecretSource) handle(obj resources.Object) {
+	if s, ok := obj.Data().(*corev1.Secret); ok {
+		if err := this.update(s); err != nil {
+			this.logger.Errorf("cannot update certificate from secret %s: %s", this.name, err)
+			this.NotifyFailure()
+		}
+	}
+}
+
+func (this *SecretSource) update(secret *corev1.Secret) error {
+	info := cert.NewCertInfo(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey], secret.Data[CACertKey], nil)
+	tlscert, err := tls.X509KeyPair(info.Cert(), info.Key())
+	if err != nil {
+		return fmt.Errorf("invalid certificate: %s", err)
+	}
+
+	this.lock.Lock()
+	changed := this.info == nil || !bytes.Equal(this.info.Cert(), info.Cert()) || !bytes.Equal(this.info.Key(), info.Key())
+	cachanged := this.info == nil || !bytes.Equal(this.info.CACert(), info.CACert())
+	this.info = info
+	this.current = &tlscert
+	this.lock.Unlock()
+
+	if changed {
+		this.logger.Infof("updated current TLS certificate: %s", certmgmt.Describe(info))
+		this.Notify(info)
+	}
+	if cachanged {
+		this.NotifyCA(info.CACert())
+	}
+	return nil
+}
+
+func (this *SecretSource) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.current, nil
+}
+
+func (this *SecretSource) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	c, _ := this.GetCertificate(nil)
+	if c == nil {
+		return &tls.Certificate{}, nil
+	}
+	return c, nil
+}
+
+func (this *SecretSource) GetCABundle() []byte {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	if this.info == nil {
+		return nil
+	}
+	return this.info.CACert()
+}
+
+func (this *SecretSource) GetCACertificates() []*x509.Certificate {
+	return certs.ParseCABundle(this.GetCABundle())
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package selfsigned
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/cert"
+	"github.com/gardener/controller-manager-library/pkg/certs/access"
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/certmgmt"
+	"github.com/gardener/controller-manager-library/pkg/logger"
+)
+
+// New creates an in-memory certificate source with an ephemeral CA and
+// a server certificate for the given DNS names. Nothing is persisted,
+// therefore it is intended for tests and local development only.
+func New(ctx context.Context, logger logger.LogContext, commonName string, dnsNames ...string) (*access.AccessSource, error) {
+	if len(dnsNames) == 0 {
+		return nil, fmt.Errorf("at least one DNS name required for self-signed certificate")
+	}
+	cfg := &certmgmt.Config{
+		Settings: cert.Settings{
+			CommonName: commonName,
+			DNSName:    dnsNames[0],
+			DNSNames:   dnsNames[1:],
+		},
+		Rest: 7 * 24 * time.Hour,
+	}
+	return NewWithConfig(ctx, logger, cfg)
+}
+
+// NewWithConfig creates an in-memory certificate source for an explicit
+// certificate configuration.
+func NewWithConfig(ctx context.Context, logger logger.LogContext, cfg *certmgmt.Config) (*access.AccessSource, error) {
+	return access.New(ctx, logger, certmgmt.NewMemory(), cfg)
+}
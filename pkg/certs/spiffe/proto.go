@@ -0,0 +1,145 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package spiffe
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Minimal protobuf decoding of the X509SVIDResponse message of the
+// SPIFFE Workload API:
+//
+//   message X509SVIDResponse {
+//     repeated X509SVID svids = 1;
+//     repeated bytes crl = 2;
+//     map<string, bytes> federated_bundles = 3;
+//   }
+//   message X509SVID {
+//     string spiffe_id = 1;
+//     bytes x509_svid = 2;
+//     bytes x509_svid_key = 3;
+//     bytes bundle = 4;
+//   }
+
+type svid struct {
+	SpiffeID string
+	Certs    []byte
+	Key      []byte
+	Bundle   []byte
+}
+
+type svidResponse struct {
+	SVIDs            []svid
+	FederatedBundles map[string][]byte
+}
+
+const (
+	wireVarint = 0
+	wire64Bit  = 1
+	wireBytes  = 2
+	wire32Bit  = 5
+)
+
+// fields calls f for every field of a protobuf message. Only length
+// delimited values are passed, other wire types are skipped.
+func fields(data []byte, f func(num int, value []byte) error) error {
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fmt.Errorf("invalid protobuf field key")
+		}
+		data = data[n:]
+		num, wire := int(key>>3), int(key&7)
+		switch wire {
+		case wireVarint:
+			_, n = binary.Uvarint(data)
+			if n <= 0 {
+				return fmt.Errorf("invalid protobuf varint")
+			}
+			data = data[n:]
+		case wire64Bit:
+			if len(data) < 8 {
+				return fmt.Errorf("truncated protobuf message")
+			}
+			data = data[8:]
+		case wire32Bit:
+			if len(data) < 4 {
+				return fmt.Errorf("truncated protobuf message")
+			}
+			data = data[4:]
+		case wireBytes:
+			l, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < l {
+				return fmt.Errorf("truncated protobuf message")
+			}
+			value := data[n : n+int(l)]
+			data = data[n+int(l):]
+			if err := f(num, value); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", wire)
+		}
+	}
+	return nil
+}
+
+func decodeSVIDResponse(data []byte) (*svidResponse, error) {
+	resp := &svidResponse{FederatedBundles: map[string][]byte{}}
+	err := fields(data, func(num int, value []byte) error {
+		switch num {
+		case 1:
+			s := svid{}
+			err := fields(value, func(num int, value []byte) error {
+				switch num {
+				case 1:
+					s.SpiffeID = string(value)
+				case 2:
+					s.Certs = value
+				case 3:
+					s.Key = value
+				case 4:
+					s.Bundle = value
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			resp.SVIDs = append(resp.SVIDs, s)
+		case 3:
+			var key string
+			var bundle []byte
+			err := fields(value, func(num int, value []byte) error {
+				switch num {
+				case 1:
+					key = string(value)
+				case 2:
+					bundle = value
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			resp.FederatedBundles[key] = bundle
+		}
+		return nil
+	})
+	return resp, err
+}
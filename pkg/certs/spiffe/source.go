@@ -0,0 +1,277 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package spiffe
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/cert"
+	"github.com/gardener/controller-manager-library/pkg/certs"
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/certmgmt"
+	"github.com/gardener/controller-manager-library/pkg/logger"
+
+	"golang.org/x/net/http2"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+)
+
+const (
+	// SocketEnv is the environment variable providing the Workload API endpoint
+	SocketEnv = "SPIFFE_ENDPOINT_SOCKET"
+	// DefaultSocket is used if no endpoint is configured
+	DefaultSocket = "unix:///run/spire/sockets/agent.sock"
+
+	fetchX509SVIDPath = "/SpiffeWorkloadAPI/FetchX509SVID"
+)
+
+// SPIFFESource is a certificate source streaming X509-SVIDs from the
+// SPIFFE Workload API (for example a SPIRE agent). The trust bundle
+// of the SVID is provided as CA bundle. Rotations are pushed by the
+// Workload API, the stream is reestablished on failures.
+type SPIFFESource struct {
+	certs.Notifiers
+	certs.CABundleChannels
+	lock     sync.RWMutex
+	logger   logger.LogContext
+	socket   string
+	client   *http.Client
+	spiffeID string
+	info     cert.CertificateInfo
+	current  *tls.Certificate
+	ready    chan struct{}
+	once     sync.Once
+}
+
+var _ certs.NotifyingCertificateSource = &SPIFFESource{}
+var _ certs.CertificateAuthoritySource = &SPIFFESource{}
+var _ certs.ClientCertificateSource = &SPIFFESource{}
+
+// New connects to the Workload API at the given address (unix:// URL or
+// path). If the address is empty the SPIFFE_ENDPOINT_SOCKET environment
+// variable or the default socket is used. New waits for the first SVID
+// until the context is done.
+func New(ctx context.Context, logger logger.LogContext, address string) (*SPIFFESource, error) {
+	if address == "" {
+		address = os.Getenv(SocketEnv)
+	}
+	if address == "" {
+		address = DefaultSocket
+	}
+	socket := strings.TrimPrefix(address, "unix://")
+	this := &SPIFFESource{
+		logger: logger,
+		socket: socket,
+		ready:  make(chan struct{}),
+		client: &http.Client{
+			Transport: &http2.Transport{
+				AllowHTTP: true,
+				DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+					return net.Dial("unix", socket)
+				},
+			},
+		},
+	}
+	this.Instrument(address)
+	go this.run(ctx)
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("no SVID received from workload API %s: %s", address, ctx.Err())
+	case <-this.ready:
+	}
+	return this, nil
+}
+
+func (this *SPIFFESource) run(ctx context.Context) {
+	backoff := time.Second
+	for {
+		received, err := this.watch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if received {
+			// the stream was established successfully
+			backoff = time.Second
+		}
+		this.logger.Warnf("workload API stream failed (retry in %s): %s", backoff, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < time.Minute {
+			backoff *= 2
+		}
+	}
+}
+
+// watch performs the server streaming gRPC call FetchX509SVID.
+// It reports whether a response has been received on the stream.
+func (this *SPIFFESource) watch(ctx context.Context) (bool, error) {
+	// empty X509SVIDRequest message in gRPC framing
+	frame := []byte{0, 0, 0, 0, 0}
+	req, err := http.NewRequest(http.MethodPost, "http://localhost"+fetchX509SVIDPath, bytes.NewReader(frame))
+	if err != nil {
+		return false, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("TE", "trailers")
+	req.Header.Set("workload.spiffe.io", "true")
+	resp, err := this.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("workload API request failed: %s", resp.Status)
+	}
+	if status := resp.Header.Get("Grpc-Status"); status != "" && status != "0" {
+		return false, fmt.Errorf("workload API error %s: %s", status, resp.Header.Get("Grpc-Message"))
+	}
+
+	received := false
+	header := make([]byte, 5)
+	for {
+		if _, err := io.ReadFull(resp.Body, header); err != nil {
+			if err == io.EOF {
+				if status := resp.Trailer.Get("Grpc-Status"); status != "" && status != "0" {
+					return received, fmt.Errorf("workload API error %s: %s", status, resp.Trailer.Get("Grpc-Message"))
+				}
+				return received, fmt.Errorf("workload API stream closed")
+			}
+			return received, err
+		}
+		if header[0] != 0 {
+			return received, fmt.Errorf("compressed workload API messages not supported")
+		}
+		msg := make([]byte, binary.BigEndian.Uint32(header[1:]))
+		if _, err := io.ReadFull(resp.Body, msg); err != nil {
+			return received, err
+		}
+		received = true
+		svids, err := decodeSVIDResponse(msg)
+		if err != nil {
+			return received, fmt.Errorf("invalid workload API response: %s", err)
+		}
+		if len(svids.SVIDs) == 0 {
+			this.logger.Warnf("workload API provided no SVID")
+			continue
+		}
+		if err := this.update(&svids.SVIDs[0]); err != nil {
+			this.logger.Errorf("invalid SVID: %s", err)
+			this.NotifyFailure()
+		}
+	}
+}
+
+func (this *SPIFFESource) update(s *svid) error {
+	chain, err := x509.ParseCertificates(s.Certs)
+	if err != nil {
+		return err
+	}
+	bundle, err := x509.ParseCertificates(s.Bundle)
+	if err != nil {
+		return err
+	}
+	if _, err := x509.ParsePKCS8PrivateKey(s.Key); err != nil {
+		return err
+	}
+	var certData, caData []byte
+	for _, c := range chain {
+		certData = append(certData, pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: c.Raw})...)
+	}
+	for _, c := range bundle {
+		caData = append(caData, pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: c.Raw})...)
+	}
+	keyData := pem.EncodeToMemory(&pem.Block{Type: keyutil.PrivateKeyBlockType, Bytes: s.Key})
+	tlscert, err := tls.X509KeyPair(certData, keyData)
+	if err != nil {
+		return err
+	}
+	info := cert.NewCertInfo(certData, keyData, caData, nil)
+
+	this.lock.Lock()
+	changed := this.info == nil || !bytes.Equal(this.info.Cert(), certData)
+	cachanged := this.info == nil || !bytes.Equal(this.info.CACert(), caData)
+	this.spiffeID = s.SpiffeID
+	this.info = info
+	this.current = &tlscert
+	this.lock.Unlock()
+	this.once.Do(func() { close(this.ready) })
+
+	if changed {
+		this.logger.Infof("updated SVID %s: %s", s.SpiffeID, certmgmt.Describe(info))
+		this.Notify(info)
+	}
+	if cachanged {
+		this.NotifyCA(caData)
+	}
+	return nil
+}
+
+// SpiffeID returns the SPIFFE ID of the actual SVID
+func (this *SPIFFESource) SpiffeID() string {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.spiffeID
+}
+
+func (this *SPIFFESource) GetCertificateInfo() cert.CertificateInfo {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.info
+}
+
+func (this *SPIFFESource) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.current, nil
+}
+
+func (this *SPIFFESource) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	c, _ := this.GetCertificate(nil)
+	if c == nil {
+		return &tls.Certificate{}, nil
+	}
+	return c, nil
+}
+
+func (this *SPIFFESource) GetCABundle() []byte {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	if this.info == nil {
+		return nil
+	}
+	return this.info.CACert()
+}
+
+func (this *SPIFFESource) GetCACertificates() []*x509.Certificate {
+	return certs.ParseCABundle(this.GetCABundle())
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package certs
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/logger"
+)
+
+// DefaultCipherSuites are the cipher suites used for TLS 1.2 if
+// not configured otherwise.
+var DefaultCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305,
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305,
+}
+
+// TLSOptions configure a TLS server configuration created by TLSConfigFor.
+// The zero value is a sensible default.
+type TLSOptions struct {
+	// MinVersion defaults to TLS 1.2
+	MinVersion uint16
+	// CipherSuites default to DefaultCipherSuites
+	CipherSuites []uint16
+	// NextProtos defaults to h2 and http/1.1. DisableHTTP2 removes h2.
+	NextProtos   []string
+	DisableHTTP2 bool
+
+	// ClientCA enables client certificate verification with the actual
+	// CA bundle of the given source.
+	ClientCA CABundleSource
+	// ClientAuth defaults to tls.RequireAndVerifyClientCert if a ClientCA is set.
+	ClientAuth tls.ClientAuthType
+
+	// SessionTicketRotation enables the periodic rotation of the session
+	// ticket keys until the Context is done. Both fields are required.
+	SessionTicketRotation time.Duration
+	Context               context.Context
+}
+
+// TLSConfigFor creates a TLS server configuration serving the
+// certificate of the given source.
+func TLSConfigFor(source CertificateSource, opts *TLSOptions) *tls.Config {
+	if opts == nil {
+		opts = &TLSOptions{}
+	}
+	cfg := &tls.Config{
+		GetCertificate: source.GetCertificate,
+		MinVersion:     opts.MinVersion,
+		CipherSuites:   opts.CipherSuites,
+		NextProtos:     opts.NextProtos,
+	}
+	if cfg.MinVersion == 0 {
+		cfg.MinVersion = tls.VersionTLS12
+	}
+	if len(cfg.CipherSuites) == 0 {
+		cfg.CipherSuites = DefaultCipherSuites
+	}
+	if len(cfg.NextProtos) == 0 {
+		cfg.NextProtos = []string{"h2", "http/1.1"}
+	}
+	if opts.DisableHTTP2 {
+		var protos []string
+		for _, p := range cfg.NextProtos {
+			if p != "h2" {
+				protos = append(protos, p)
+			}
+		}
+		cfg.NextProtos = protos
+	}
+
+	if opts.ClientCA != nil {
+		cfg.ClientAuth = opts.ClientAuth
+		if cfg.ClientAuth == tls.NoClientCert {
+			cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+		// the client CA pool is determined per connection to respect CA rotations
+		cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			c := cfg.Clone()
+			c.GetConfigForClient = nil
+			pool := x509.NewCertPool()
+			for _, ca := range opts.ClientCA.GetCACertificates() {
+				pool.AddCert(ca)
+			}
+			c.ClientCAs = pool
+			return c, nil
+		}
+	}
+
+	if opts.SessionTicketRotation > 0 && opts.Context != nil {
+		go rotateSessionTickets(opts.Context, cfg, opts.SessionTicketRotation)
+	}
+	return cfg
+}
+
+// rotateSessionTickets periodically replaces the session ticket key,
+// the previous key is kept to decrypt tickets issued before the rotation.
+func rotateSessionTickets(ctx context.Context, cfg *tls.Config, period time.Duration) {
+	var keys [][32]byte
+	rotate := func() {
+		var key [32]byte
+		if _, err := io.ReadFull(rand.Reader, key[:]); err != nil {
+			logger.Errorf("cannot generate session ticket key: %s", err)
+			return
+		}
+		keys = append([][32]byte{key}, keys...)
+		if len(keys) > 2 {
+			keys = keys[:2]
+		}
+		cfg.SetSessionTicketKeys(keys)
+	}
+	rotate()
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rotate()
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package certmgmt
+
+import (
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/cert"
+)
+
+// Environment provides the clock and the randomness used by the
+// certificate management. It is set by the Environment field of a Config.
+type Environment = cert.Environment
+
+// Clock provides the actual time for an Environment
+type Clock = cert.Clock
+
+// Config describes the requested server certificate and the conditions
+// for its renewal.
+type Config struct {
+	cert.Settings
+
+	// Rest is the minimal remaining validity period of a valid certificate.
+	// It is also used for the CA certificate, if CARest is not set.
+	Rest time.Duration
+	// RenewBeforePercent is the percentage of the certificate lifetime that
+	// must be left. If set, a certificate is considered stale once more than
+	// (100-RenewBeforePercent) percent of its lifetime has passed.
+	RenewBeforePercent int
+}
+
+func IsValid(info cert.CertificateInfo, cfg *Config) bool {
+	return Check(info, cfg).IsValid()
+}
+
+func UpdateCertificate(old cert.CertificateInfo, cfg *Config) (cert.CertificateInfo, error) {
+	if IsValid(old, cfg) {
+		bundle := cert.PruneCABundle(cfg.Environment, old.CACert(), cfg.CAOverlap)
+		if len(bundle) != len(old.CACert()) {
+			return cert.NewCertInfoWithHistory(old.Cert(), old.Key(), bundle, old.CAKey(), cert.PreviousGenerations(old)), nil
+		}
+		return old, nil
+	}
+	settings := cfg.Settings
+	settings.CARest = cfg.caRest()
+	return cert.RenewCertificate(old, &settings)
+}
+
+// caRest is the minimal remaining validity period of the CA certificate.
+// The same period is used to check and to renew the CA certificate,
+// otherwise a CA considered expiring would be kept by the renewal.
+func (this *Config) caRest() time.Duration {
+	if this.CARest > 0 {
+		return this.CARest
+	}
+	return this.Rest
+}
@@ -24,11 +24,25 @@ import (
 )
 
 func GetCertificateInfo(logger logger.LogContext, access CertificateAccess, commonName, dnsName string) (cert.CertificateInfo, error) {
+	cfg := &Config{
+		Settings: cert.Settings{
+			CommonName: commonName,
+			DNSName:    dnsName,
+		},
+		Rest: 7 * 24 * time.Hour,
+	}
+	return GetCertificate(logger, access, cfg)
+}
+
+func GetCertificate(logger logger.LogContext, access CertificateAccess, cfg *Config) (cert.CertificateInfo, error) {
 	r, err := access.Get(logger)
 	if err != nil {
 		return nil, fmt.Errorf("error reading from certificate access: %s", err)
 	}
-	r, err = cert.UpdateCertificate(r, commonName, dnsName, 7*24*time.Hour)
+	if report := Check(r, cfg); !report.IsValid() {
+		logger.Infof("certificate renewal required: %s", report)
+	}
+	r, err = UpdateCertificate(r, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("cert update failed: %s", err)
 	}
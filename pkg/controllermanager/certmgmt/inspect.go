@@ -0,0 +1,157 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package certmgmt
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/cert"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+// CertificateMetadata describes a single parsed certificate.
+type CertificateMetadata struct {
+	Subject      string
+	Issuer       string
+	SerialNumber string
+	Fingerprint  string
+	NotBefore    time.Time
+	NotAfter     time.Time
+	DNSNames     []string
+	IPAddresses  []string
+	IsCA         bool
+}
+
+func (this *CertificateMetadata) String() string {
+	s := fmt.Sprintf("subject=%q serial=%s sha256=%s notAfter=%s", this.Subject, this.SerialNumber, this.Fingerprint, this.NotAfter.UTC().Format(time.RFC3339))
+	if sans := this.SANs(); len(sans) > 0 {
+		s += fmt.Sprintf(" sans=%s", strings.Join(sans, ","))
+	}
+	return s
+}
+
+// SANs returns all subject alternative names (DNS names and IP addresses).
+func (this *CertificateMetadata) SANs() []string {
+	return append(append([]string{}, this.DNSNames...), this.IPAddresses...)
+}
+
+// CertificateInfoMetadata is the structured metadata of a certificate info.
+// CA is the list of certificates found in the CA bundle, Previous the list
+// of retained previous certificate generations.
+type CertificateInfoMetadata struct {
+	Certificate *CertificateMetadata
+	CA          []*CertificateMetadata
+	Previous    []*CertificateMetadata
+}
+
+func (this *CertificateInfoMetadata) String() string {
+	if this.Certificate == nil {
+		return "no certificate"
+	}
+	s := this.Certificate.String()
+	for _, c := range this.CA {
+		s += fmt.Sprintf(", ca: serial=%s sha256=%s notAfter=%s", c.SerialNumber, c.Fingerprint, c.NotAfter.UTC().Format(time.RFC3339))
+	}
+	return s
+}
+
+// Fingerprint returns the SHA-256 fingerprint of a certificate in the
+// usual colon separated upper case hex notation.
+func Fingerprint(c *x509.Certificate) string {
+	sum := sha256.Sum256(c.Raw)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
+
+// SerialNumber returns the serial number of a certificate in
+// colon separated hex notation.
+func SerialNumber(c *x509.Certificate) string {
+	hex := fmt.Sprintf("%X", c.SerialNumber)
+	if len(hex)%2 != 0 {
+		hex = "0" + hex
+	}
+	parts := make([]string, 0, len(hex)/2)
+	for i := 0; i < len(hex); i += 2 {
+		parts = append(parts, hex[i:i+2])
+	}
+	return strings.Join(parts, ":")
+}
+
+// NewCertificateMetadata extracts the metadata of a parsed certificate.
+func NewCertificateMetadata(c *x509.Certificate) *CertificateMetadata {
+	m := &CertificateMetadata{
+		Subject:      c.Subject.String(),
+		Issuer:       c.Issuer.String(),
+		SerialNumber: SerialNumber(c),
+		Fingerprint:  Fingerprint(c),
+		NotBefore:    c.NotBefore,
+		NotAfter:     c.NotAfter,
+		DNSNames:     append([]string{}, c.DNSNames...),
+		IsCA:         c.IsCA,
+	}
+	for _, ip := range c.IPAddresses {
+		m.IPAddresses = append(m.IPAddresses, ip.String())
+	}
+	return m
+}
+
+// Inspect parses the certificates of a certificate info into
+// structured metadata.
+func Inspect(info cert.CertificateInfo) (*CertificateInfoMetadata, error) {
+	if info == nil || len(info.Cert()) == 0 {
+		return nil, fmt.Errorf("no certificate found")
+	}
+	certs, err := certutil.ParseCertsPEM(info.Cert())
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate: %s", err)
+	}
+	result := &CertificateInfoMetadata{Certificate: NewCertificateMetadata(certs[0])}
+	if len(info.CACert()) > 0 {
+		cacerts, err := certutil.ParseCertsPEM(info.CACert())
+		if err != nil {
+			return nil, fmt.Errorf("invalid CA certificate: %s", err)
+		}
+		for _, c := range cacerts {
+			result.CA = append(result.CA, NewCertificateMetadata(c))
+		}
+	}
+	for _, g := range cert.PreviousGenerations(info) {
+		certs, err := certutil.ParseCertsPEM(g.Cert)
+		if err != nil {
+			return nil, fmt.Errorf("invalid previous certificate: %s", err)
+		}
+		result.Previous = append(result.Previous, NewCertificateMetadata(certs[0]))
+	}
+	return result, nil
+}
+
+// Describe returns a single line description of a certificate info
+// suitable for logging.
+func Describe(info cert.CertificateInfo) string {
+	m, err := Inspect(info)
+	if err != nil {
+		return err.Error()
+	}
+	return m.String()
+}
@@ -25,3 +25,10 @@ type CertificateAccess interface {
 	Get(logger.LogContext) (cert.CertificateInfo, error)
 	Set(logger.LogContext, cert.CertificateInfo) error
 }
+
+// WatchableCertificateAccess is a CertificateAccess able to notify
+// about external changes of the stored certificate.
+type WatchableCertificateAccess interface {
+	CertificateAccess
+	Watch(handler func()) error
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package certmgmt
+
+import (
+	"sync"
+
+	"github.com/gardener/controller-manager-library/pkg/cert"
+	"github.com/gardener/controller-manager-library/pkg/logger"
+)
+
+type memoryCertificateAccess struct {
+	lock sync.RWMutex
+	info cert.CertificateInfo
+}
+
+var _ CertificateAccess = &memoryCertificateAccess{}
+
+// NewMemory returns a certificate access keeping the certificate
+// info in memory only.
+func NewMemory() CertificateAccess {
+	return &memoryCertificateAccess{}
+}
+
+func (this *memoryCertificateAccess) Get(logger logger.LogContext) (cert.CertificateInfo, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.info, nil
+}
+
+func (this *memoryCertificateAccess) Set(logger logger.LogContext, info cert.CertificateInfo) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.info = info
+	return nil
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package certmgmt
+
+import (
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/cert"
+	"github.com/gardener/controller-manager-library/pkg/metrics"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+var (
+	certExpiry = metrics.NewGaugeFuncVec("certificate_expiry_seconds",
+		"Seconds until the server certificate expires", "name")
+	caExpiry = metrics.NewGaugeFuncVec("certificate_ca_expiry_seconds",
+		"Seconds until the CA certificate expires", "name")
+	rotations = metrics.NewCounterVec("certificate_rotations_total",
+		"Number of certificate rotations", "name")
+	lastRotation = metrics.NewGaugeVec("certificate_last_rotation_timestamp_seconds",
+		"Timestamp of the last certificate rotation", "name")
+	rotationFailures = metrics.NewCounterVec("certificate_rotation_failures_total",
+		"Number of failed certificate rotations", "name")
+)
+
+func init() {
+	metrics.MustRegister(certExpiry, caExpiry, rotations, lastRotation, rotationFailures)
+}
+
+// ReportCertificate updates the expiry metrics for the given certificate info
+func ReportCertificate(name string, info cert.CertificateInfo) {
+	if info == nil {
+		certExpiry.Delete(name)
+		caExpiry.Delete(name)
+		return
+	}
+	setExpiry(certExpiry, name, info.Cert())
+	setExpiry(caExpiry, name, info.CACert())
+}
+
+// ReportRotation records a successful certificate rotation
+func ReportRotation(name string, info cert.CertificateInfo) {
+	rotations.WithLabelValues(name).Inc()
+	lastRotation.WithLabelValues(name).Set(float64(time.Now().Unix()))
+	ReportCertificate(name, info)
+}
+
+// ReportRotationFailure records a failed certificate rotation
+func ReportRotationFailure(name string) {
+	rotationFailures.WithLabelValues(name).Inc()
+}
+
+func setExpiry(gauge *metrics.GaugeFuncVec, name string, data []byte) {
+	certs, err := certutil.ParseCertsPEM(data)
+	if err != nil || len(certs) == 0 {
+		gauge.Delete(name)
+		return
+	}
+	notAfter := certs[0].NotAfter
+	gauge.Set(func() float64 { return time.Until(notAfter).Seconds() }, name)
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package certmgmt
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/cert"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+const (
+	FindingMissingCertificate   = "MissingCertificate"
+	FindingMissingKey           = "MissingKey"
+	FindingMissingCACertificate = "MissingCACertificate"
+	FindingInvalidCertificate   = "InvalidCertificate"
+	FindingInvalidCACertificate = "InvalidCACertificate"
+	FindingKeyMismatch          = "KeyMismatch"
+	FindingDNSMismatch          = "DNSMismatch"
+	FindingCAMismatch           = "CAMismatch"
+	FindingExpiring             = "Expiring"
+	FindingLifetimeExceeded     = "LifetimeExceeded"
+	FindingCAExpiring           = "CAExpiring"
+	FindingMissingExtension     = "MissingExtension"
+)
+
+// Finding describes a single reason why a certificate is not valid
+// for a certificate config.
+type Finding struct {
+	Reason  string
+	Message string
+}
+
+func (this Finding) String() string {
+	return fmt.Sprintf("%s: %s", this.Reason, this.Message)
+}
+
+// Report is the result of a detailed validation of a certificate info.
+// The expiry timestamps are set as far as the certificates could be parsed.
+type Report struct {
+	Findings   []Finding
+	NotAfter   time.Time
+	CANotAfter time.Time
+}
+
+func (this *Report) add(reason, msgfmt string, args ...interface{}) {
+	this.Findings = append(this.Findings, Finding{Reason: reason, Message: fmt.Sprintf(msgfmt, args...)})
+}
+
+func (this *Report) IsValid() bool {
+	return len(this.Findings) == 0
+}
+
+func (this *Report) Has(reason string) bool {
+	for _, f := range this.Findings {
+		if f.Reason == reason {
+			return true
+		}
+	}
+	return false
+}
+
+func (this *Report) String() string {
+	if this.IsValid() {
+		return "valid"
+	}
+	msgs := []string{}
+	for _, f := range this.Findings {
+		msgs = append(msgs, f.String())
+	}
+	return strings.Join(msgs, ", ")
+}
+
+// Check validates a certificate info against a certificate config and
+// reports all reasons why it is not valid.
+func Check(info cert.CertificateInfo, cfg *Config) *Report {
+	report := &Report{}
+	if info == nil || len(info.Cert()) == 0 {
+		report.add(FindingMissingCertificate, "no certificate found")
+		return report
+	}
+	if len(info.Key()) == 0 {
+		report.add(FindingMissingKey, "no private key found")
+	}
+	if len(info.CACert()) == 0 {
+		report.add(FindingMissingCACertificate, "no CA certificate found")
+	}
+
+	certs, err := certutil.ParseCertsPEM(info.Cert())
+	if err != nil {
+		report.add(FindingInvalidCertificate, "cannot parse certificate: %s", err)
+		return report
+	}
+	c := certs[0]
+	report.NotAfter = c.NotAfter
+
+	now := cfg.Environment.Now()
+	if len(info.Key()) > 0 {
+		if _, err := tls.X509KeyPair(info.Cert(), info.Key()); err != nil {
+			report.add(FindingKeyMismatch, "key does not match certificate: %s", err)
+		}
+	}
+	for _, name := range cfg.AllDNSNames() {
+		if err := c.VerifyHostname(name); err != nil {
+			report.add(FindingDNSMismatch, "%s", err)
+		}
+	}
+	for _, ext := range cfg.Extensions {
+		if !cert.HasExtension(c, ext) {
+			report.add(FindingMissingExtension, "extension %s not found", ext.Id)
+		}
+	}
+	if now.Add(cfg.Rest).After(c.NotAfter) {
+		report.add(FindingExpiring, "certificate expires at %s", c.NotAfter.Format(time.RFC3339))
+	}
+	if cfg.RenewBeforePercent > 0 && !cert.HasRemainingLifetime(cfg.Environment, info.Cert(), cfg.RenewBeforePercent) {
+		report.add(FindingLifetimeExceeded, "less than %d%% of lifetime left (expires at %s)",
+			cfg.RenewBeforePercent, c.NotAfter.Format(time.RFC3339))
+	}
+
+	if len(info.CACert()) > 0 {
+		cacerts, err := certutil.ParseCertsPEM(info.CACert())
+		if err != nil {
+			report.add(FindingInvalidCACertificate, "cannot parse CA certificate: %s", err)
+			return report
+		}
+		report.CANotAfter = cacerts[0].NotAfter
+		if now.Add(cfg.caRest()).After(cacerts[0].NotAfter) {
+			report.add(FindingCAExpiring, "CA certificate expires at %s", cacerts[0].NotAfter.Format(time.RFC3339))
+		}
+		pool := x509.NewCertPool()
+		for _, ca := range cacerts {
+			pool.AddCert(ca)
+		}
+		t := now
+		if t.After(c.NotAfter) {
+			t = c.NotAfter
+		}
+		c.UnhandledCriticalExtensions = nil
+		_, err = c.Verify(x509.VerifyOptions{Roots: pool, CurrentTime: t})
+		if _, ok := err.(x509.UnknownAuthorityError); ok {
+			report.add(FindingCAMismatch, "certificate not signed by CA: %s", err)
+		}
+	}
+	return report
+}
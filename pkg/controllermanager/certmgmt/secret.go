@@ -17,6 +17,8 @@
 package certmgmt
 
 import (
+	"fmt"
+
 	"github.com/gardener/controller-manager-library/pkg/cert"
 	"github.com/gardener/controller-manager-library/pkg/controllermanager/cluster"
 	"github.com/gardener/controller-manager-library/pkg/fieldpath"
@@ -39,6 +41,16 @@ const (
 	CertName = "cert.pem"
 )
 
+// PreviousCertName is the name of the n-th (starting with 1) previous serving certificate
+func PreviousCertName(n int) string {
+	return fmt.Sprintf("cert-%d.pem", n)
+}
+
+// PreviousKeyName is the name of the n-th (starting with 1) previous server private key
+func PreviousKeyName(n int) string {
+	return fmt.Sprintf("key-%d.pem", n)
+}
+
 var dataField = fieldpath.RequiredField(&corev1.Secret{}, ".Data")
 
 type secretCertificateAccess struct {
@@ -82,6 +94,50 @@ func (this *secretCertificateAccess) Set(logger logger.LogContext, cert cert.Cer
 	return err
 }
 
+type watchedSecretCertificateAccess struct {
+	secretCertificateAccess
+}
+
+var _ WatchableCertificateAccess = &watchedSecretCertificateAccess{}
+
+// NewWatchedSecret returns a secret based certificate access notifying
+// about changes of the secret using the informer of the cluster.
+func NewWatchedSecret(cluster cluster.Interface, name resources.ObjectName) WatchableCertificateAccess {
+	return &watchedSecretCertificateAccess{
+		secretCertificateAccess{
+			cluster: cluster,
+			name:    name,
+		},
+	}
+}
+
+func (this *watchedSecretCertificateAccess) Watch(handler func()) error {
+	r, err := this.cluster.GetResource(schema.GroupKind{Group: corev1.GroupName, Kind: "Secret"})
+	if err != nil {
+		return err
+	}
+	matches := func(obj resources.Object) bool {
+		return obj.GetNamespace() == this.name.Namespace() && obj.GetName() == this.name.Name()
+	}
+	return r.AddSelectedEventHandler(resources.ResourceEventHandlerFuncs{
+		AddFunc: func(obj resources.Object) {
+			if matches(obj) {
+				handler()
+			}
+		},
+		UpdateFunc: func(old, new resources.Object) {
+			if matches(new) && old.GetResourceVersion() != new.GetResourceVersion() {
+				handler()
+			}
+		},
+		DeleteFunc: func(obj resources.Object) {
+			if matches(obj) {
+				handler()
+			}
+		},
+	}, this.name.Namespace(), nil)
+}
+
 func dataToCertInfo(data map[string][]byte) cert.CertificateInfo {
 	if data == nil {
 		return nil
@@ -91,14 +147,23 @@ func dataToCertInfo(data map[string][]byte) cert.CertificateInfo {
 	_cacert := data[CACertName]
 	_cakey := data[CAKeyName]
 
-	return cert.NewCertInfo(_cert, _key, _cacert, _cakey)
+	var previous []cert.Generation
+	for n := 1; data[PreviousCertName(n)] != nil; n++ {
+		previous = append(previous, cert.Generation{Cert: data[PreviousCertName(n)], Key: data[PreviousKeyName(n)]})
+	}
+	return cert.NewCertInfoWithHistory(_cert, _key, _cacert, _cakey, previous)
 }
 
-func certInfoToData(cert cert.CertificateInfo) map[string][]byte {
-	return map[string][]byte{
-		CAKeyName:  cert.CAKey(),
-		CACertName: cert.CACert(),
-		KeyName:    cert.Key(),
-		CertName:   cert.Cert(),
+func certInfoToData(info cert.CertificateInfo) map[string][]byte {
+	data := map[string][]byte{
+		CAKeyName:  info.CAKey(),
+		CACertName: info.CACert(),
+		KeyName:    info.Key(),
+		CertName:   info.Cert(),
+	}
+	for i, g := range cert.PreviousGenerations(info) {
+		data[PreviousCertName(i+1)] = g.Cert
+		data[PreviousKeyName(i+1)] = g.Key
 	}
+	return data
 }
@@ -38,6 +38,23 @@ const DEFAULT = "default"
 
 const SUBOPTION_ID = ".id"
 const SUBOPTION_DISABLE_DEPLOY_CRDS = ".disable-deploy-crds"
+const SUBOPTION_LIST_CHUNK_SIZE = ".list-chunk-size"
+const SUBOPTION_NAMESPACES = ".namespaces"
+const SUBOPTION_DISABLE_PROTOBUF = ".disable-protobuf"
+const SUBOPTION_DISABLE_DISCOVERY_WATCH = ".disable-discovery-watch"
+const SUBOPTION_CLIENT_QPS = ".client-qps"
+const SUBOPTION_CLIENT_BURST = ".client-burst"
+const SUBOPTION_IMPERSONATE_USER = ".impersonate-user"
+const SUBOPTION_IMPERSONATE_GROUPS = ".impersonate-groups"
+const SUBOPTION_IMPERSONATE_EXTRA = ".impersonate-extra"
+
+// context attributes for the client rate limit of a cluster
+const ATTR_CLIENT_QPS = "client-qps"
+const ATTR_CLIENT_BURST = "client-burst"
+
+// ATTR_IMPERSONATION is the context attribute for the impersonation
+// (restclient.ImpersonationConfig) used for all requests to a cluster
+const ATTR_IMPERSONATION = "impersonation"
 
 func Canonical(names []string) []string {
 	if names == nil {
@@ -71,6 +88,7 @@ type Interface interface {
 	Config() restclient.Config
 	Resources() resources.Resources
 	ResourceContext() resources.ResourceContext
+	Impersonate(cfg restclient.ImpersonationConfig) resources.Resources
 	IsLocal() bool
 	Definition() Definition
 
@@ -147,6 +165,12 @@ func (this *_Cluster) ResourceContext() resources.ResourceContext {
 	return this.rctx
 }
 
+// Impersonate returns the resources of the cluster accessed with the
+// given impersonation.
+func (this *_Cluster) Impersonate(cfg restclient.ImpersonationConfig) resources.Resources {
+	return this.rctx.Impersonate(cfg).Resources()
+}
+
 func (this *_Cluster) GetObject(spec interface{}) (resources.Object, error) {
 	return this.resources.GetObject(spec)
 }
@@ -168,6 +192,19 @@ func (this *_Cluster) GetServerVersion() *semver.Version {
 }
 
 func (this *_Cluster) setup(logger logger.LogContext) error {
+	if qps, ok := this.ctx.Value(ATTR_CLIENT_QPS).(int); ok && qps > 0 {
+		this.kubeConfig.QPS = float32(qps)
+	}
+	if burst, ok := this.ctx.Value(ATTR_CLIENT_BURST).(int); ok && burst > 0 {
+		this.kubeConfig.Burst = burst
+	}
+	if imp, ok := this.ctx.Value(ATTR_IMPERSONATION).(restclient.ImpersonationConfig); ok {
+		logger.Infof("impersonating user %q (groups %v) for cluster %q", imp.UserName, imp.Groups, this.name)
+		this.kubeConfig.Impersonate = imp
+	}
+	if this.kubeConfig.QPS > 0 || this.kubeConfig.Burst > 0 {
+		logger.Infof("client rate limit for cluster %q: qps %v, burst %d", this.name, this.kubeConfig.QPS, this.kubeConfig.Burst)
+	}
 	rctx, err := resources.NewResourceContext(this.ctx, this, nil, 0*time.Second)
 	if err != nil {
 		return err
@@ -205,6 +242,30 @@ func CreateCluster(ctx context.Context, logger logger.LogContext, req Definition
 	return cluster, nil
 }
 
+// CreateClusterForKubeconfig creates a cluster with the given name
+// for the content of a kubeconfig file.
+func CreateClusterForKubeconfig(ctx context.Context, logger logger.LogContext, req Definition, name, id string, kubeconfig []byte) (Interface, error) {
+	cluster := &_Cluster{name: name, attributes: map[interface{}]interface{}{}}
+
+	logger.Infof("using kubeconfig data for cluster %q[%s]", name, id)
+	kubeConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cluster %q: %s", name, err)
+	}
+
+	cluster.ctx = ctx
+	cluster.definition = req
+	cluster.id = id
+	cluster.kubeConfig = kubeConfig
+
+	err = cluster.setup(logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return cluster, nil
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 // cluster set
 ///////////////////////////////////////////////////////////////////////////////
@@ -217,6 +278,10 @@ type Clusters interface {
 	GetEffective(name string) Interface
 	GetAliases(name string) utils.StringSet
 
+	// With returns a copy of the cluster set additionally
+	// containing the given cluster.
+	With(name string, cluster Interface, info ...interface{}) Clusters
+
 	GetObject(key resources.ClusterObjectKey) (resources.Object, error)
 	GetCachedObject(key resources.ClusterObjectKey) (resources.Object, error)
 
@@ -284,6 +349,15 @@ func (this *_Clusters) GetClusters(name ...string) (Clusters, error) {
 	return clusters, nil
 }
 
+func (this *_Clusters) With(name string, cluster Interface, info ...interface{}) Clusters {
+	clusters := NewClusters()
+	for n, c := range this.clusters {
+		clusters.Add(n, c, this.infos[n])
+	}
+	clusters.Add(name, cluster, info...)
+	return clusters
+}
+
 func (this *_Clusters) GetAliases(name string) utils.StringSet {
 	set := this.mapped[name]
 	if set != nil {
@@ -307,17 +381,9 @@ func (this *_Clusters) String() string {
 }
 
 func (this *_Clusters) GetObject(key resources.ClusterObjectKey) (resources.Object, error) {
-	cluster := this.GetById(key.Cluster())
-	if cluster == nil {
-		return nil, fmt.Errorf("cluster with id %q not found")
-	}
-	return cluster.GetObject(key.ObjectKey())
+	return ResolveObject(this, key, false)
 }
 
 func (this *_Clusters) GetCachedObject(key resources.ClusterObjectKey) (resources.Object, error) {
-	cluster := this.GetById(key.Cluster())
-	if cluster == nil {
-		return nil, fmt.Errorf("cluster with id %q not found")
-	}
-	return cluster.GetCachedObject(key.ObjectKey())
+	return ResolveObject(this, key, true)
 }
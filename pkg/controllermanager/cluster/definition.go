@@ -19,12 +19,15 @@ package cluster
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/gardener/controller-manager-library/pkg/controllermanager/config"
 	"github.com/gardener/controller-manager-library/pkg/logger"
+	"github.com/gardener/controller-manager-library/pkg/resources"
 	"github.com/gardener/controller-manager-library/pkg/utils"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	restclient "k8s.io/client-go/rest"
 )
 
 const CLUSTERID_GROUP = "gardener.cloud"
@@ -43,6 +46,12 @@ type Definition interface {
 	Description() string
 	ConfigOptionName() string
 	Fallback() string
+
+	// DynamicSource returns the name of the cluster hosting the
+	// kubeconfig secrets for a dynamic cluster. It is empty for
+	// clusters configured by command line options.
+	DynamicSource() string
+	DynamicSelector() string
 }
 
 type _Definition struct {
@@ -50,10 +59,12 @@ type _Definition struct {
 	fallback         string
 	configOptionName string
 	description      string
+	dynamicSource    string
+	dynamicSelector  string
 }
 
 func copy(d Definition) *_Definition {
-	return &_Definition{d.Name(), d.Fallback(), d.ConfigOptionName(), d.Description()}
+	return &_Definition{d.Name(), d.Fallback(), d.ConfigOptionName(), d.Description(), d.DynamicSource(), d.DynamicSelector()}
 }
 
 func (this *_Definition) Name() string {
@@ -68,6 +79,12 @@ func (this *_Definition) Description() string {
 func (this *_Definition) Fallback() string {
 	return this.fallback
 }
+func (this *_Definition) DynamicSource() string {
+	return this.dynamicSource
+}
+func (this *_Definition) DynamicSelector() string {
+	return this.dynamicSelector
+}
 
 ////////////////////////////////////////////////////////////////////////////////
 
@@ -78,6 +95,34 @@ func (this *_Definitions) create(ctx context.Context, logger logger.LogContext,
 		id = idopt.StringValue()
 		logger.Infof("found id %q for cluster %q", id, req.Name())
 	}
+	qpsopt := cfg.GetOption(req.ConfigOptionName() + SUBOPTION_CLIENT_QPS)
+	if qpsopt != nil && qpsopt.Changed() {
+		ctx = context.WithValue(ctx, ATTR_CLIENT_QPS, qpsopt.IntValue())
+	}
+	burstopt := cfg.GetOption(req.ConfigOptionName() + SUBOPTION_CLIENT_BURST)
+	if burstopt != nil && burstopt.Changed() {
+		ctx = context.WithValue(ctx, ATTR_CLIENT_BURST, burstopt.IntValue())
+	}
+	imp, err := impersonation(cfg, req)
+	if err != nil {
+		return nil, err
+	}
+	if imp != nil {
+		ctx = context.WithValue(ctx, ATTR_IMPERSONATION, *imp)
+	}
+	chunkopt := cfg.GetOption(req.ConfigOptionName() + SUBOPTION_LIST_CHUNK_SIZE)
+	if chunkopt != nil && chunkopt.Changed() {
+		ctx = context.WithValue(ctx, resources.ATTR_LIST_CHUNK_SIZE, chunkopt.IntValue())
+	}
+	nsopt := cfg.GetOption(req.ConfigOptionName() + SUBOPTION_NAMESPACES)
+	if nsopt != nil && nsopt.Changed() {
+		logger.Infof("restricting informers of cluster %q to namespaces %s", req.Name(), nsopt.StringValue())
+		ctx = context.WithValue(ctx, resources.ATTR_NAMESPACES, nsopt.StringValue())
+	}
+	pbopt := cfg.GetOption(req.ConfigOptionName() + SUBOPTION_DISABLE_PROTOBUF)
+	if pbopt != nil && pbopt.Changed() && pbopt.BoolValue() {
+		ctx = context.WithValue(ctx, resources.ATTR_DISABLE_PROTOBUF, true)
+	}
 	cluster, err := CreateCluster(ctx, logger, req, id, option)
 	if err != nil {
 		return nil, err
@@ -88,6 +133,16 @@ func (this *_Definitions) create(ctx context.Context, logger logger.LogContext,
 		cluster.SetAttr(SUBOPTION_DISABLE_DEPLOY_CRDS, true)
 	}
 
+	dwopt := cfg.GetOption(req.ConfigOptionName() + SUBOPTION_DISABLE_DISCOVERY_WATCH)
+	if dwopt == nil || !dwopt.Changed() || !dwopt.BoolValue() {
+		// started in background to not block on missing permissions
+		go func() {
+			if err := cluster.ResourceContext().WatchDiscoveryChanges(); err != nil {
+				logger.Warnf("cannot watch discovery changes for cluster %s: %s", req.Name(), err)
+			}
+		}()
+	}
+
 	err = callExtensions(func(e Extension) error { return e.Extend(cluster, cfg) })
 	if err != nil {
 		return nil, err
@@ -96,6 +151,21 @@ func (this *_Definitions) create(ctx context.Context, logger logger.LogContext,
 	return cluster, nil
 }
 
+// CreateDynamicCluster creates a cluster for a dynamic cluster definition
+// using the content of a kubeconfig file.
+func CreateDynamicCluster(ctx context.Context, logger logger.LogContext, cfg *config.Config, req Definition, name string, kubeconfig []byte) (Interface, error) {
+	cluster, err := CreateClusterForKubeconfig(ctx, logger, req, name, "", kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+
+	err = callExtensions(func(e Extension) error { return e.Extend(cluster, cfg) })
+	if err != nil {
+		return nil, err
+	}
+	return cluster, nil
+}
+
 func (this *_Definitions) CreateClusters(ctx context.Context, logger logger.LogContext, cfg *config.Config, names utils.StringSet) (Clusters, error) {
 	clusters := NewClusters()
 	this.lock.RLock()
@@ -103,6 +173,18 @@ func (this *_Definitions) CreateClusters(ctx context.Context, logger logger.LogC
 
 	logger.Infof("required clusters: %s", names)
 
+	// dynamic clusters are created at runtime, but require their source cluster
+	static := utils.StringSet{}
+	for name := range names {
+		if req := this.definitions[name]; req != nil && req.DynamicSource() != "" {
+			logger.Infof("cluster %q is provided dynamically by secrets in cluster %q", name, req.DynamicSource())
+			static.Add(req.DynamicSource())
+		} else {
+			static.Add(name)
+		}
+	}
+	names = static
+
 	lastFound := -1
 	missing := names
 	for len(missing) > 0 && lastFound != len(clusters.clusters) {
@@ -195,7 +277,62 @@ func (this *_Definitions) ExtendConfig(cfg *config.Config) {
 
 			opt, _ = cfg.AddBoolOption(req.ConfigOptionName() + SUBOPTION_DISABLE_DEPLOY_CRDS)
 			opt.Description = fmt.Sprintf("disable deployment of required crds for cluster %s", req.Name())
+
+			opt, _ = cfg.AddIntOption(req.ConfigOptionName() + SUBOPTION_CLIENT_QPS)
+			opt.Description = fmt.Sprintf("maximum queries per second of the clients for cluster %s", req.Name())
+
+			opt, _ = cfg.AddIntOption(req.ConfigOptionName() + SUBOPTION_CLIENT_BURST)
+			opt.Description = fmt.Sprintf("maximum burst of the client rate limit for cluster %s", req.Name())
+
+			opt, _ = cfg.AddStringOption(req.ConfigOptionName() + SUBOPTION_IMPERSONATE_USER)
+			opt.Description = fmt.Sprintf("user to impersonate for all requests to cluster %s", req.Name())
+
+			opt, _ = cfg.AddStringArrayOption(req.ConfigOptionName() + SUBOPTION_IMPERSONATE_GROUPS)
+			opt.Description = fmt.Sprintf("groups to impersonate for all requests to cluster %s", req.Name())
+
+			opt, _ = cfg.AddStringArrayOption(req.ConfigOptionName() + SUBOPTION_IMPERSONATE_EXTRA)
+			opt.Description = fmt.Sprintf("extra user info (<key>=<value>) to impersonate for all requests to cluster %s", req.Name())
+
+			opt, _ = cfg.AddIntOption(req.ConfigOptionName() + SUBOPTION_LIST_CHUNK_SIZE)
+			opt.Description = fmt.Sprintf("chunk size for list calls for cluster %s (default %d, negative disables chunking)", req.Name(), resources.DEFAULT_LIST_CHUNK_SIZE)
+
+			opt, _ = cfg.AddStringOption(req.ConfigOptionName() + SUBOPTION_NAMESPACES)
+			opt.Description = fmt.Sprintf("comma separated list of namespaces the informers for cluster %s are restricted to", req.Name())
+
+			opt, _ = cfg.AddBoolOption(req.ConfigOptionName() + SUBOPTION_DISABLE_PROTOBUF)
+			opt.Description = fmt.Sprintf("use JSON instead of protobuf for the built-in API groups of cluster %s", req.Name())
+
+			opt, _ = cfg.AddBoolOption(req.ConfigOptionName() + SUBOPTION_DISABLE_DISCOVERY_WATCH)
+			opt.Description = fmt.Sprintf("disable invalidation of cached discovery information on CRD and APIService changes for cluster %s", req.Name())
 		}
 		callExtensions(func(e Extension) error { e.ExtendConfig(req, cfg); return nil })
 	}
 }
+
+// impersonation returns the impersonation configured for a cluster or nil.
+func impersonation(cfg *config.Config, req Definition) (*restclient.ImpersonationConfig, error) {
+	imp := restclient.ImpersonationConfig{}
+	if opt := cfg.GetOption(req.ConfigOptionName() + SUBOPTION_IMPERSONATE_USER); opt != nil && opt.Changed() {
+		imp.UserName = opt.StringValue()
+	}
+	if opt := cfg.GetOption(req.ConfigOptionName() + SUBOPTION_IMPERSONATE_GROUPS); opt != nil && opt.Changed() {
+		imp.Groups = opt.StringArray()
+	}
+	if opt := cfg.GetOption(req.ConfigOptionName() + SUBOPTION_IMPERSONATE_EXTRA); opt != nil && opt.Changed() {
+		imp.Extra = map[string][]string{}
+		for _, e := range opt.StringArray() {
+			i := strings.Index(e, "=")
+			if i <= 0 {
+				return nil, fmt.Errorf("invalid impersonation extra %q for cluster %s: expected <key>=<value>", e, req.Name())
+			}
+			imp.Extra[e[:i]] = append(imp.Extra[e[:i]], e[i+1:])
+		}
+	}
+	if imp.UserName == "" && len(imp.Groups) == 0 && len(imp.Extra) == 0 {
+		return nil, nil
+	}
+	if imp.UserName == "" {
+		return nil, fmt.Errorf("impersonation of groups or extra user info for cluster %s requires a user", req.Name())
+	}
+	return &imp, nil
+}
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"github.com/gardener/controller-manager-library/pkg/resources"
 	"github.com/gardener/controller-manager-library/pkg/utils"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sync"
 )
@@ -97,6 +98,14 @@ func (this *_Registry) RegisterCluster(reg Registerable) error {
 		if err != nil {
 			return err
 		}
+		err = utils.FillStringValue(msg, &new.dynamicSource, def.DynamicSource())
+		if err != nil {
+			return err
+		}
+		err = utils.FillStringValue(msg, &new.dynamicSelector, def.DynamicSelector())
+		if err != nil {
+			return err
+		}
 		def = new
 	}
 	this.definitions[def.Name()] = def
@@ -148,7 +157,7 @@ type Configuration struct {
 var _ Registerable = Configuration{}
 
 func Configure(name string, option string, short string) Configuration {
-	return Configuration{_Definition{name, "", option, short}}
+	return Configuration{_Definition{name: name, configOptionName: option, description: short}}
 }
 
 func (this Configuration) Fallback(name string) Configuration {
@@ -156,6 +165,19 @@ func (this Configuration) Fallback(name string) Configuration {
 	return this
 }
 
+// Dynamic declares a cluster provided at runtime by kubeconfig secrets
+// in the namespace of the controller manager in the given source cluster.
+// The secrets are selected by a label selector. Controllers using this
+// cluster are started once per secret.
+func (this Configuration) Dynamic(source string, selector string) Configuration {
+	if _, err := labels.Parse(selector); err != nil {
+		panic(fmt.Sprintf("invalid label selector %q for dynamic cluster %q: %s", selector, this.definition.name, err))
+	}
+	this.definition.dynamicSource = source
+	this.definition.dynamicSelector = selector
+	return this
+}
+
 func (this Configuration) Definition() Definition {
 	return &this.definition
 }
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/gardener/controller-manager-library/pkg/resources"
+)
+
+// ResolveObject returns the object for a cluster object key. The cluster is
+// looked up by the cluster id of the key. With cached set the object is
+// taken from the informer cache of the cluster, otherwise it is read from
+// the cluster. In both cases a missing object is reported by an error
+// for which errors.IsNotFound is true, whereas an unknown cluster or
+// resource is reported by another error.
+func ResolveObject(clusters Clusters, key resources.ClusterObjectKey, cached bool) (resources.Object, error) {
+	cluster := clusters.GetById(key.Cluster())
+	if cluster == nil {
+		return nil, fmt.Errorf("cluster with id %q not found for %s", key.Cluster(), key.ObjectKey())
+	}
+	if cached {
+		return cluster.GetCachedObject(key.ObjectKey())
+	}
+	return cluster.GetObject(key.ObjectKey())
+}
+
+// ResolveReference resolves a textual object reference relative to the
+// cluster with the given id. The reference has the format
+// [<cluster id>:]<group>/<kind>/<namespace>/<name> as generated by
+// ClusterObjectKey.AsRefFor. If the cluster id is omitted the object is
+// taken from the given cluster.
+func ResolveReference(clusters Clusters, clusterid string, ref string, cached bool) (resources.Object, error) {
+	key, err := resources.ParseClusterObjectKey(clusterid, ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid object reference %q: %s", ref, err)
+	}
+	return ResolveObject(clusters, key, cached)
+}
@@ -0,0 +1,246 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package controllermanager
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/cluster"
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/controller"
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/controller/mappings"
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/controller/reconcile"
+	"github.com/gardener/controller-manager-library/pkg/ctxutil"
+	"github.com/gardener/controller-manager-library/pkg/logger"
+	"github.com/gardener/controller-manager-library/pkg/resources"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DYNAMIC_CLUSTER_KUBECONFIG_KEY is the key of the secret data entry
+// holding the kubeconfig for a dynamic cluster.
+const DYNAMIC_CLUSTER_KUBECONFIG_KEY = "kubeconfig"
+
+// dynamicClusterFor returns the name of the dynamic cluster used by
+// a controller or an empty string for controllers using only
+// clusters configured by command line options.
+func (c *ControllerManager) dynamicClusterFor(def controller.Definition, cmp mappings.Definition) (string, error) {
+	found := ""
+	for i, n := range cluster.Canonical(def.RequiredClusters()) {
+		real, _ := mappings.MapCluster(i == 0, n, cmp)
+		cdef := c.definition.ClusterDefinitions().Get(real)
+		if cdef == nil || cdef.DynamicSource() == "" {
+			continue
+		}
+		if found != "" && found != real {
+			return "", fmt.Errorf("controller %q uses multiple dynamic clusters (%s and %s)", def.GetName(), found, real)
+		}
+		found = real
+	}
+	return found, nil
+}
+
+// newClusterRegistry creates a controller watching the kubeconfig secrets
+// for a dynamic cluster. For every secret the given controllers are
+// started with a dedicated context, which is cancelled when the secret
+// disappears or its kubeconfig changes.
+func (c *ControllerManager) newClusterRegistry(cdef cluster.Definition, registrations controller.Registrations, requireLease bool) (Controller, error) {
+	name := "cluster-registry-" + cdef.Name()
+	cfg := controller.Configure(name).
+		Cluster(cdef.DynamicSource()).
+		MainResource("core", "Secret", controller.Selection(c.config.Namespace, cdef.DynamicSelector(), "")).
+		DefaultWorkerPool(1, 0).
+		Reconciler(func(cntr controller.Interface) (reconcile.Interface, error) {
+			return &clusterRegistry{
+				manager:       c,
+				definition:    cdef,
+				registrations: registrations,
+				instances:     map[string]*dynamicCluster{},
+			}, nil
+		})
+	if requireLease {
+		cfg = cfg.RequireLease()
+	}
+	cmp, err := c.definition.GetMappingsFor(name)
+	if err != nil {
+		return nil, err
+	}
+	return controller.NewController(c, cfg.Definition(), cmp)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type dynamicCluster struct {
+	ctx     context.Context
+	cluster cluster.Interface
+	hash    string
+}
+
+type clusterRegistry struct {
+	reconcile.DefaultReconciler
+	manager       *ControllerManager
+	definition    cluster.Definition
+	registrations controller.Registrations
+
+	lock      sync.Mutex
+	instances map[string]*dynamicCluster
+}
+
+func (this *clusterRegistry) Reconcile(logger logger.LogContext, obj resources.Object) reconcile.Status {
+	secret, ok := obj.Data().(*corev1.Secret)
+	if !ok {
+		return reconcile.Failed(logger, fmt.Errorf("unexpected object %s", obj.Description()))
+	}
+	key := obj.ObjectName().String()
+
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	data := secret.Data[DYNAMIC_CLUSTER_KUBECONFIG_KEY]
+	if len(data) == 0 {
+		this.stop(logger, key)
+		return reconcile.Failed(logger, fmt.Errorf("secret %s has no entry %q", key, DYNAMIC_CLUSTER_KUBECONFIG_KEY))
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256(data))
+	if old := this.instances[key]; old != nil {
+		if old.hash == hash {
+			return reconcile.Succeeded(logger)
+		}
+		logger.Infof("kubeconfig for cluster %q changed", old.cluster.GetName())
+		this.stop(logger, key)
+	}
+
+	instance, err := this.start(logger, obj.GetName(), hash, data)
+	if err != nil {
+		return reconcile.Delay(logger, err)
+	}
+	this.instances[key] = instance
+	return reconcile.Succeeded(logger)
+}
+
+func (this *clusterRegistry) Delete(logger logger.LogContext, obj resources.Object) reconcile.Status {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.stop(logger, obj.ObjectName().String())
+	return reconcile.Succeeded(logger)
+}
+
+func (this *clusterRegistry) Deleted(logger logger.LogContext, key resources.ClusterObjectKey) reconcile.Status {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.stop(logger, key.ObjectName().String())
+	return reconcile.Succeeded(logger)
+}
+
+func (this *clusterRegistry) start(logger logger.LogContext, secret, hash string, kubeconfig []byte) (*dynamicCluster, error) {
+	m := this.manager
+	name := fmt.Sprintf("%s/%s", this.definition.Name(), secret)
+	ctx := ctxutil.SyncContext(ctxutil.CancelContext(m.ctx))
+
+	c, err := cluster.CreateDynamicCluster(ctx, logger, m.config, this.definition, name, kubeconfig)
+	if err != nil {
+		ctxutil.Cancel(ctx)
+		return nil, err
+	}
+	env := &dynamicEnvironment{
+		ControllerManager: m,
+		ctx:               ctx,
+		instance:          name,
+		clusters:          m.clusters.With(this.definition.Name(), c, fmt.Sprintf("%s (secret %s)", this.definition.Name(), secret)),
+	}
+
+	cntrs := []Controller{}
+	for _, def := range this.registrations {
+		var cntr Controller
+		cmp, err := m.definition.GetMappingsFor(def.GetName())
+		if err == nil {
+			cntr, err = controller.NewController(env, def, cmp)
+		}
+		if err == nil {
+			err = m.checkController(cntr)
+		}
+		if err != nil {
+			ctxutil.Cancel(ctx)
+			return nil, fmt.Errorf("cannot create controller %q for cluster %q: %s", def.GetName(), name, err)
+		}
+		cntrs = append(cntrs, cntr)
+	}
+
+	logger.Infof("starting controllers for cluster %q", name)
+	for _, cntr := range orderControllers(cntrs) {
+		err := m.prepareController(cntr)
+		if err != nil {
+			ctxutil.Cancel(ctx)
+			return nil, fmt.Errorf("cannot start controller %q for cluster %q: %s", cntr.GetName(), name, err)
+		}
+		ctxutil.SyncPointRunAndCancelOnExit(ctx, cntr.Run)
+	}
+
+	// let the controller manager wait for the controllers on shutdown
+	ctxutil.SyncPointRun(m.ctx, func() {
+		<-ctx.Done()
+		ctxutil.SyncPointWait(ctx, 120*time.Second)
+	})
+	return &dynamicCluster{ctx: ctx, cluster: c, hash: hash}, nil
+}
+
+func (this *clusterRegistry) stop(logger logger.LogContext, key string) {
+	instance := this.instances[key]
+	if instance == nil {
+		return
+	}
+	delete(this.instances, key)
+	logger.Infof("stopping controllers for cluster %q", instance.cluster.GetName())
+	ctxutil.Cancel(instance.ctx)
+	ctxutil.SyncPointWait(instance.ctx, 120*time.Second)
+	logger.Infof("controllers for cluster %q stopped", instance.cluster.GetName())
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// dynamicEnvironment is the environment for controllers started for
+// a dynamic cluster.
+type dynamicEnvironment struct {
+	*ControllerManager
+	ctx      context.Context
+	instance string
+	clusters cluster.Clusters
+}
+
+var _ controller.Environment = &dynamicEnvironment{}
+var _ controller.InstanceEnvironment = &dynamicEnvironment{}
+
+// GetInstanceName distinguishes the controllers started for different
+// secrets of the dynamic cluster.
+func (this *dynamicEnvironment) GetInstanceName() string {
+	return this.instance
+}
+
+func (this *dynamicEnvironment) GetContext() context.Context {
+	return this.ctx
+}
+
+func (this *dynamicEnvironment) GetClusters() cluster.Clusters {
+	return this.clusters
+}
+
+func (this *dynamicEnvironment) GetCluster(name string) cluster.Interface {
+	return this.clusters.GetCluster(name)
+}
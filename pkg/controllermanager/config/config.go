@@ -30,15 +30,29 @@ var GracePeriod time.Duration
 type Config struct {
 	lock                        sync.Mutex
 	LogLevel                    string
+	LogFormat                   string
+	LogLevelConfigMap           string
 	Controllers                 string
 	PluginDir                   string
 	Name                        string
 	Namespace                   string
 	OmitLease                   bool
+	LeaseResourceLock           string
+	LeaseIdentity               string
+	LeaseDuration               time.Duration
+	LeaseRenewDeadline          time.Duration
+	LeaseRetryPeriod            time.Duration
+	LeaseWarmStandby            bool
+	StartupTimeout              time.Duration
+	ShutdownGracePeriod         time.Duration
+	QueueStalenessLimit         time.Duration
 	DisableNamespaceRestriction bool
 	NamespaceRestriction        bool
 	ServerPortHTTP              int
 	CPUProfile                  string
+	DebugPort                   int
+	DebugBindAddress            string
+	DebugTokenFile              string
 	ArbitraryOptions            map[string]*ArbitraryOption
 }
 
@@ -106,11 +120,25 @@ func (this *Config) AddToCommand(cmd *cobra.Command) {
 	cmd.PersistentFlags().StringVarP(&this.Name, "name", "", "", "name used for controller manager")
 	cmd.PersistentFlags().StringVarP(&this.Namespace, "namespace", "", "", "namespace for lease")
 	cmd.PersistentFlags().BoolVarP(&this.OmitLease, "omit-lease", "", false, "omit lease for development")
+	cmd.PersistentFlags().StringVarP(&this.LeaseResourceLock, "lease-resource-lock", "", "configmaps", "resource used for leader election (configmaps, leases or endpoints)")
+	cmd.PersistentFlags().StringVarP(&this.LeaseIdentity, "lease-identity", "", "", "identity used for leader election (default <hostname>/<pid>)")
+	cmd.PersistentFlags().DurationVarP(&this.LeaseDuration, "lease-duration", "", 15*time.Second, "duration non-leaders wait before trying to acquire the lease")
+	cmd.PersistentFlags().DurationVarP(&this.LeaseRenewDeadline, "lease-renew-deadline", "", 10*time.Second, "duration the leader retries to renew the lease before giving up leadership")
+	cmd.PersistentFlags().DurationVarP(&this.LeaseRetryPeriod, "lease-retry-period", "", 2*time.Second, "duration between leader election attempts")
+	cmd.PersistentFlags().BoolVarP(&this.LeaseWarmStandby, "lease-warm-standby", "", false, "prepare controllers (reconciler setup and watches) before acquiring the lease")
+	cmd.PersistentFlags().DurationVarP(&this.StartupTimeout, "startup-timeout", "", 2*time.Minute, "maximum duration to wait for each startup dependency (controller or crd) of a controller")
+	cmd.PersistentFlags().DurationVarP(&this.ShutdownGracePeriod, "shutdown-grace-period", "", 120*time.Second, "maximum duration in-flight reconcilations may take on shutdown before they are cancelled")
+	cmd.PersistentFlags().DurationVarP(&this.QueueStalenessLimit, "queue-staleness-limit", "", 10*time.Minute, "maximum duration the oldest due item of a workqueue may wait before the controller is reported unhealthy (0 disables the check)")
 	cmd.PersistentFlags().StringVarP(&this.Controllers, "controllers", "c", "all", "comma separated list of controllers to start (<name>,source,target,all)")
 	cmd.PersistentFlags().StringVarP(&this.PluginDir, "plugin-dir", "", "", "directory containing go plugins")
 	cmd.PersistentFlags().IntVarP(&this.ServerPortHTTP, "server-port-http", "", 0, "HTTP server port (serving /healthz, /metrics, ...)")
 	cmd.PersistentFlags().StringVarP(&this.LogLevel, "log-level", "D", "", "logrus log level")
+	cmd.PersistentFlags().StringVarP(&this.LogFormat, "log-format", "", "text", "log format (text or json)")
+	cmd.PersistentFlags().StringVarP(&this.LogLevelConfigMap, "log-level-configmap", "", "", "config map (<namespace>/<name>) on the default cluster with the log levels of controllers")
 	cmd.PersistentFlags().StringVarP(&this.CPUProfile, "cpuprofile", "", "", "set file for cpu profiling")
+	cmd.PersistentFlags().IntVarP(&this.DebugPort, "debug-port", "", 0, "debug server port (serving pprof profiles, goroutine dumps and gc statistics, disabled if 0)")
+	cmd.PersistentFlags().StringVarP(&this.DebugBindAddress, "debug-bind-address", "", "127.0.0.1", "bind address of the debug server")
+	cmd.PersistentFlags().StringVarP(&this.DebugTokenFile, "debug-token-file", "", "", "file containing the bearer token required for requests to the debug server")
 	cmd.PersistentFlags().BoolVarP(&this.NamespaceRestriction, "namespace-local-access-only", "n", false, "enable access restriction for namespace local access only (deprecated)")
 	cmd.PersistentFlags().BoolVarP(&this.DisableNamespaceRestriction, "disable-namespace-restriction", "", false, "disable access restriction for namespace local access only")
 
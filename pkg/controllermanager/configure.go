@@ -29,6 +29,10 @@ type Configuration struct {
 	description    string
 	cluster_reg    cluster.Registry
 	controller_reg controller.Registry
+	leaseLost      []LeadershipLostHandler
+	preStart       []LifecycleHook
+	postStart      []LifecycleHook
+	preStop        []LifecycleHook
 }
 
 var _ cluster.RegistrationInterface = &Configuration{}
@@ -51,6 +55,37 @@ func (this Configuration) ByDefault() Configuration {
 	return this
 }
 
+// OnLeadershipLost registers a handler called when the controller manager
+// loses the leadership for a lease. Afterwards the controller manager
+// is shut down.
+func (this Configuration) OnLeadershipLost(h LeadershipLostHandler) Configuration {
+	this.leaseLost = append(append([]LeadershipLostHandler{}, this.leaseLost...), h)
+	return this
+}
+
+// PreStart registers a hook called after the clusters and controllers
+// have been created and before the first controller is started.
+// An error aborts the controller manager.
+func (this Configuration) PreStart(h LifecycleHook) Configuration {
+	this.preStart = append(append([]LifecycleHook{}, this.preStart...), h)
+	return this
+}
+
+// PostStart registers a hook called once all controllers are running,
+// which for controllers requiring a lease is after the leadership has
+// been acquired. An error shuts down the controller manager.
+func (this Configuration) PostStart(h LifecycleHook) Configuration {
+	this.postStart = append(append([]LifecycleHook{}, this.postStart...), h)
+	return this
+}
+
+// PreStop registers a hook called after the controllers have been
+// shut down and before the leases are released. Errors are only logged.
+func (this Configuration) PreStop(h LifecycleHook) Configuration {
+	this.preStop = append(append([]LifecycleHook{}, this.preStop...), h)
+	return this
+}
+
 func (this Configuration) RegisterCluster(reg cluster.Registerable) error {
 	return this.cluster_reg.RegisterCluster(reg)
 }
@@ -85,5 +120,9 @@ func (this Configuration) Definition() *Definition {
 		description:     this.description,
 		cluster_defs:    this.cluster_reg.GetDefinitions(),
 		controller_defs: this.controller_reg.GetDefinitions(),
+		leaseLost:       this.leaseLost,
+		preStart:        this.preStart,
+		postStart:       this.postStart,
+		preStop:         this.preStop,
 	}
 }
@@ -17,7 +17,9 @@
 package controller
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/gardener/controller-manager-library/pkg/controllermanager/cluster"
@@ -27,23 +29,120 @@ import (
 )
 
 type clusterResourceInfo struct {
-	pools []*pool
+	pools       []*pool
+	namespace   string
+	optionsFunc resources.TweakListOptionsFunc
+	// predicates of the watches per pool. An event is passed to a pool
+	// if it is accepted by all predicates of one of its watches.
+	predicates map[*pool][][]Predicate
+	// number of dynamic watches per pool, they accept all events
+	dynamicPools map[*pool]int
+	// stop stops the dedicated informer of a resource only watched
+	// dynamically, whose objects are found in cache
+	stop  context.CancelFunc
+	cache resources.ObjectCache
+}
+
+func (this *clusterResourceInfo) addPool(usedpool *pool, preds []Predicate) {
+	if this.predicates == nil {
+		this.predicates = map[*pool][][]Predicate{}
+	}
+	this.predicates[usedpool] = append(this.predicates[usedpool], preds)
+	this.usePool(usedpool)
+}
+
+func (this *clusterResourceInfo) usePool(usedpool *pool) {
+	for _, p := range this.pools {
+		if p == usedpool {
+			return
+		}
+	}
+	this.pools = append(this.pools, usedpool)
+}
+
+func (this *clusterResourceInfo) addDynamicPool(usedpool *pool) {
+	if this.dynamicPools == nil {
+		this.dynamicPools = map[*pool]int{}
+	}
+	this.dynamicPools[usedpool]++
+	this.usePool(usedpool)
+}
+
+// removeDynamicPool removes a dynamic watch of a pool. The pool is removed
+// if it is neither watched statically nor by other dynamic watches.
+func (this *clusterResourceInfo) removeDynamicPool(usedpool *pool) {
+	if this.dynamicPools[usedpool] > 1 {
+		this.dynamicPools[usedpool]--
+		return
+	}
+	delete(this.dynamicPools, usedpool)
+	if len(this.predicates[usedpool]) > 0 {
+		return
+	}
+	pools := []*pool{}
+	for _, p := range this.pools {
+		if p != usedpool {
+			pools = append(pools, p)
+		}
+	}
+	this.pools = pools
+}
+
+func (this *clusterResourceInfo) accepts(p *pool, f func(p Predicate) bool) bool {
+	watches := this.predicates[p]
+	if len(watches) == 0 || this.dynamicPools[p] > 0 {
+		return true
+	}
+	for _, preds := range watches {
+		if acceptedByAll(preds, f) {
+			return true
+		}
+	}
+	return false
 }
 
 type ClusterHandler struct {
 	logger.LogContext
+	lock       sync.RWMutex
 	controller *controller
 	cluster    cluster.Interface
 	resources  map[ResourceKey]*clusterResourceInfo
+	synced     bool
 }
 
 func newClusterHandler(controller *controller, cluster cluster.Interface) *ClusterHandler {
 	return &ClusterHandler{
-		controller.NewContext("cluster", cluster.GetName()),
-		controller,
-		cluster,
-		map[ResourceKey]*clusterResourceInfo{},
+		LogContext: controller.NewContext("cluster", cluster.GetName()),
+		controller: controller,
+		cluster:    cluster,
+		resources:  map[ResourceKey]*clusterResourceInfo{},
+	}
+}
+
+func (c *ClusterHandler) getResourceInfo(resourceKey ResourceKey) *clusterResourceInfo {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.resources[resourceKey]
+}
+
+// setSynced marks the caches of all watched resources as synced.
+// Registering a watch waits for the cache of the resource to be synced,
+// so this is done after all watches are registered.
+func (c *ClusterHandler) setSynced() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.synced = true
+}
+
+// CheckSynced reports an error as long as the caches of the watched
+// resources are not synced.
+func (c *ClusterHandler) CheckSynced() error {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	if !c.synced {
+		return fmt.Errorf("caches for %d resources not synced", len(c.resources))
 	}
+	return nil
 }
 
 func (c *ClusterHandler) whenReady() {
@@ -62,10 +161,14 @@ func (c *ClusterHandler) GetResource(resourceKey ResourceKey) (resources.Interfa
 	return c.cluster.GetResource(resourceKey.GroupKind())
 }
 
-func (c *ClusterHandler) register(resourceKey ResourceKey, namespace string, optionsFunc resources.TweakListOptionsFunc, usedpool *pool) error {
+func (c *ClusterHandler) register(resourceKey ResourceKey, namespace string, optionsFunc resources.TweakListOptionsFunc, usedpool *pool, preds []Predicate) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
 	i := c.resources[resourceKey]
 	if i == nil {
-		i = &clusterResourceInfo{[]*pool{usedpool}}
+		i = &clusterResourceInfo{namespace: namespace, optionsFunc: optionsFunc}
+		i.addPool(usedpool, preds)
 		c.resources[resourceKey] = i
 
 		resource, err := c.cluster.GetResource(resourceKey.GroupKind())
@@ -77,17 +180,75 @@ func (c *ClusterHandler) register(resourceKey ResourceKey, namespace string, opt
 			return err
 		}
 	} else {
-		for _, p := range i.pools {
-			if p == usedpool {
-				return nil
-			}
-		}
-		i.pools = append(i.pools, usedpool)
+		i.addPool(usedpool, preds)
 	}
 
 	return nil
 }
 
+// registerDynamic registers a watch of a pool for a resource at runtime.
+// If the resource is not watched yet, a dedicated informer is started,
+// which runs until the last dynamic watch is unregistered or the given
+// context is done.
+func (c *ClusterHandler) registerDynamic(ctx context.Context, resourceKey ResourceKey, usedpool *pool) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	i := c.resources[resourceKey]
+	if i == nil {
+		resource, err := c.cluster.GetResource(resourceKey.GroupKind())
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithCancel(ctx)
+		cache, err := resource.AddDedicatedEventHandler(ctx, c.GetEventHandlerFuncs(), "", nil)
+		if err != nil {
+			cancel()
+			return err
+		}
+		i = &clusterResourceInfo{stop: cancel, cache: cache}
+		c.resources[resourceKey] = i
+	}
+	i.addDynamicPool(usedpool)
+	return nil
+}
+
+// unregisterDynamic removes a dynamic watch of a pool. The dedicated
+// informer of a resource is stopped if it is not used anymore.
+func (c *ClusterHandler) unregisterDynamic(resourceKey ResourceKey, usedpool *pool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	i := c.resources[resourceKey]
+	if i == nil {
+		return
+	}
+	i.removeDynamicPool(usedpool)
+	if i.stop != nil && len(i.pools) == 0 {
+		i.stop()
+		delete(c.resources, resourceKey)
+	}
+}
+
+// GetCachedObject gets an object from the cache used for the watch of its
+// resource. For watches restricted by a selector only matching objects are
+// found. This avoids caching all objects of such a resource.
+func (c *ClusterHandler) GetCachedObject(key resources.ClusterObjectKey) (resources.Object, error) {
+	gk := key.GroupKind()
+	i := c.getResourceInfo(NewResourceKey(gk.Group, gk.Kind))
+	if i != nil && i.cache != nil {
+		return i.cache.GetCached(key.ObjectKey())
+	}
+	if i == nil || i.optionsFunc == nil {
+		return c.cluster.GetCachedObject(key)
+	}
+	resource, err := c.cluster.GetResource(gk)
+	if err != nil {
+		return nil, err
+	}
+	return resource.GetSelectedCached(i.namespace, i.optionsFunc, key.ObjectKey())
+}
+
 func (c *ClusterHandler) GetEventHandlerFuncs() resources.ResourceEventHandlerFuncs {
 	return resources.ResourceEventHandlerFuncs{
 		AddFunc:    c.objectAdd,
@@ -102,7 +263,7 @@ func (c *ClusterHandler) EnqueueKey(key resources.ClusterObjectKey) error {
 	//c.Infof("enqueue %s", obj.Description())
 	gk := key.GroupKind()
 	rk := NewResourceKey(gk.Group, gk.Kind)
-	i := c.resources[rk]
+	i := c.getResourceInfo(rk)
 	if i == nil {
 		c.Warnf("no resource info for type %s", rk)
 		return fmt.Errorf("cluster %q: no resource info for %s", c, rk)
@@ -120,8 +281,8 @@ func (c *ClusterHandler) EnqueueKey(key resources.ClusterObjectKey) error {
 func (c *ClusterHandler) enqueue(obj resources.Object, e func(p *pool, r resources.Object)) error {
 	c.whenReady()
 	//c.Infof("enqueue %s", obj.Description())
-	i := c.resources[GetResourceKey(obj)]
-	if i.pools == nil || len(i.pools) == 0 {
+	i := c.getResourceInfo(GetResourceKey(obj))
+	if i == nil || i.pools == nil || len(i.pools) == 0 {
 		c.Warnf("no worker pool for type %s", obj.GroupKind())
 		return fmt.Errorf("no worker pool for type %s", obj.GroupKind())
 	}
@@ -156,11 +317,34 @@ func (c *ClusterHandler) EnqueueObjectAfter(obj resources.Object, duration time.
 
 ///////////////////////////////////////////////////////////////////////////////
 
+// enqueueEvent enqueues an object for an event into all pools whose
+// watch predicates accept the event.
+func (c *ClusterHandler) enqueueEvent(obj resources.Object, f func(p Predicate) bool) error {
+	c.whenReady()
+	i := c.getResourceInfo(GetResourceKey(obj))
+	if i == nil || i.pools == nil || len(i.pools) == 0 {
+		c.Warnf("no worker pool for type %s", obj.GroupKind())
+		return fmt.Errorf("no worker pool for type %s", obj.GroupKind())
+	}
+	c.lock.RLock()
+	pools := []*pool{}
+	for _, p := range i.pools {
+		if i.accepts(p, f) {
+			pools = append(pools, p)
+		}
+	}
+	c.lock.RUnlock()
+	for _, p := range pools {
+		p.EnqueueObject(obj)
+	}
+	return nil
+}
+
 func (c *ClusterHandler) objectAdd(obj resources.Object) {
 	c.Debugf("** GOT add event for %s", obj.Description())
 
 	if c.controller.mustHandle(obj) {
-		c.EnqueueObject(obj)
+		c.enqueueEvent(obj, func(p Predicate) bool { return p.Add(obj) })
 	}
 }
 
@@ -170,13 +354,13 @@ func (c *ClusterHandler) objectUpdate(old, new resources.Object) {
 		return
 	}
 
-	c.EnqueueObject(new)
+	c.enqueueEvent(new, func(p Predicate) bool { return p.Update(old, new) })
 }
 
 func (c *ClusterHandler) objectDelete(obj resources.Object) {
 	c.Debugf("** GOT delete event for %s: %s", obj.Description(), obj.GetResourceVersion())
 
 	if c.controller.mustHandle(obj) {
-		c.EnqueueObject(obj)
+		c.enqueueEvent(obj, func(p Predicate) bool { return p.Delete(obj) })
 	}
 }
@@ -26,6 +26,11 @@ import (
 func ControllerOption(controller, name string) string {
 	return fmt.Sprintf("%s.%s", controller, name)
 }
+
+func ControllerPoolSizeOptionName(controller string) string {
+	return ControllerOption(controller, CONTROLLER_POOL_SIZE_OPTION)
+}
+
 func PoolSizeOptionName(controller, pool string) string {
 	return fmt.Sprintf("%s.%s.%s", controller, pool, POOL_SIZE_OPTION)
 }
@@ -35,7 +40,11 @@ func PoolResyncPeriodOptionName(controller, pool string) string {
 }
 
 const POOL_SIZE_OPTION = "pool.size"
+const CONTROLLER_POOL_SIZE_OPTION = "pool-size"
 const POOL_RESYNC_PERIOD_OPTION = "pool.resync-period"
+const RECONCILE_TIMEOUT_OPTION = "reconcile-timeout"
+const DEADLETTER_THRESHOLD_OPTION = "deadletter.threshold"
+const DEADLETTER_RETRY_PERIOD_OPTION = "deadletter.retry-period"
 
 func (this *_Definitions) ExtendConfig(cfg *config.Config) {
 	shared := map[string]reflect.Type{}
@@ -50,6 +59,42 @@ func (this *_Definitions) ExtendConfig(cfg *config.Config) {
 	}
 
 	for name, def := range this.definitions {
+		opt, _ := cfg.AddIntOption(ControllerPoolSizeOptionName(name))
+		opt.Description = fmt.Sprintf("Worker pool size for all pools of controller %s (default: %d)", name, def.PoolSize())
+		opt.Default = def.PoolSize()
+
+		opt, _ = cfg.AddDurationOption(ControllerOption(name, RECONCILE_TIMEOUT_OPTION))
+		opt.Description = fmt.Sprintf("Deadline for a single reconcilation of controller %s, 0 disables (default: %s)", name, def.ReconcileTimeout())
+		opt.Default = def.ReconcileTimeout()
+		updateSharedOption(RECONCILE_TIMEOUT_OPTION, opt)
+
+		opt, _ = cfg.AddIntOption(ControllerOption(name, DEADLETTER_THRESHOLD_OPTION))
+		opt.Description = fmt.Sprintf("Number of consecutive failures of an item of controller %s before it is moved to the dead letters, 0 disables (default: %d)", name, def.DeadLetterThreshold())
+		opt.Default = def.DeadLetterThreshold()
+		updateSharedOption(DEADLETTER_THRESHOLD_OPTION, opt)
+		opt, _ = cfg.AddDurationOption(ControllerOption(name, DEADLETTER_RETRY_PERIOD_OPTION))
+		opt.Description = fmt.Sprintf("Retry period for dead letters of controller %s (default: %s)", name, def.DeadLetterRetryPeriod())
+		opt.Default = def.DeadLetterRetryPeriod()
+		updateSharedOption(DEADLETTER_RETRY_PERIOD_OPTION, opt)
+
+		rl := def.RateLimiter()
+		opt, _ = cfg.AddDurationOption(RateLimiterOptionName(name, RATELIMIT_BASE_DELAY_OPTION))
+		opt.Description = fmt.Sprintf("Base delay of per-item requeue backoff of controller %s, 0 disables (default: %s)", name, rl.BaseDelay())
+		opt.Default = rl.BaseDelay()
+		updateSharedOption(RATELIMIT_BASE_DELAY_OPTION, opt)
+		opt, _ = cfg.AddDurationOption(RateLimiterOptionName(name, RATELIMIT_MAX_DELAY_OPTION))
+		opt.Description = fmt.Sprintf("Maximum delay of per-item requeue backoff of controller %s (default: %s)", name, rl.MaxDelay())
+		opt.Default = rl.MaxDelay()
+		updateSharedOption(RATELIMIT_MAX_DELAY_OPTION, opt)
+		opt, _ = cfg.AddIntOption(RateLimiterOptionName(name, RATELIMIT_QPS_OPTION))
+		opt.Description = fmt.Sprintf("Overall requeue rate limit of controller %s, 0 disables (default: %d)", name, rl.QPS())
+		opt.Default = rl.QPS()
+		updateSharedOption(RATELIMIT_QPS_OPTION, opt)
+		opt, _ = cfg.AddIntOption(RateLimiterOptionName(name, RATELIMIT_BURST_OPTION))
+		opt.Description = fmt.Sprintf("Burst for overall requeue rate limit of controller %s (default: %d)", name, rl.Burst())
+		opt.Default = rl.Burst()
+		updateSharedOption(RATELIMIT_BURST_OPTION, opt)
+
 		for pname, p := range def.Pools() {
 			opt, _ := cfg.AddIntOption(PoolSizeOptionName(name, pname))
 			opt.Description = fmt.Sprintf("Worker pool size for pool %s of controller %s (default: %d)", pname, name, p.Size())
@@ -65,6 +110,12 @@ func (this *_Definitions) ExtendConfig(cfg *config.Config) {
 			}
 		}
 
+		for _, t := range def.Triggers() {
+			opt, _ := cfg.AddStringOption(TriggerOptionName(name, t.GetName()))
+			opt.Description = fmt.Sprintf("Schedule of trigger %s of controller %s, empty disables (default: %s)", t.GetName(), name, t.Schedule())
+			opt.Default = t.Schedule().String()
+		}
+
 		for oname, o := range def.ConfigOptions() {
 			opt, _ := cfg.AddOption(ControllerOption(name, oname), o.Type())
 			opt.Description = o.Description()
@@ -20,10 +20,14 @@ import (
 	"fmt"
 	"github.com/gardener/controller-manager-library/pkg/controllermanager/cluster"
 	"github.com/gardener/controller-manager-library/pkg/resources"
+	"github.com/gardener/controller-manager-library/pkg/resources/apiextensions"
 	"reflect"
 	"time"
 
 	apiext "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/gardener/controller-manager-library/pkg/utils"
 )
@@ -34,6 +38,40 @@ func NamespaceSelection(namespace string) WatchSelectionFunction {
 	}
 }
 
+func LabelSelection(selector string) WatchSelectionFunction {
+	return Selection("", selector, "")
+}
+
+func FieldSelection(selector string) WatchSelectionFunction {
+	return Selection("", "", selector)
+}
+
+// Selection restricts a watch to a namespace and to the objects matching
+// the given label and field selectors. Empty values don't restrict the watch.
+// The informer used for the watch only caches the matching objects.
+func Selection(namespace, labelSelector, fieldSelector string) WatchSelectionFunction {
+	if _, err := labels.Parse(labelSelector); err != nil {
+		panic(fmt.Sprintf("invalid label selector %q: %s", labelSelector, err))
+	}
+	if _, err := fields.ParseSelector(fieldSelector); err != nil {
+		panic(fmt.Sprintf("invalid field selector %q: %s", fieldSelector, err))
+	}
+	var tweak resources.TweakListOptionsFunc
+	if labelSelector != "" || fieldSelector != "" {
+		tweak = func(opts *metav1.ListOptions) {
+			if labelSelector != "" {
+				opts.LabelSelector = labelSelector
+			}
+			if fieldSelector != "" {
+				opts.FieldSelector = fieldSelector
+			}
+		}
+	}
+	return func(c Interface) (string, resources.TweakListOptionsFunc) {
+		return namespace, tweak
+	}
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 
 type configdef struct {
@@ -90,6 +128,7 @@ type watchdef struct {
 type rescdef struct {
 	rtype      ResourceKey
 	selectFunc WatchSelectionFunction
+	predicates []Predicate
 }
 
 func (this *rescdef) ResourceType() ResourceKey {
@@ -98,6 +137,9 @@ func (this *rescdef) ResourceType() ResourceKey {
 func (this *rescdef) WatchSelectionFunction() WatchSelectionFunction {
 	return this.selectFunc
 }
+func (this *rescdef) Predicates() []Predicate {
+	return this.predicates
+}
 
 func (this *watchdef) Reconciler() string {
 	return this.reconciler
@@ -124,22 +166,85 @@ func (this *cmddef) PoolName() string {
 	return this.pool
 }
 
+type indexdef struct {
+	name  string
+	rtype ResourceKey
+	index resources.IndexFunc
+}
+
+func (this *indexdef) GetName() string {
+	return this.name
+}
+func (this *indexdef) ResourceType() ResourceKey {
+	return this.rtype
+}
+func (this *indexdef) IndexFunc() resources.IndexFunc {
+	return this.index
+}
+
+type transformdef struct {
+	rtype     ResourceKey
+	transform resources.TransformFunc
+}
+
+func (this *transformdef) ResourceType() ResourceKey {
+	return this.rtype
+}
+func (this *transformdef) TransformFunc() resources.TransformFunc {
+	return this.transform
+}
+
+type triggerdef struct {
+	name     string
+	schedule Schedule
+	cmds     []string
+	pool     string
+}
+
+func (this *triggerdef) GetName() string {
+	return this.name
+}
+func (this *triggerdef) Schedule() Schedule {
+	return this.schedule
+}
+func (this *triggerdef) Commands() []string {
+	return this.cmds
+}
+func (this *triggerdef) PoolName() string {
+	return this.pool
+}
+
 type _Definition struct {
 	name                 string
 	main                 rescdef
 	reconcilers          map[string]ReconcilerType
 	watches              Watches
 	commands             Commands
+	indexes              Indexes
+	transforms           Transforms
 	resource_filters     []ResourceFilter
 	required_clusters    []string
 	required_controllers []string
+	after                []string
+	required_crds        map[string][]string
 	require_lease        bool
 	pools                map[string]PoolDefinition
+	poolSize             int
+	reconcileTimeout     time.Duration
+	rateLimiter          *ratelimiterdef
+	deadLetterThreshold  int
+	deadLetterRetry      time.Duration
 	configs              map[string]OptionDefinition
 	finalizerName        string
 	finalizerDomain      string
 	crds                 map[string][]*CustomResourceDefinition
 	activateExplicitly   bool
+	storageMigration     bool
+	triggers             []Trigger
+	middlewares          []Middleware
+	preStart             []LifecycleHook
+	postStart            []LifecycleHook
+	preStop              []LifecycleHook
 }
 
 var _ Definition = &_Definition{}
@@ -149,10 +254,16 @@ func (this *_Definition) String() string {
 	s += fmt.Sprintf("  main rsc:    %s\n", this.main)
 	s += fmt.Sprintf("  clusters:    %s\n", utils.Strings(this.RequiredClusters()...))
 	s += fmt.Sprintf("  required:    %s\n", utils.Strings(this.RequiredControllers()...))
+	s += fmt.Sprintf("  after:       %s\n", utils.Strings(this.After()...))
+	s += fmt.Sprintf("  crds:        %s\n", toString(this.required_crds))
 	s += fmt.Sprintf("  reconcilers: %s\n", toString(this.reconcilers))
 	s += fmt.Sprintf("  watches:     %s\n", toString(this.watches))
 	s += fmt.Sprintf("  commands:    %s\n", toString(this.commands))
 	s += fmt.Sprintf("  pools:       %s\n", toString(this.pools))
+	s += fmt.Sprintf("  ratelimit:   %s\n", this.RateLimiter())
+	if this.deadLetterThreshold > 0 {
+		s += fmt.Sprintf("  deadletter:  after %d failures, retry period %s\n", this.deadLetterThreshold, this.DeadLetterRetryPeriod())
+	}
 	s += fmt.Sprintf("  finalizer:   %s\n", this.FinalizerName())
 	return s
 }
@@ -172,6 +283,12 @@ func (this *_Definition) Watches() Watches {
 func (this *_Definition) Commands() Commands {
 	return this.commands
 }
+func (this *_Definition) Indexes() Indexes {
+	return this.indexes
+}
+func (this *_Definition) Transforms() Transforms {
+	return this.transforms
+}
 func (this *_Definition) ResourceFilters() []ResourceFilter {
 	return this.resource_filters
 }
@@ -184,6 +301,20 @@ func (this *_Definition) RequiredClusters() []string {
 func (this *_Definition) RequiredControllers() []string {
 	return this.required_controllers
 }
+// After returns the names of the controllers that must be started
+// before this controller.
+func (this *_Definition) After() []string {
+	return this.after
+}
+// RequiredCRDs returns the names of the CRDs per cluster that must be
+// established before this controller is started.
+func (this *_Definition) RequiredCRDs() map[string][]string {
+	crds := map[string][]string{}
+	for n, l := range this.required_crds {
+		crds[n] = append([]string{}, l...)
+	}
+	return crds
+}
 func (this *_Definition) RequireLease() bool {
 	return this.require_lease
 }
@@ -215,13 +346,49 @@ func (this *_Definition) Reconcilers() map[string]ReconcilerType {
 func (this *_Definition) Pools() map[string]PoolDefinition {
 	pools := map[string]PoolDefinition{}
 	for n, d := range this.pools {
+		if d.Size() <= 0 {
+			d = &pooldef{n, this.PoolSize(), d.Period()}
+		}
 		pools[n] = d
 	}
 	if len(pools) == 0 {
-		pools[DEFAULT_POOL] = &pooldef{DEFAULT_POOL, 5, 30 * time.Second}
+		pools[DEFAULT_POOL] = &pooldef{DEFAULT_POOL, this.PoolSize(), 30 * time.Second}
 	}
 	return pools
 }
+
+// PoolSize is the worker pool size used for pools without explicit size.
+func (this *_Definition) PoolSize() int {
+	if this.poolSize > 0 {
+		return this.poolSize
+	}
+	return DEFAULT_POOL_SIZE
+}
+// ReconcileTimeout is the deadline for a single reconciler call,
+// 0 means no deadline.
+func (this *_Definition) ReconcileTimeout() time.Duration {
+	return this.reconcileTimeout
+}
+// DeadLetterThreshold is the number of consecutive failures of an item
+// after which it is moved to the dead letters, 0 disables dead letters.
+func (this *_Definition) DeadLetterThreshold() int {
+	return this.deadLetterThreshold
+}
+
+// DeadLetterRetryPeriod is the period dead letters are retried with.
+func (this *_Definition) DeadLetterRetryPeriod() time.Duration {
+	if this.deadLetterRetry > 0 {
+		return this.deadLetterRetry
+	}
+	return DEFAULT_DEADLETTER_RETRY_PERIOD
+}
+
+func (this *_Definition) RateLimiter() RateLimiterDefinition {
+	if this.rateLimiter == nil {
+		return defaultRateLimiter
+	}
+	return this.rateLimiter
+}
 func (this *_Definition) ConfigOptions() map[string]OptionDefinition {
 	cfgs := map[string]OptionDefinition{}
 	for n, d := range this.configs {
@@ -234,6 +401,32 @@ func (this *_Definition) ActivateExplicitly() bool {
 	return this.activateExplicitly
 }
 
+// StorageVersionMigration reports whether the objects of the deployed
+// CRDs are migrated to their actual storage version.
+func (this *_Definition) StorageVersionMigration() bool {
+	return this.storageMigration
+}
+
+func (this *_Definition) Triggers() []Trigger {
+	return this.triggers
+}
+
+func (this *_Definition) Middlewares() []Middleware {
+	return this.middlewares
+}
+
+func (this *_Definition) PreStartHooks() []LifecycleHook {
+	return this.preStart
+}
+
+func (this *_Definition) PostStartHooks() []LifecycleHook {
+	return this.postStart
+}
+
+func (this *_Definition) PreStopHooks() []LifecycleHook {
+	return this.preStop
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 
 type Configuration struct {
@@ -273,6 +466,35 @@ names:
 	return this
 }
 
+// After declares controllers that must be started before this one.
+// Controllers not activated in the controller manager are ignored.
+func (this Configuration) After(names ...string) Configuration {
+	after := append([]string{}, this.settings.after...)
+names:
+	for _, n := range names {
+		for _, o := range after {
+			if n == o {
+				continue names
+			}
+		}
+		after = append(after, n)
+	}
+	this.settings.after = after
+	return this
+}
+
+// RequireCRDs declares CRDs of the actual cluster that must be
+// established before the controller is started.
+func (this Configuration) RequireCRDs(names ...string) Configuration {
+	m := map[string][]string{}
+	for k, v := range this.settings.required_crds {
+		m[k] = v
+	}
+	m[this.cluster] = append(append([]string{}, m[this.cluster]...), names...)
+	this.settings.required_crds = m
+	return this
+}
+
 func (this Configuration) MainResource(group, kind string, sel ...WatchSelectionFunction) Configuration {
 	return this.MainResourceByKey(NewResourceKey(group, kind), sel...)
 }
@@ -285,6 +507,13 @@ func (this Configuration) MainResourceByKey(key ResourceKey, sel ...WatchSelecti
 	return this
 }
 
+// MainResourcePredicates sets predicates for the events of the main
+// resource. Only events accepted by all predicates trigger a reconcilation.
+func (this Configuration) MainResourcePredicates(preds ...Predicate) Configuration {
+	this.settings.main.predicates = append([]Predicate{}, preds...)
+	return this
+}
+
 func (this Configuration) DefaultWorkerPool(size int, period time.Duration) Configuration {
 	return this.WorkerPool(DEFAULT_POOL, size, period)
 }
@@ -299,6 +528,50 @@ func (this Configuration) WorkerPool(name string, size int, period time.Duration
 	return this
 }
 
+// PoolSize sets the worker pool size for all pools of the controller
+// defined without explicit size (size 0).
+func (this Configuration) PoolSize(size int) Configuration {
+	this.settings.poolSize = size
+	return this
+}
+
+// ReconcileTimeout sets the deadline for a single reconciler call.
+// The context provided by reconcile.Context is cancelled if the deadline
+// is exceeded and the item is requeued rate limited. The reconciler call
+// itself is not interrupted: the worker is only released when the
+// reconciler returns, so reconcilers must honor the context.
+func (this Configuration) ReconcileTimeout(d time.Duration) Configuration {
+	this.settings.reconcileTimeout = d
+	return this
+}
+
+// RateLimiter configures the requeue rate limiter used for the pools of the
+// controller. A baseDelay of 0 disables the per-item exponential backoff,
+// a qps of 0 disables the overall token bucket.
+func (this Configuration) RateLimiter(baseDelay, maxDelay time.Duration, qps, burst int) Configuration {
+	this.settings.rateLimiter = &ratelimiterdef{baseDelay: baseDelay, maxDelay: maxDelay, qps: qps, burst: burst}
+	return this
+}
+
+// CustomRateLimiter configures a factory for the requeue rate limiter used
+// for the pools of the controller. It is overridden by explicitly set rate
+// limiter options.
+func (this Configuration) CustomRateLimiter(factory RateLimiterFactory) Configuration {
+	def := *defaultRateLimiter
+	def.factory = factory
+	this.settings.rateLimiter = &def
+	return this
+}
+
+// DeadLetter moves items failing the given number of consecutive times to
+// the dead letters of their pool. Dead letters are only retried with the
+// given period (0 uses the default of 30 minutes) or on request.
+func (this Configuration) DeadLetter(threshold int, retry time.Duration) Configuration {
+	this.settings.deadLetterThreshold = threshold
+	this.settings.deadLetterRetry = retry
+	return this
+}
+
 func (this Configuration) Pool(name string) Configuration {
 	this.pool = name
 	return this
@@ -341,6 +614,20 @@ func (this Configuration) CustomResourceDefinitions(crds ...*apiext.CustomResour
 	return this
 }
 
+// CustomResourceDefinitionManifests adds CRDs given as YAML or JSON
+// manifests to the CRDs deployed for the actual cluster.
+func (this Configuration) CustomResourceDefinitionManifests(manifests ...[]byte) Configuration {
+	crds := []*apiext.CustomResourceDefinition{}
+	for _, m := range manifests {
+		crd, err := apiextensions.CreateCRDObjectFromManifest(m)
+		if err != nil {
+			panic(fmt.Sprintf("controller %q: %s", this.settings.name, err))
+		}
+		crds = append(crds, crd)
+	}
+	return this.CustomResourceDefinitions(crds...)
+}
+
 func (this Configuration) VersionedCustomResourceDefinitions(crds ...*CustomResourceDefinition) Configuration {
 	m := map[string][]*CustomResourceDefinition{}
 	for k, v := range this.settings.crds {
@@ -385,7 +672,7 @@ func (this Configuration) ReconcilerWatches(reconciler string, keys ...ResourceK
 	this.assureWatches()
 	for _, key := range keys {
 		//logger.Infof("adding watch for %q:%q to pool %q", this.cluster, key, this.pool)
-		this.settings.watches[this.cluster] = append(this.settings.watches[this.cluster], &watchdef{rescdef{key, nil}, reconciler, this.pool})
+		this.settings.watches[this.cluster] = append(this.settings.watches[this.cluster], &watchdef{rescdef{rtype: key}, reconciler, this.pool})
 	}
 	return this
 }
@@ -394,8 +681,65 @@ func (this Configuration) ReconcilerSelectedWatches(reconciler string, sel Watch
 	this.assureWatches()
 	for _, key := range keys {
 		//logger.Infof("adding watch for %q:%q to pool %q", this.cluster, key, this.pool)
-		this.settings.watches[this.cluster] = append(this.settings.watches[this.cluster], &watchdef{rescdef{key, sel}, reconciler, this.pool})
+		this.settings.watches[this.cluster] = append(this.settings.watches[this.cluster], &watchdef{rescdef{rtype: key, selectFunc: sel}, reconciler, this.pool})
+	}
+	return this
+}
+
+// FilteredWatches adds watches for the default reconciler, whose events
+// only trigger a reconcilation if accepted by all given predicates.
+func (this Configuration) FilteredWatches(preds []Predicate, keys ...ResourceKey) Configuration {
+	return this.ReconcilerFilteredWatches(DEFAULT_RECONCILER, preds, keys...)
+}
+
+func (this Configuration) FilteredWatch(preds []Predicate, group, kind string) Configuration {
+	return this.ReconcilerFilteredWatches(DEFAULT_RECONCILER, preds, NewResourceKey(group, kind))
+}
+
+func (this Configuration) ReconcilerFilteredWatches(reconciler string, preds []Predicate, keys ...ResourceKey) Configuration {
+	this.assureWatches()
+	for _, key := range keys {
+		this.settings.watches[this.cluster] = append(this.settings.watches[this.cluster], &watchdef{rescdef{rtype: key, predicates: append([]Predicate{}, preds...)}, reconciler, this.pool})
+	}
+	return this
+}
+
+// Index registers an index for the cached objects of a resource of the
+// actual cluster. The index can be queried with ListCachedByIndex.
+func (this Configuration) Index(key ResourceKey, name string, f resources.IndexFunc) Configuration {
+	indexes := Indexes{}
+	for n, l := range this.settings.indexes {
+		indexes[n] = l
 	}
+	for _, i := range indexes[this.cluster] {
+		if i.GetName() == name && i.ResourceType() == key {
+			panic(fmt.Sprintf("index %q for %s of controller %q already defined", name, key, this.settings.name))
+		}
+	}
+	indexes[this.cluster] = append(append([]Index{}, indexes[this.cluster]...), &indexdef{name, key, f})
+	this.settings.indexes = indexes
+	return this
+}
+
+// FieldIndex registers an index named by a field path (see resources.FieldIndex).
+func (this Configuration) FieldIndex(key ResourceKey, path string) Configuration {
+	return this.Index(key, path, resources.FieldIndex(path))
+}
+
+// Transform registers transforms for the objects of a resource of the
+// actual cluster before they enter the cache, for example
+// resources.StripManagedFields to reduce the memory footprint.
+func (this Configuration) Transform(key ResourceKey, f ...resources.TransformFunc) Configuration {
+	transforms := Transforms{}
+	for n, l := range this.settings.transforms {
+		transforms[n] = l
+	}
+	l := append([]Transform{}, transforms[this.cluster]...)
+	for _, t := range f {
+		l = append(l, &transformdef{key, t})
+	}
+	transforms[this.cluster] = l
+	this.settings.transforms = transforms
 	return this
 }
 
@@ -404,6 +748,45 @@ func (this Configuration) ActivateExplicitly() Configuration {
 	return this
 }
 
+// StorageVersionMigration enables the migration of all objects of the
+// CRDs deployed by the controller to their actual storage version. The
+// migration is done in the background when the controller is started.
+func (this Configuration) StorageVersionMigration() Configuration {
+	this.settings.storageMigration = true
+	return this
+}
+
+// Middleware adds middlewares wrapping all reconcilers of the controller.
+// The first middleware is the outermost one.
+func (this Configuration) Middleware(m ...Middleware) Configuration {
+	this.settings.middlewares = append(append([]Middleware{}, this.settings.middlewares...), m...)
+	return this
+}
+
+// PreStart registers a hook called after the caches of the controller
+// are synced and before it is started. With a warm standby this happens
+// before the lease is acquired. An error aborts the controller manager.
+func (this Configuration) PreStart(h LifecycleHook) Configuration {
+	this.settings.preStart = append(append([]LifecycleHook{}, this.settings.preStart...), h)
+	return this
+}
+
+// PostStart registers a hook called after the controller has been started,
+// which for controllers requiring a lease is after the leadership has been
+// acquired. An error shuts down the controller manager.
+func (this Configuration) PostStart(h LifecycleHook) Configuration {
+	this.settings.postStart = append(append([]LifecycleHook{}, this.settings.postStart...), h)
+	return this
+}
+
+// PreStop registers a hook called after the worker pools of the controller
+// have been shut down and before its lease is released.
+// Errors are only logged.
+func (this Configuration) PreStop(h LifecycleHook) Configuration {
+	this.settings.preStop = append(append([]LifecycleHook{}, this.settings.preStop...), h)
+	return this
+}
+
 func (this *Configuration) assureCommands() {
 	if this.settings.commands == nil {
 		this.settings.commands = map[string][]Command{}
@@ -425,6 +808,33 @@ func (this Configuration) ReconcilerCommands(reconciler string, cmd ...string) C
 	}
 	return this
 }
+// Trigger registers a trigger for the actual pool enqueuing the given
+// commands (or the name of the trigger if no command is given) according
+// to a schedule (see ParseSchedule). The commands are handled by the
+// default reconciler. The schedule can be overwritten by the option
+// <controller>.trigger.<name>.
+func (this Configuration) Trigger(name, schedule string, cmd ...string) Configuration {
+	return this.ReconcilerTrigger(DEFAULT_RECONCILER, name, schedule, cmd...)
+}
+
+func (this Configuration) ReconcilerTrigger(reconciler, name, schedule string, cmd ...string) Configuration {
+	s, err := ParseSchedule(schedule)
+	if err != nil {
+		panic(fmt.Sprintf("trigger %q for controller %q: %s", name, this.settings.name, err))
+	}
+	for _, t := range this.settings.triggers {
+		if t.GetName() == name {
+			panic(fmt.Sprintf("trigger %q for controller %q already defined", name, this.settings.name))
+		}
+	}
+	if len(cmd) == 0 {
+		cmd = []string{name}
+	}
+	this = this.ReconcilerCommands(reconciler, cmd...)
+	this.settings.triggers = append(append([]Trigger{}, this.settings.triggers...), &triggerdef{name, s, cmd, this.pool})
+	return this
+}
+
 func (this Configuration) ReconcilerCommandMatchers(reconciler string, cmd ...utils.Matcher) Configuration {
 	this.assureCommands()
 	for _, cmd := range cmd {
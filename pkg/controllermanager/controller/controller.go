@@ -31,6 +31,7 @@ import (
 	"github.com/gardener/controller-manager-library/pkg/logger"
 	"github.com/gardener/controller-manager-library/pkg/resources"
 	"github.com/gardener/controller-manager-library/pkg/resources/apiextensions"
+	"github.com/gardener/controller-manager-library/pkg/server/readyz"
 	"github.com/gardener/controller-manager-library/pkg/utils"
 	"k8s.io/client-go/tools/record"
 )
@@ -61,6 +62,14 @@ type Environment interface {
 	//GetSharedOption(name string) *config.ArbitraryOption
 }
 
+// InstanceEnvironment may be implemented by environments running an
+// instance of a controller definition besides other instances, for example
+// one per dynamic cluster. The instance name is used to distinguish the
+// keys and metric labels of the instances.
+type InstanceEnvironment interface {
+	GetInstanceName() string
+}
+
 type _ReconcilerMapping struct {
 	key        interface{}
 	cluster    string
@@ -96,6 +105,7 @@ type controller struct {
 
 	ready       ReadyFlag
 	definition  Definition
+	instance    string
 	env         Environment
 	ctx         context.Context
 	cluster     cluster.Interface
@@ -106,9 +116,15 @@ type controller struct {
 	mappings    map[_ReconcilerMapping]string
 	finalizer   Finalizer
 
+	hlock    sync.Mutex
 	handlers map[string]*ClusterHandler
 
+	dynlock sync.Mutex
+	dynamic map[dynamicWatchKey]*dynamicWatch
+
 	pools map[string]*pool
+
+	events *eventRecorder
 }
 
 func Filter(owning ResourceKey, resc resources.Object) bool {
@@ -128,6 +144,7 @@ func NewController(env Environment, def Definition, cmp mappings.Definition) (*c
 		EventRecorder: cluster.Resources(),
 
 		definition: def,
+		instance:   def.GetName(),
 		env:        env,
 		cluster:    cluster,
 		clusters:   clusters,
@@ -136,18 +153,26 @@ func NewController(env Environment, def Definition, cmp mappings.Definition) (*c
 		filters: def.ResourceFilters(),
 
 		handlers:    map[string]*ClusterHandler{},
+		dynamic:     map[dynamicWatchKey]*dynamicWatch{},
 		pools:       map[string]*pool{},
 		reconcilers: map[string]reconcile.Interface{},
 		mappings:    map[_ReconcilerMapping]string{},
 		finalizer:   NewDefaultFinalizer(def.FinalizerName()),
 	}
+	if i, ok := env.(InstanceEnvironment); ok {
+		this.instance = fmt.Sprintf("%s[%s]", def.GetName(), i.GetInstanceName())
+	}
+	this.events = newEventRecorder(this)
 
 	this.ready.start()
 
-	this.ctx, this.LogContext = logger.WithLogger(
+	ctx, lgr := logger.WithLogger(
 		ctxutil.SyncContext(
 			context.WithValue(env.GetContext(), typekey, this)),
-		"this", def.GetName())
+		"controller", def.GetName())
+	// the log level of the controller can be changed at runtime
+	this.LogContext = logger.WithDomain(lgr, def.GetName())
+	this.ctx = logger.Set(ctx, this.LogContext)
 	this.Infof("  using clusters %+v: %s (selected from %s)", required, clusters, env.GetClusters())
 
 	for n, crds := range def.CustomResourceDefinitions() {
@@ -164,19 +189,58 @@ func NewController(env Environment, def Definition, cmp mappings.Definition) (*c
 			crd := v.GetFor(cluster)
 			if crd != nil {
 				this.Infof("   %s", crd.Name)
-				err := apiextensions.CreateCRDFromObject(cluster, crd)
+				err := apiextensions.CreateOrUpdateCRDFromObject(cluster, crd, crdTimeout(env))
 				if err != nil {
 					return nil, fmt.Errorf("creating CRD for %s failed: %s", crd.Name, err)
 				}
 			}
 		}
 	}
+	for n, indexes := range def.Indexes() {
+		cluster := clusters.GetCluster(n)
+		if cluster == nil {
+			return nil, fmt.Errorf("cluster %q not found for indexes", n)
+		}
+		for _, i := range indexes {
+			res, err := cluster.GetResource(i.ResourceType().GroupKind())
+			if err != nil {
+				return nil, err
+			}
+			if err := res.AddIndexer(i.GetName(), i.IndexFunc()); err != nil {
+				return nil, fmt.Errorf("index %q for %s: %s", i.GetName(), i.ResourceType(), err)
+			}
+		}
+	}
+	for n, transforms := range def.Transforms() {
+		cluster := clusters.GetCluster(n)
+		if cluster == nil {
+			return nil, fmt.Errorf("cluster %q not found for transforms", n)
+		}
+		for _, t := range transforms {
+			res, err := cluster.GetResource(t.ResourceType().GroupKind())
+			if err != nil {
+				return nil, err
+			}
+			if err := res.AddTransform(t.TransformFunc()); err != nil {
+				return nil, fmt.Errorf("transform for %s: %s", t.ResourceType(), err)
+			}
+		}
+	}
 	for n, t := range def.Reconcilers() {
 		this.Infof("creating reconciler %q", n)
 		reconciler, err := t(this)
 		if err != nil {
 			return nil, fmt.Errorf("creating reconciler %s failed: %s", n, err)
 		}
+		if i, ok := reconciler.(reconcile.EventRecorderInjection); ok {
+			i.InjectEventRecorder(this.events)
+		}
+		reconciler = newDeclaredFinalizer(this, reconciler)
+		// the first middleware is the outermost one
+		mw := def.Middlewares()
+		for i := len(mw) - 1; i >= 0; i-- {
+			reconciler = mw[i](this, n, reconciler)
+		}
 		this.reconcilers[n] = reconciler
 	}
 
@@ -206,6 +270,15 @@ func NewController(env Environment, def Definition, cmp mappings.Definition) (*c
 	return this, nil
 }
 
+// crdTimeout is the maximum duration waiting for a deployed CRD to be
+// established.
+func crdTimeout(env Environment) time.Duration {
+	if t := env.GetConfig().StartupTimeout; t > 0 {
+		return t
+	}
+	return 60 * time.Second
+}
+
 func isDeployCRDsDisabled(cl cluster.Interface) bool {
 	return cl.GetAttr(cluster.SUBOPTION_DISABLE_DEPLOY_CRDS) == true
 }
@@ -218,8 +291,13 @@ func (this *controller) IsReady() bool {
 	return this.ready.IsReady()
 }
 
+// GetReconciler returns the reconciler with the given name as created by
+// its ReconcilerType, without the configured middlewares.
 func (this *controller) GetReconciler(name string) reconcile.Interface {
-	return this.reconcilers[name]
+	if r := this.reconcilers[name]; r != nil {
+		return reconcile.Unwrap(r)
+	}
+	return nil
 }
 
 func (this *controller) addReconciler(cname string, key interface{}, pool string, reconciler string) error {
@@ -264,18 +342,17 @@ func (this *controller) getPool(name string) *pool {
 			def = &pooldef{name: name, size: 5, period: 30 * time.Second}
 		}
 		size := def.Size()
-		{
-			opt := this.env.GetConfig().GetOption(PoolSizeOptionName(this.GetName(), name))
-
-			if shared := this.env.GetConfig().GetOption(POOL_SIZE_OPTION); shared != nil && shared.Changed() && (opt == nil || !opt.Changed()) {
-				if shared != nil && shared.Changed() {
-					opt = shared
-				}
-			}
-			if opt != nil {
+		// precedence: pool option, controller option, shared option
+		for _, o := range []string{PoolSizeOptionName(this.GetName(), name), ControllerPoolSizeOptionName(this.GetName()), POOL_SIZE_OPTION} {
+			if opt := this.env.GetConfig().GetOption(o); opt != nil && opt.Changed() {
 				size = opt.IntValue()
+				break
 			}
 		}
+		if size <= 0 {
+			this.Warnf("invalid pool size %d for pool %s: using 1", size, name)
+			size = 1
+		}
 
 		period := def.Period()
 		{
@@ -291,12 +368,48 @@ func (this *controller) getPool(name string) *pool {
 			}
 		}
 
-		pool = NewPool(this, name, size, period)
+		limiter, limits := this.newRateLimiter()
+		pool = NewPool(this, name, size, period, limiter)
+		pool.limits = limits
+		pool.timeout = this.reconcileTimeout()
+		if pool.timeout > 0 {
+			pool.Infof("reconcile deadline %s", pool.timeout)
+		}
+		pool.deadletters.threshold, pool.deadletters.retry = this.deadLetterSettings()
 		this.pools[name] = pool
 	}
 	return pool
 }
 
+func (this *controller) reconcileTimeout() time.Duration {
+	timeout := this.definition.ReconcileTimeout()
+	for _, o := range []string{ControllerOption(this.GetName(), RECONCILE_TIMEOUT_OPTION), RECONCILE_TIMEOUT_OPTION} {
+		if opt := this.env.GetConfig().GetOption(o); opt != nil && opt.Changed() {
+			return opt.DurationValue()
+		}
+	}
+	return timeout
+}
+
+func (this *controller) deadLetterSettings() (int, time.Duration) {
+	threshold := this.definition.DeadLetterThreshold()
+	retry := this.definition.DeadLetterRetryPeriod()
+	cfg := this.env.GetConfig()
+	for _, o := range []string{ControllerOption(this.GetName(), DEADLETTER_THRESHOLD_OPTION), DEADLETTER_THRESHOLD_OPTION} {
+		if opt := cfg.GetOption(o); opt != nil && opt.Changed() {
+			threshold = opt.IntValue()
+			break
+		}
+	}
+	for _, o := range []string{ControllerOption(this.GetName(), DEADLETTER_RETRY_PERIOD_OPTION), DEADLETTER_RETRY_PERIOD_OPTION} {
+		if opt := cfg.GetOption(o); opt != nil && opt.Changed() {
+			retry = opt.DurationValue()
+			break
+		}
+	}
+	return threshold, retry
+}
+
 func (this *controller) GetPool(name string) Pool {
 	pool := this.pools[name]
 	if pool == nil {
@@ -309,6 +422,12 @@ func (this *controller) GetName() string {
 	return this.definition.GetName()
 }
 
+// GetInstanceName returns the name of the controller instance. It differs
+// from the name for controllers started for every dynamic cluster.
+func (this *controller) GetInstanceName() string {
+	return this.instance
+}
+
 func (this *controller) GetEnvironment() Environment {
 	return this.env
 }
@@ -323,6 +442,8 @@ func (this *controller) GetClusterHandler(name string) (*ClusterHandler, error)
 	if cluster == nil {
 		return nil, fmt.Errorf("unknown cluster %q for %q", name, this.GetName())
 	}
+	this.hlock.Lock()
+	defer this.hlock.Unlock()
 	h := this.handlers[cluster.GetName()]
 	if h == nil {
 		h = newClusterHandler(this, cluster)
@@ -331,6 +452,23 @@ func (this *controller) GetClusterHandler(name string) (*ClusterHandler, error)
 	return h, nil
 }
 
+func (this *controller) lookupClusterHandler(name string) *ClusterHandler {
+	this.hlock.Lock()
+	defer this.hlock.Unlock()
+	return this.handlers[name]
+}
+
+// IsWatching reports whether the controller has a worker pool for the
+// given resource type on the given cluster.
+func (this *controller) IsWatching(name string, key ResourceKey) bool {
+	h := this.lookupClusterHandler(name)
+	if h == nil {
+		return false
+	}
+	i := h.getResourceInfo(key)
+	return i != nil && len(i.pools) > 0
+}
+
 func (this *controller) GetClusterById(id string) cluster.Interface {
 	return this.clusters.GetById(id)
 }
@@ -359,30 +497,56 @@ func (this *controller) GetCachedObject(key resources.ClusterObjectKey) (resourc
 	return this.clusters.GetCachedObject(key)
 }
 
+func (this *controller) ResolveReference(base resources.Object, ref string, cached bool) (resources.Object, error) {
+	return cluster.ResolveReference(this.clusters, base.GetCluster().GetId(), ref, cached)
+}
+
 func (this *controller) EnqueueKey(key resources.ClusterObjectKey) error {
 	cluster := this.GetClusterById(key.Cluster())
 	if cluster == nil {
 		return fmt.Errorf("cluster with id %q not found", key.Cluster())
 	}
-	h := this.handlers[cluster.GetName()]
+	h, err := this.watchingClusterHandler(cluster.GetName())
+	if err != nil {
+		return err
+	}
 	return h.EnqueueKey(key)
 }
 
 func (this *controller) Enqueue(object resources.Object) error {
-	h := this.handlers[object.GetCluster().GetName()]
+	h, err := this.watchingClusterHandler(object.GetCluster().GetName())
+	if err != nil {
+		return err
+	}
 	return h.EnqueueObject(object)
 }
 
 func (this *controller) EnqueueAfter(object resources.Object, duration time.Duration) error {
-	h := this.handlers[object.GetCluster().GetName()]
+	h, err := this.watchingClusterHandler(object.GetCluster().GetName())
+	if err != nil {
+		return err
+	}
 	return h.EnqueueObjectAfter(object, duration)
 }
 
 func (this *controller) EnqueueRateLimited(object resources.Object) error {
-	h := this.handlers[object.GetCluster().GetName()]
+	h, err := this.watchingClusterHandler(object.GetCluster().GetName())
+	if err != nil {
+		return err
+	}
 	return h.EnqueueObjectRateLimited(object)
 }
 
+// watchingClusterHandler returns the handler for an effective cluster
+// the controller is watching resources for.
+func (this *controller) watchingClusterHandler(name string) (*ClusterHandler, error) {
+	h := this.lookupClusterHandler(name)
+	if h == nil {
+		return nil, fmt.Errorf("controller %q does not watch cluster %q", this.GetName(), name)
+	}
+	return h, nil
+}
+
 func (this *controller) EnqueueCommand(cmd string) error {
 	found := false
 	for _, p := range this.pools {
@@ -483,14 +647,14 @@ func (this *controller) Check() error {
 
 	// setup and check cluster handlers for all required cluster
 	for cname, watches := range this.GetDefinition().Watches() {
-		_, err := this.GetClusterHandler(cname)
+		h, err := this.GetClusterHandler(cname)
 		if err != nil {
 			return err
 		}
 		for _, watch := range watches {
 			_, err = h.GetResource(watch.ResourceType())
 			if err != nil {
-				return err
+				return fmt.Errorf("watch %q at cluster %q: %s", watch.ResourceType(), h, err)
 			}
 		}
 	}
@@ -508,7 +672,7 @@ func (this *controller) registerWatch(h *ClusterHandler, r WatchResource, p stri
 	if r.WatchSelectionFunction() != nil {
 		ns, optionsFunc = r.WatchSelectionFunction()(this)
 	}
-	return h.register(r.ResourceType(), ns, optionsFunc, this.getPool(p))
+	return h.register(r.ResourceType(), ns, optionsFunc, this.getPool(p), r.Predicates())
 }
 
 // Prepare finally prepares the controller to run
@@ -521,6 +685,7 @@ func (this *controller) Prepare() error {
 		return err
 	}
 
+	this.registerReadinessChecks()
 	this.Infof("setup reconcilers...")
 	for _, r := range this.reconcilers {
 		r.Setup()
@@ -541,11 +706,22 @@ func (this *controller) Prepare() error {
 
 		for _, watch := range watches {
 			this.Infof("watching additional resources %q at cluster %q", watch.ResourceType(), h)
-			this.registerWatch(h, watch, watch.PoolName())
+			err = this.registerWatch(h, watch, watch.PoolName())
+			if err != nil {
+				return err
+			}
 		}
 	}
+	this.hlock.Lock()
+	for _, h := range this.handlers {
+		h.setSynced()
+	}
+	this.hlock.Unlock()
 	this.Infof("setup watches done")
 
+	if err := this.runHooks("pre start", this.definition.PreStartHooks()); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -561,13 +737,97 @@ func (this *controller) Run() {
 	for _, r := range this.reconcilers {
 		r.Start()
 	}
+	if this.definition.StorageVersionMigration() {
+		this.migrateStorageVersions()
+	}
+	this.startTriggers()
 	this.Infof("controller started")
+	if err := this.runHooks("post start", this.definition.PostStartHooks()); err != nil {
+		this.Errorf("%s", err)
+		ctxutil.Cancel(this.env.GetContext())
+	}
 	<-this.ctx.Done()
 	this.Info("waiting for worker pools to shutdown")
-	ctxutil.SyncPointWait(this.ctx, 120*time.Second)
+	ctxutil.SyncPointWait(this.ctx, this.shutdownGracePeriod()+10*time.Second)
+	for n, r := range this.reconcilers {
+		if h, ok := reconcile.Unwrap(r).(reconcile.ShutdownHandler); ok {
+			this.Infof("shutdown reconciler %q", n)
+			h.Shutdown()
+		}
+	}
+	if err := this.runHooks("pre stop", this.definition.PreStopHooks()); err != nil {
+		this.Errorf("%s", err)
+	}
+	this.unregisterReadinessChecks()
 	this.Info("exit controller")
 }
 
+// runHooks calls the given lifecycle hooks in the order of their
+// registration and stops at the first failing one.
+func (this *controller) runHooks(kind string, hooks []LifecycleHook) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+	this.Infof("running %d %s hook(s)", len(hooks), kind)
+	for i, h := range hooks {
+		if err := h(this); err != nil {
+			return fmt.Errorf("%s hook %d of controller %q failed: %s", kind, i+1, this.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// migrateStorageVersions starts the storage version migration for all
+// CRDs deployed by the controller.
+func (this *controller) migrateStorageVersions() {
+	for n, crds := range this.definition.CustomResourceDefinitions() {
+		cluster := this.clusters.GetCluster(n)
+		if cluster == nil || isDeployCRDsDisabled(cluster) {
+			continue
+		}
+		for _, v := range crds {
+			crd := v.GetFor(cluster)
+			if crd == nil {
+				continue
+			}
+			name := crd.Name
+			ctxutil.SyncPointRun(this.ctx, func() {
+				if err := apiextensions.MigrateStorageVersion(this.ctx, cluster, name); err != nil {
+					this.Errorf("storage version migration failed: %s", err)
+				}
+			})
+		}
+	}
+}
+
+func (this *controller) readinessKey(h *ClusterHandler) string {
+	return fmt.Sprintf("controller:%s/cluster:%s", this.GetInstanceName(), h)
+}
+
+// registerReadinessChecks registers a readiness check for the cache sync
+// state of every cluster used by the controller.
+func (this *controller) registerReadinessChecks() {
+	this.hlock.Lock()
+	defer this.hlock.Unlock()
+	for _, h := range this.handlers {
+		readyz.Register(this.readinessKey(h), h.CheckSynced)
+	}
+}
+
+func (this *controller) unregisterReadinessChecks() {
+	this.hlock.Lock()
+	defer this.hlock.Unlock()
+	for _, h := range this.handlers {
+		readyz.Unregister(this.readinessKey(h))
+	}
+}
+
+// shutdownGracePeriod is the maximum duration in-flight reconcilations
+// may take after the controller has been stopped.
+func (this *controller) shutdownGracePeriod() time.Duration {
+	return this.env.GetConfig().ShutdownGracePeriod
+}
+
 func (this *controller) mustHandle(r resources.Object) bool {
 	for _, f := range this.filters {
 		if !f(this.owning.ResourceType(), r) {
@@ -607,6 +867,15 @@ func (this *controller) DecodeKey(key string) (string, *resources.ClusterObjectK
 	}
 	objKey := resources.NewClusterKey(cluster.GetId(), resources.NewGroupKind(apiGroup, kind), namespace, name)
 
-	r, err := cluster.GetCachedObject(objKey)
+	var r resources.Object
+	if h := this.lookupClusterHandler(cluster.GetName()); h != nil {
+		r, err = h.GetCachedObject(objKey)
+	} else {
+		r, err = cluster.GetCachedObject(objKey)
+	}
 	return "", &objKey, r, err
 }
+
+func (this *controller) GetEventRecorder() reconcile.EventRecorder {
+	return this.events
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package controller
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/server/debug"
+)
+
+const DEFAULT_DEADLETTER_RETRY_PERIOD = 30 * time.Minute
+
+func init() {
+	debug.Register("/debug/deadletters", DeadLetters)
+}
+
+// deadLetters keeps track of the consecutive failures of the items of a
+// pool. Items failing too often are moved to the dead letters, which are
+// only retried periodically with a long period instead of using the
+// rate limiter, to protect the pool from items failing permanently.
+type deadLetters struct {
+	lock      sync.Mutex
+	threshold int
+	retry     time.Duration
+	failures  map[string]int
+	letters   map[string]time.Time
+}
+
+func newDeadLetters() *deadLetters {
+	return &deadLetters{
+		failures: map[string]int{},
+		letters:  map[string]time.Time{},
+	}
+}
+
+func (this *deadLetters) retryPeriod() time.Duration {
+	if this.retry <= 0 {
+		return DEFAULT_DEADLETTER_RETRY_PERIOD
+	}
+	return this.retry
+}
+
+// failed records a failure for the given key. It returns whether the key
+// is a dead letter and whether it has just been moved to the dead letters.
+func (this *deadLetters) failed(key string) (dead bool, moved bool) {
+	if this.threshold <= 0 {
+		return false, false
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.failures[key]++
+	if _, ok := this.letters[key]; ok {
+		return true, false
+	}
+	if this.failures[key] >= this.threshold {
+		this.letters[key] = time.Now()
+		return true, true
+	}
+	return false, false
+}
+
+func (this *deadLetters) succeeded(key string) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	delete(this.failures, key)
+	delete(this.letters, key)
+}
+
+func (this *deadLetters) Len() int {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return len(this.letters)
+}
+
+// Keys returns the sorted keys of the dead letters.
+func (this *deadLetters) Keys() []string {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	keys := make([]string, 0, len(this.letters))
+	for k := range this.letters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (this *deadLetters) info(key string) (time.Time, int, bool) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	t, ok := this.letters[key]
+	return t, this.failures[key], ok
+}
+
+// RetryDeadLetters enqueues the dead letters of the pool matching the
+// given key (all for an empty key) for an immediate retry. It returns the
+// number of enqueued items.
+func (p *pool) RetryDeadLetters(key string) int {
+	count := 0
+	for _, k := range p.deadletters.Keys() {
+		if key == "" || key == k {
+			p.Infof("retrying dead letter %q", k)
+			p.workqueue.Add(k)
+			count++
+		}
+	}
+	return count
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// DeadLetters is a HTTP handler listing the dead letters of all pools
+// (GET) or retrying them (POST). The query parameters controller, pool
+// and key restrict the selected dead letters.
+func DeadLetters(w http.ResponseWriter, r *http.Request) {
+	selected := selectPools(r.FormValue("controller"), r.FormValue("pool"))
+	key := r.FormValue("key")
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	switch r.Method {
+	case http.MethodGet:
+		for _, p := range selected {
+			for _, k := range p.deadletters.Keys() {
+				if key != "" && key != k {
+					continue
+				}
+				if since, failures, ok := p.deadletters.info(k); ok {
+					fmt.Fprintf(w, "%s: %s: %d failures since %s\n", p.Key(), k, failures, since.Format(time.RFC3339))
+				}
+			}
+		}
+	case http.MethodPost:
+		count := 0
+		for _, p := range selected {
+			count += p.RetryDeadLetters(key)
+		}
+		io.WriteString(w, fmt.Sprintf("%d dead letters enqueued\n", count))
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package controller
+
+import (
+	"fmt"
+	"sync"
+)
+
+type dynamicWatchKey struct {
+	cluster    string
+	key        ResourceKey
+	pool       string
+	reconciler string
+}
+
+type dynamicWatch struct {
+	users int
+	// the reconciler mapping has been added for the watch
+	mapping *_ReconcilerMapping
+}
+
+// Watch starts watching a resource of a cluster at runtime, for example
+// after the CRD of the resource has been installed. Events are handled by
+// the given reconciler in the given (already defined) pool.
+// The returned function removes the watch again. The informer is stopped
+// when the last dynamic watch for the resource is removed. For resources
+// already watched by the controller definition the shared informer is used.
+func (this *controller) Watch(cname string, key ResourceKey, reconciler, pool string) (func(), error) {
+	this.dynlock.Lock()
+	defer this.dynlock.Unlock()
+
+	h, err := this.GetClusterHandler(cname)
+	if err != nil {
+		return nil, err
+	}
+	p := this.pools[pool]
+	if p == nil {
+		return nil, fmt.Errorf("pool %q not found for controller %q", pool, this.GetName())
+	}
+
+	wkey := dynamicWatchKey{h.cluster.GetName(), key, pool, reconciler}
+	w := this.dynamic[wkey]
+	if w == nil {
+		w = &dynamicWatch{}
+		src := _ReconcilerMapping{key: key.GroupKind(), cluster: h.cluster.GetName(), reconciler: reconciler}
+		if _, ok := this.mappings[src]; !ok {
+			w.mapping = &src
+		}
+		err = this.addReconciler(cname, key.GroupKind(), pool, reconciler)
+		if err != nil {
+			return nil, err
+		}
+		err = h.registerDynamic(this.ctx, key, p)
+		if err != nil {
+			if w.mapping != nil {
+				this.removeReconciler(*w.mapping, pool)
+			}
+			return nil, err
+		}
+		this.Infof("watching %q at cluster %q", key, h)
+		this.dynamic[wkey] = w
+	}
+	w.users++
+
+	once := sync.Once{}
+	return func() { once.Do(func() { this.unwatch(h, wkey) }) }, nil
+}
+
+func (this *controller) unwatch(h *ClusterHandler, wkey dynamicWatchKey) {
+	this.dynlock.Lock()
+	defer this.dynlock.Unlock()
+
+	w := this.dynamic[wkey]
+	if w == nil {
+		return
+	}
+	w.users--
+	if w.users <= 0 {
+		this.Infof("stop watching %q at cluster %q", wkey.key, h)
+		h.unregisterDynamic(wkey.key, this.pools[wkey.pool])
+		if w.mapping != nil {
+			this.removeReconciler(*w.mapping, wkey.pool)
+		}
+		delete(this.dynamic, wkey)
+	}
+}
+
+// removeReconciler removes a reconciler mapping. The reconciler is removed
+// from the pool if it is not mapped for the key for other clusters.
+func (this *controller) removeReconciler(src _ReconcilerMapping, pool string) {
+	delete(this.mappings, src)
+	for m, p := range this.mappings {
+		if m.key == src.key && m.reconciler == src.reconciler && p == pool {
+			return
+		}
+	}
+	if p := this.pools[pool]; p != nil {
+		p.removeReconciler(src.key, this.reconcilers[src.reconciler])
+	}
+}
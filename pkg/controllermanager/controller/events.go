@@ -0,0 +1,69 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package controller
+
+import (
+	"sync"
+
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/controller/reconcile"
+	"github.com/gardener/controller-manager-library/pkg/resources"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// eventRecorder records the events of a controller using a dedicated
+// recorder per cluster. The event broadcaster of the cluster aggregates
+// similar events and limits the event rate per object.
+type eventRecorder struct {
+	controller *controller
+	lock       sync.Mutex
+	recorders  map[string]record.EventRecorder
+}
+
+var _ reconcile.EventRecorder = &eventRecorder{}
+
+func newEventRecorder(controller *controller) *eventRecorder {
+	return &eventRecorder{
+		controller: controller,
+		recorders:  map[string]record.EventRecorder{},
+	}
+}
+
+func (this *eventRecorder) recorderFor(obj resources.Object) record.EventRecorder {
+	cluster := obj.GetCluster()
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	r := this.recorders[cluster.GetId()]
+	if r == nil {
+		r = cluster.Resources().EventRecorderFor(this.controller.GetName())
+		this.recorders[cluster.GetId()] = r
+	}
+	return r
+}
+
+func (this *eventRecorder) Eventf(obj resources.Object, eventtype, reason, msgfmt string, args ...interface{}) {
+	this.recorderFor(obj).Eventf(obj.Data(), eventtype, reason, msgfmt, args...)
+}
+
+func (this *eventRecorder) NormalEventf(obj resources.Object, reason, msgfmt string, args ...interface{}) {
+	this.Eventf(obj, corev1.EventTypeNormal, reason, msgfmt, args...)
+}
+
+func (this *eventRecorder) WarningEventf(obj resources.Object, reason, msgfmt string, args ...interface{}) {
+	this.Eventf(obj, corev1.EventTypeWarning, reason, msgfmt, args...)
+}
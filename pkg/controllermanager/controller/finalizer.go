@@ -17,6 +17,9 @@
 package controller
 
 import (
+	"fmt"
+
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/controller/reconcile"
 	"github.com/gardener/controller-manager-library/pkg/logger"
 	"github.com/gardener/controller-manager-library/pkg/resources"
 )
@@ -121,3 +124,45 @@ func (this *ClassesFinalizer) RemoveFinalizer(obj resources.Object) error {
 	}
 	return nil
 }
+
+////////////////////////////////////////////////////////////////////////////////
+
+// declaredFinalizer maintains the finalizer declared by a reconciler
+// (see reconcile.FinalizerDeclaration) on the objects of the main resource.
+// It is the innermost wrapper of the reconciler, so that middlewares
+// suppressing calls (like a dry run) suppress the finalizer handling, too.
+type declaredFinalizer struct {
+	reconcile.Wrapper
+	name       string
+	controller *controller
+}
+
+func newDeclaredFinalizer(c *controller, reconciler reconcile.Interface) reconcile.Interface {
+	if d, ok := reconciler.(reconcile.FinalizerDeclaration); ok && d.Finalizer() != "" {
+		return &declaredFinalizer{reconcile.Wrapper{Interface: reconciler}, d.Finalizer(), c}
+	}
+	return reconciler
+}
+
+func (this *declaredFinalizer) responsible(obj resources.Object) bool {
+	return this.controller.Owning().GroupKind() == obj.GroupKind()
+}
+
+func (this *declaredFinalizer) Reconcile(logger logger.LogContext, obj resources.Object) reconcile.Status {
+	if this.responsible(obj) && !obj.HasFinalizer(this.name) {
+		if err := obj.SetFinalizerByPatch(this.name); err != nil {
+			return reconcile.Delay(logger, fmt.Errorf("cannot set finalizer %q: %s", this.name, err))
+		}
+	}
+	return this.Interface.Reconcile(logger, obj)
+}
+
+func (this *declaredFinalizer) Delete(logger logger.LogContext, obj resources.Object) reconcile.Status {
+	status := this.Interface.Delete(logger, obj)
+	if this.responsible(obj) && status.IsSucceeded() && obj.HasFinalizer(this.name) {
+		if err := obj.RemoveFinalizerByPatch(this.name); err != nil {
+			return reconcile.Delay(logger, fmt.Errorf("cannot remove finalizer %q: %s", this.name, err))
+		}
+	}
+	return status
+}
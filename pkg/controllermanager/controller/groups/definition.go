@@ -30,11 +30,14 @@ type Definitions interface {
 	AllGroups() map[string]utils.StringSet
 	AllControllers() utils.StringSet
 	AllActivateExplicitlyControllers() utils.StringSet
+	LeaseFor(controller string) (string, bool, error)
 }
 
 type Definition interface {
 	Controllers() utils.StringSet
 	ActivateExplicitlyControllers() utils.StringSet
+	LeaseName() string
+	OmitLease() bool
 }
 
 type _Definition struct {
@@ -42,11 +45,16 @@ type _Definition struct {
 	controllers utils.StringSet
 
 	activateExplicitylyControllers utils.StringSet
+	lease                          string
+	omitLease                      bool
 }
 
 func (this *_Definition) copy() *_Definition {
 	return &_Definition{name: this.name, controllers: this.controllers.Copy(),
-		activateExplicitylyControllers: this.activateExplicitylyControllers.Copy()}
+		activateExplicitylyControllers: this.activateExplicitylyControllers.Copy(),
+		lease:                          this.lease,
+		omitLease:                      this.omitLease,
+	}
 }
 
 func (this *_Definition) Controllers() utils.StringSet {
@@ -57,6 +65,18 @@ func (this *_Definition) ActivateExplicitlyControllers() utils.StringSet {
 	return this.activateExplicitylyControllers.Copy()
 }
 
+// LeaseName is the name of the dedicated lease used for the controllers
+// of the group requiring a lease. If empty the common lease is used.
+func (this *_Definition) LeaseName() string {
+	return this.lease
+}
+
+// OmitLease indicates that the controllers of the group are run
+// without leader election.
+func (this *_Definition) OmitLease() bool {
+	return this.omitLease
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 
 func (this *_Definitions) Activate(controllers []string) (utils.StringSet, error) {
@@ -131,3 +151,27 @@ func (this *_Definitions) AllActivateExplicitlyControllers() utils.StringSet {
 	}
 	return set
 }
+
+// LeaseFor determines the dedicated lease name for a controller and
+// whether leader election should be omitted according to the groups
+// the controller belongs to. Groups with conflicting settings result in
+// an error.
+func (this *_Definitions) LeaseFor(controller string) (string, bool, error) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+
+	var found *_Definition
+	for _, g := range this.definitions {
+		if !g.controllers.Contains(controller) || (g.lease == "" && !g.omitLease) {
+			continue
+		}
+		if found != nil && (found.lease != g.lease || found.omitLease != g.omitLease) {
+			return "", false, fmt.Errorf("controller %q is member of groups %q and %q with conflicting lease settings", controller, found.name, g.name)
+		}
+		found = g
+	}
+	if found == nil {
+		return "", false, nil
+	}
+	return found.lease, found.omitLease, nil
+}
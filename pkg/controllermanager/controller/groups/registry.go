@@ -137,6 +137,21 @@ func (this Configuration) ActivateExplicitlyControllers(names ...string) {
 	this.definition.activateExplicitylyControllers.AddAll(names)
 }
 
+// Lease configures a dedicated lease for the controllers of the group
+// requiring a lease. This way controller groups can be elected
+// independently of each other.
+func (this Configuration) Lease(name string) {
+	this.definition.lease = name
+	this.definition.omitLease = false
+}
+
+// OmitLease runs the controllers of the group without leader election
+// on all instances of the controller manager, even if they require a lease.
+func (this Configuration) OmitLease() {
+	this.definition.lease = ""
+	this.definition.omitLease = true
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 
 func Register(name string) (*Configuration, error) {
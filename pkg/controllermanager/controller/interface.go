@@ -38,7 +38,21 @@ import (
 
 type ReconcilerType func(Interface) (reconcile.Interface, error)
 
+// LifecycleHook is called by the controller manager at dedicated points
+// of the lifecycle of a controller.
+type LifecycleHook func(Interface) error
+
+// Middleware wraps the reconciler with the given name of a controller
+// to add cross-cutting behaviour like logging or metrics. Wrapping
+// reconcilers should embed reconcile.Wrapper.
+type Middleware func(c Interface, name string, next reconcile.Interface) reconcile.Interface
+
 type Pool interface {
+	Size() int
+	SetSize(size int) error
+	SetRateLimits(baseDelay, maxDelay time.Duration, qps, burst int) error
+	RateLimits() string
+	ActiveWorkers() int
 	StartTicker()
 	EnqueueCommand(name string)
 	EnqueueCommandRateLimited(name string)
@@ -48,6 +62,7 @@ type Pool interface {
 
 type Interface interface {
 	GetName() string
+	GetInstanceName() string
 	IsReady() bool
 	Owning() ResourceKey
 	GetMainWatchResource() WatchResource
@@ -82,10 +97,20 @@ type Interface interface {
 	EnqueueAfter(object resources.Object, duration time.Duration) error
 	EnqueueCommand(cmd string) error
 
+	Watch(cluster string, key ResourceKey, reconciler, pool string) (func(), error)
+
 	logger.LogContext
 
 	GetObject(key resources.ClusterObjectKey) (resources.Object, error)
 	GetCachedObject(key resources.ClusterObjectKey) (resources.Object, error)
+	// ResolveReference resolves an object reference (see
+	// ClusterObjectKey.AsRefFor) relative to the cluster of the given
+	// object in the clusters used by the controller.
+	ResolveReference(base resources.Object, ref string, cached bool) (resources.Object, error)
+
+	// GetEventRecorder returns the event recorder of the controller,
+	// which records events with the component <controller manager>/<controller>.
+	GetEventRecorder() reconcile.EventRecorder
 }
 
 type WatchSelectionFunction func(c Interface) (string, resources.TweakListOptionsFunc)
@@ -93,6 +118,7 @@ type WatchSelectionFunction func(c Interface) (string, resources.TweakListOption
 type WatchResource interface {
 	ResourceType() ResourceKey
 	WatchSelectionFunction() WatchSelectionFunction
+	Predicates() []Predicate
 }
 
 type Watch interface {
@@ -106,6 +132,29 @@ type Command interface {
 	PoolName() string
 }
 
+// Trigger enqueues commands periodically according to a schedule.
+type Trigger interface {
+	GetName() string
+	Schedule() Schedule
+	Commands() []string
+	PoolName() string
+}
+
+// Index is an index for the cached objects of a resource
+// (see resources.Interface.AddIndexer).
+type Index interface {
+	GetName() string
+	ResourceType() ResourceKey
+	IndexFunc() resources.IndexFunc
+}
+
+// Transform is a transform for the cached objects of a resource
+// (see resources.Interface.AddTransform).
+type Transform interface {
+	ResourceType() ResourceKey
+	TransformFunc() resources.TransformFunc
+}
+
 // ResourceKey implementations are used as key and MUST therefore be value types
 type ResourceKey interface {
 	GroupKind() schema.GroupKind
@@ -139,11 +188,22 @@ func GetResourceKey(obj resources.Object) ResourceKey {
 
 type Watches map[string][]Watch
 type Commands map[string][]Command
+type Indexes map[string][]Index
+type Transforms map[string][]Transform
 
 const CLUSTER_MAIN = mappings.CLUSTER_MAIN
 const DEFAULT_POOL = "default"
+const DEFAULT_POOL_SIZE = 5
 const DEFAULT_RECONCILER = "default"
 
+type RateLimiterDefinition interface {
+	BaseDelay() time.Duration
+	MaxDelay() time.Duration
+	QPS() int
+	Burst() int
+	Factory() RateLimiterFactory
+}
+
 type PoolDefinition interface {
 	GetName() string
 	Size() int
@@ -165,12 +225,27 @@ type Definition interface {
 	MainWatchResource() WatchResource
 	Watches() Watches
 	Commands() Commands
+	Indexes() Indexes
+	Transforms() Transforms
 	Pools() map[string]PoolDefinition
+	PoolSize() int
+	ReconcileTimeout() time.Duration
+	RateLimiter() RateLimiterDefinition
+	DeadLetterThreshold() int
+	DeadLetterRetryPeriod() time.Duration
 	ResourceFilters() []ResourceFilter
 	RequiredClusters() []string
 	RequiredControllers() []string
+	After() []string
+	RequiredCRDs() map[string][]string
 	CustomResourceDefinitions() map[string][]*CustomResourceDefinition
 	RequireLease() bool
+	StorageVersionMigration() bool
+	Triggers() []Trigger
+	Middlewares() []Middleware
+	PreStartHooks() []LifecycleHook
+	PostStartHooks() []LifecycleHook
+	PreStopHooks() []LifecycleHook
 	FinalizerName() string
 	ActivateExplicitly() bool
 	ConfigOptions() map[string]OptionDefinition
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package controller
+
+import (
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/metrics"
+)
+
+var durationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300}
+
+var (
+	poolWorkersConfigured = metrics.NewGaugeFuncVec("controller_pool_workers_configured",
+		"Configured number of workers of a controller pool", "controller", "pool")
+	poolWorkersActive = metrics.NewGaugeFuncVec("controller_pool_workers_active",
+		"Number of running workers of a controller pool", "controller", "pool")
+	poolWorkersBusy = metrics.NewGaugeFuncVec("controller_pool_workers_busy",
+		"Number of workers of a controller pool currently processing an item", "controller", "pool")
+	reconcilePanics = metrics.NewCounterVec("reconcile_panics_total",
+		"Number of recovered panics of reconcilers", "controller", "pool")
+	reconcileTimeouts = metrics.NewCounterVec("reconcile_timeouts_total",
+		"Number of reconcilations exceeding the reconcile deadline", "controller", "pool")
+	reconcileDuration = metrics.NewHistogramVec("reconcile_duration_seconds",
+		"Duration of the processing of a workqueue item", durationBuckets, "controller", "pool")
+	reconcileErrors = metrics.NewCounterVec("reconcile_errors_total",
+		"Number of workqueue items whose processing failed", "controller", "pool")
+	reconcilePermanentErrors = metrics.NewCounterVec("reconcile_permanent_errors_total",
+		"Number of workqueue items whose processing failed with a permanent error", "controller", "pool")
+	reconcileDeadLetters = metrics.NewCounterVec("reconcile_dead_letters_total",
+		"Number of workqueue items moved to the dead letters", "controller", "pool")
+	poolDeadLetters = metrics.NewGaugeFuncVec("controller_pool_dead_letters",
+		"Number of dead letters of a controller pool", "controller", "pool")
+	reconcileRequeues = metrics.NewCounterVec("reconcile_requeues_total",
+		"Number of workqueue items requeued because of a failed or incomplete processing", "controller", "pool")
+	queueDepth = metrics.NewGaugeFuncVec("controller_pool_queue_depth",
+		"Number of due items in the workqueue of a controller pool", "controller", "pool")
+	queueOldestItemAge = metrics.NewGaugeFuncVec("controller_pool_queue_oldest_item_age_seconds",
+		"Time the oldest due item of the workqueue of a controller pool is waiting", "controller", "pool")
+	queueAdds = metrics.NewCounterVec("controller_pool_queue_adds_total",
+		"Number of items added to the workqueue of a controller pool", "controller", "pool")
+	queueLatency = metrics.NewHistogramVec("controller_pool_queue_duration_seconds",
+		"Time a due item is waiting in the workqueue of a controller pool until it is processed", durationBuckets, "controller", "pool")
+)
+
+func init() {
+	metrics.MustRegister(poolWorkersConfigured, poolWorkersActive, poolWorkersBusy, reconcilePanics, reconcileTimeouts,
+		reconcileDuration, reconcileErrors, reconcilePermanentErrors, reconcileRequeues,
+		reconcileDeadLetters, poolDeadLetters, queueDepth, queueOldestItemAge, queueAdds, queueLatency)
+}
+
+func (p *pool) registerMetrics() {
+	c := p.controller.GetInstanceName()
+	poolWorkersConfigured.Set(func() float64 { return float64(p.Size()) }, c, p.name)
+	poolWorkersActive.Set(func() float64 { return float64(p.ActiveWorkers()) }, c, p.name)
+	poolWorkersBusy.Set(func() float64 { return float64(p.BusyWorkers()) }, c, p.name)
+	poolDeadLetters.Set(func() float64 { return float64(p.deadletters.Len()) }, c, p.name)
+	queueDepth.Set(func() float64 { return float64(p.workqueue.Len()) }, c, p.name)
+	queueOldestItemAge.Set(func() float64 { return p.OldestItemAge().Seconds() }, c, p.name)
+}
+
+func (p *pool) unregisterMetrics() {
+	c := p.controller.GetInstanceName()
+	poolWorkersConfigured.Delete(c, p.name)
+	poolWorkersActive.Delete(c, p.name)
+	poolWorkersBusy.Delete(c, p.name)
+	queueDepth.Delete(c, p.name)
+	poolDeadLetters.Delete(c, p.name)
+	reconcileDeadLetters.Delete(c, p.name)
+	queueOldestItemAge.Delete(c, p.name)
+	reconcileDuration.Delete(c, p.name)
+	reconcileErrors.Delete(c, p.name)
+	reconcilePermanentErrors.Delete(c, p.name)
+	reconcileRequeues.Delete(c, p.name)
+	queueAdds.Delete(c, p.name)
+	queueLatency.Delete(c, p.name)
+}
+
+func (p *pool) observeReconcile(start time.Time) {
+	reconcileDuration.WithLabelValues(p.controller.GetInstanceName(), p.name).Observe(time.Since(start).Seconds())
+}
+
+func (p *pool) countError() {
+	reconcileErrors.WithLabelValues(p.controller.GetInstanceName(), p.name).Inc()
+}
+
+func (p *pool) countPermanentError() {
+	reconcilePermanentErrors.WithLabelValues(p.controller.GetInstanceName(), p.name).Inc()
+}
+
+func (p *pool) countDeadLetter() {
+	reconcileDeadLetters.WithLabelValues(p.controller.GetInstanceName(), p.name).Inc()
+}
+
+func (p *pool) countRequeue() {
+	reconcileRequeues.WithLabelValues(p.controller.GetInstanceName(), p.name).Inc()
+}
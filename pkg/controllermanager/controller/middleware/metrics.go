@@ -0,0 +1,34 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package middleware
+
+import (
+	"github.com/gardener/controller-manager-library/pkg/metrics"
+)
+
+var durationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300}
+
+var (
+	reconcilerCalls = metrics.NewCounterVec("reconciler_calls_total",
+		"Number of calls of a reconciler per operation and result", "controller", "reconciler", "operation", "result")
+	reconcilerCallDuration = metrics.NewHistogramVec("reconciler_call_duration_seconds",
+		"Duration of the calls of a reconciler per operation", durationBuckets, "controller", "reconciler", "operation")
+)
+
+func init() {
+	metrics.MustRegister(reconcilerCalls, reconcilerCallDuration)
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+// Package middleware provides middlewares for the reconcilers of a
+// controller (see controller.Configuration.Middleware).
+package middleware
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/controller"
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/controller/reconcile"
+	"github.com/gardener/controller-manager-library/pkg/logger"
+	"github.com/gardener/controller-manager-library/pkg/resources"
+)
+
+// call executes a single call of a reconciler.
+type call func(logger.LogContext) reconcile.Status
+
+// intercepted is a wrapping reconciler passing all object and command
+// calls through an interceptor function.
+type intercepted struct {
+	reconcile.Wrapper
+	intercept func(logger logger.LogContext, op string, key string, f call) reconcile.Status
+}
+
+func (this *intercepted) Reconcile(lgr logger.LogContext, obj resources.Object) reconcile.Status {
+	return this.intercept(lgr, "reconcile", obj.ClusterKey().String(), func(l logger.LogContext) reconcile.Status {
+		return this.Interface.Reconcile(l, obj)
+	})
+}
+
+func (this *intercepted) Delete(lgr logger.LogContext, obj resources.Object) reconcile.Status {
+	return this.intercept(lgr, "delete", obj.ClusterKey().String(), func(l logger.LogContext) reconcile.Status {
+		return this.Interface.Delete(l, obj)
+	})
+}
+
+func (this *intercepted) Deleted(lgr logger.LogContext, key resources.ClusterObjectKey) reconcile.Status {
+	return this.intercept(lgr, "deleted", key.String(), func(l logger.LogContext) reconcile.Status {
+		return this.Interface.Deleted(l, key)
+	})
+}
+
+func (this *intercepted) Command(lgr logger.LogContext, cmd string) reconcile.Status {
+	return this.intercept(lgr, "command", cmd, func(l logger.LogContext) reconcile.Status {
+		return this.Interface.Command(l, cmd)
+	})
+}
+
+func result(status reconcile.Status) string {
+	switch {
+	case status.Error != nil:
+		return "error"
+	case !status.Completed:
+		return "incomplete"
+	default:
+		return "succeeded"
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Logging logs every call of the reconciler with its duration and result.
+func Logging(c controller.Interface, name string, next reconcile.Interface) reconcile.Interface {
+	return &intercepted{reconcile.Wrapper{Interface: next}, func(logger logger.LogContext, op string, key string, f call) reconcile.Status {
+		logger.Debugf("reconciler %q: %s %s", name, op, key)
+		start := time.Now()
+		status := f(logger)
+		if status.Error != nil {
+			logger.Infof("reconciler %q: %s %s: %s after %s: %s", name, op, key, result(status), time.Since(start), status.Error)
+		} else {
+			logger.Infof("reconciler %q: %s %s: %s after %s", name, op, key, result(status), time.Since(start))
+		}
+		return status
+	}}
+}
+
+// Metrics records the number and the duration of the calls of the
+// reconciler per operation and result.
+func Metrics(c controller.Interface, name string, next reconcile.Interface) reconcile.Interface {
+	cname := c.GetInstanceName()
+	return &intercepted{reconcile.Wrapper{Interface: next}, func(logger logger.LogContext, op string, key string, f call) reconcile.Status {
+		start := time.Now()
+		status := f(logger)
+		reconcilerCalls.WithLabelValues(cname, name, op, result(status)).Inc()
+		reconcilerCallDuration.WithLabelValues(cname, name, op).Observe(time.Since(start).Seconds())
+		return status
+	}}
+}
+
+// DryRun suppresses all calls of the reconciler and only logs them.
+// Setup and Start are still called.
+func DryRun(c controller.Interface, name string, next reconcile.Interface) reconcile.Interface {
+	return &intercepted{reconcile.Wrapper{Interface: next}, func(logger logger.LogContext, op string, key string, f call) reconcile.Status {
+		logger.Infof("dry run: reconciler %q: skipping %s %s", name, op, key)
+		return reconcile.Succeeded(logger)
+	}}
+}
+
+// SingleFlight serializes the calls of the reconciler for the same object
+// or command. The workqueue of a pool already ensures this for a single
+// pool, so it is required only for reconcilers used by multiple pools.
+func SingleFlight(c controller.Interface, name string, next reconcile.Interface) reconcile.Interface {
+	flights := &flights{keys: map[string]*flight{}}
+	return &intercepted{reconcile.Wrapper{Interface: next}, func(logger logger.LogContext, op string, key string, f call) reconcile.Status {
+		defer flights.acquire(key)()
+		return f(logger)
+	}}
+}
+
+type flight struct {
+	sync.Mutex
+	users int
+}
+
+type flights struct {
+	lock sync.Mutex
+	keys map[string]*flight
+}
+
+// acquire waits until no other call is active for the key and returns
+// the function to release it.
+func (this *flights) acquire(key string) func() {
+	this.lock.Lock()
+	f := this.keys[key]
+	if f == nil {
+		f = &flight{}
+		this.keys[key] = f
+	}
+	f.users++
+	this.lock.Unlock()
+
+	f.Lock()
+	return func() {
+		f.Unlock()
+		this.lock.Lock()
+		defer this.lock.Unlock()
+		f.users--
+		if f.users == 0 {
+			delete(this.keys, key)
+		}
+	}
+}
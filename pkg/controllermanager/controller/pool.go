@@ -20,6 +20,8 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gardener/controller-manager-library/pkg/controllermanager/controller/reconcile"
@@ -52,7 +54,18 @@ func (this reconcilers) add(reconciler reconcile.Interface) reconcilers {
 	return append(this, reconciler)
 }
 
+func (this reconcilers) remove(reconciler reconcile.Interface) reconcilers {
+	result := reconcilers{}
+	for _, r := range this {
+		if r != reconciler {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
 type reconcilerMapping struct {
+	lock     sync.RWMutex
 	values   map[interface{}]reconcilers
 	matchers map[utils.Matcher]reconcilers
 }
@@ -65,6 +78,8 @@ func newReconcilerMapping() *reconcilerMapping {
 }
 
 func (this *reconcilerMapping) getReconcilers(key interface{}) reconcilers {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
 	i := this.values[key]
 	if i == nil {
 		cmd, ok := key.(string)
@@ -81,6 +96,8 @@ func (this *reconcilerMapping) getReconcilers(key interface{}) reconcilers {
 }
 
 func (this *reconcilerMapping) addReconciler(key interface{}, reconciler reconcile.Interface) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
 	switch k := key.(type) {
 	case utils.Matcher:
 		this.matchers[k] = this.matchers[k].add(reconciler)
@@ -89,32 +106,72 @@ func (this *reconcilerMapping) addReconciler(key interface{}, reconciler reconci
 	}
 }
 
+func (this *reconcilerMapping) removeReconciler(key interface{}, reconciler reconcile.Interface) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	switch k := key.(type) {
+	case utils.Matcher:
+		if r := this.matchers[k].remove(reconciler); len(r) > 0 {
+			this.matchers[k] = r
+		} else {
+			delete(this.matchers, k)
+		}
+	default:
+		if r := this.values[k].remove(reconciler); len(r) > 0 {
+			this.values[k] = r
+		} else {
+			delete(this.values, k)
+		}
+	}
+}
+
 type pool struct {
 	logger.LogContext
 	*controller
 	name        string
 	size        int
 	ctx         context.Context
+	rctx        context.Context
+	rcancel     context.CancelFunc
 	period      time.Duration
+	timeout     time.Duration
 	key         string
-	workqueue   workqueue.RateLimitingInterface
+	workqueue   *trackingQueue
 	reconcilers *reconcilerMapping
+	deadletters *deadLetters
+	active      int32
+	busy        int32
+	lock        sync.Mutex
+	running     bool
+	workers     map[int]bool
+	limits      string
 }
 
-func NewPool(controller *controller, name string, size int, period time.Duration) *pool {
+func NewPool(controller *controller, name string, size int, period time.Duration, limiter workqueue.RateLimiter) *pool {
+	limits := "custom"
+	if limiter == nil {
+		limiter = workqueue.DefaultControllerRateLimiter()
+		limits = defaultRateLimiter.String()
+	}
 
 	pool := &pool{
 		name:        name,
 		controller:  controller,
 		size:        size,
 		period:      period,
-		key:         fmt.Sprintf("controller:%s/pool:%s", controller.GetName(), name),
-		workqueue:   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), name),
+		key:         fmt.Sprintf("controller:%s/pool:%s", controller.GetInstanceName(), name),
+		workqueue:   newTrackingQueue(limiter, controller.GetInstanceName(), name),
 		reconcilers: newReconcilerMapping(),
+		deadletters: newDeadLetters(),
+		workers:     map[int]bool{},
+		limits:      limits,
 	}
 	pool.ctx, pool.LogContext = logger.WithLogger(
 		ctxutil.SyncContext(context.WithValue(controller.ctx, poolkey, pool)),
 		"pool", name)
+	// reconcilations are not cancelled together with the pool to let
+	// them finish within the shutdown grace period
+	pool.rctx, pool.rcancel = context.WithCancel(ctxutil.Detached(pool.ctx))
 	if pool.period != 0 {
 		pool.Infof("pool size %d, resync period %s", pool.size, pool.period.String())
 	} else {
@@ -132,6 +189,11 @@ func (p *pool) addReconciler(key interface{}, reconciler reconcile.Interface) {
 	p.reconcilers.addReconciler(key, reconciler)
 }
 
+func (p *pool) removeReconciler(key interface{}, reconciler reconcile.Interface) {
+	p.Infof("removing reconciler %T for key %q", reconciler, key)
+	p.reconcilers.removeReconciler(key, reconciler)
+}
+
 func (p *pool) getReconcilers(key interface{}) []reconcile.Interface {
 	p.whenReady()
 	return p.reconcilers.getReconcilers(key)
@@ -153,12 +215,51 @@ func (p *pool) Period() time.Duration {
 	return p.period
 }
 
+func (p *pool) Size() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.size
+}
+
+// ActiveWorkers returns the number of currently running workers.
+func (p *pool) ActiveWorkers() int {
+	return int(atomic.LoadInt32(&p.active))
+}
+
+// BusyWorkers returns the number of workers currently processing an item.
+func (p *pool) BusyWorkers() int {
+	return int(atomic.LoadInt32(&p.busy))
+}
+
+// OldestItemAge returns the duration the oldest due item of the
+// workqueue is waiting for processing.
+func (p *pool) OldestItemAge() time.Duration {
+	return p.workqueue.OldestItemAge()
+}
+
+// checkQueue reports a stale workqueue, whose oldest due item is
+// waiting longer than the configured limit.
+func (p *pool) checkQueue() error {
+	limit := p.queueStalenessLimit()
+	if limit <= 0 {
+		return nil
+	}
+	if age := p.OldestItemAge(); age > limit {
+		return fmt.Errorf("oldest item waiting for %s (limit %s)", age.Round(time.Second), limit)
+	}
+	return nil
+}
+
+func (p *pool) queueStalenessLimit() time.Duration {
+	return p.controller.env.GetConfig().QueueStalenessLimit
+}
+
 func (p *pool) StartTicker() {
 	// noop as periodic tick is always activated
 }
 
 func (p *pool) Run() {
-	p.Infof("Starting worker pool with %d workers", p.size)
+	p.Infof("Starting worker pool with %d workers", p.Size())
 	period := p.period
 	if period == 0 {
 		p.Infof("no reconcile period active -> start ticker")
@@ -168,14 +269,28 @@ func (p *pool) Run() {
 	p.workqueue.AddAfter(tickCmd, period)
 
 	healthz.Start(p.Key(), period)
-	for i := 0; i < p.size; i++ {
-		p.startWorker(i, p.ctx.Done())
-	}
+	healthz.Register(p.Key()+"/queue", p.checkQueue)
+	registerPool(p)
+	p.registerMetrics()
+	p.lock.Lock()
+	p.running = true
+	p.startWorkers()
+	p.lock.Unlock()
 
 	<-p.ctx.Done()
 	p.workqueue.ShutDown()
-	p.Infof("waiting for workers to shutdown")
-	ctxutil.SyncPointWait(p.ctx, 120*time.Second)
+	grace := p.shutdownGracePeriod()
+	if grace > 0 {
+		p.Infof("waiting for workers to shutdown (max. %s)", grace)
+		ctxutil.SyncPointWait(p.ctx, grace)
+	}
+	if busy := p.BusyWorkers(); busy > 0 {
+		p.Warnf("shutdown grace period exceeded: cancelling %d in-flight reconcilations", busy)
+	}
+	p.rcancel()
+	p.unregisterMetrics()
+	unregisterPool(p)
+	healthz.Unregister(p.Key() + "/queue")
 	healthz.End(p.Key())
 }
 
@@ -0,0 +1,202 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package controller
+
+import (
+	"reflect"
+
+	"github.com/gardener/controller-manager-library/pkg/resources"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Predicate decides whether an event for an object of a watch triggers
+// a reconcilation. Predicates can be attached to the watches of a
+// controller, so that reconcilers are not woken up by irrelevant changes,
+// for example status-only updates.
+type Predicate interface {
+	Add(obj resources.Object) bool
+	Update(old, new resources.Object) bool
+	Delete(obj resources.Object) bool
+}
+
+// PredicateFuncs is a Predicate based on functions, a missing function
+// accepts all events of its kind.
+type PredicateFuncs struct {
+	AddFunc    func(obj resources.Object) bool
+	UpdateFunc func(old, new resources.Object) bool
+	DeleteFunc func(obj resources.Object) bool
+}
+
+var _ Predicate = PredicateFuncs{}
+
+func (this PredicateFuncs) Add(obj resources.Object) bool {
+	return this.AddFunc == nil || this.AddFunc(obj)
+}
+
+func (this PredicateFuncs) Update(old, new resources.Object) bool {
+	return this.UpdateFunc == nil || this.UpdateFunc(old, new)
+}
+
+func (this PredicateFuncs) Delete(obj resources.Object) bool {
+	return this.DeleteFunc == nil || this.DeleteFunc(obj)
+}
+
+// UpdatePredicate filters update events, all other events are accepted.
+func UpdatePredicate(f func(old, new resources.Object) bool) Predicate {
+	return PredicateFuncs{UpdateFunc: f}
+}
+
+// ObjectPredicate filters the objects of all events. Update events are
+// accepted if the old or the new object is accepted.
+func ObjectPredicate(f func(obj resources.Object) bool) Predicate {
+	return PredicateFuncs{
+		AddFunc:    f,
+		UpdateFunc: func(old, new resources.Object) bool { return f(old) || f(new) },
+		DeleteFunc: f,
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// GenerationChanged accepts updates changing the generation (the spec)
+// or the deletion timestamp of an object. For resources without
+// generation all updates are accepted.
+func GenerationChanged() Predicate {
+	return UpdatePredicate(func(old, new resources.Object) bool {
+		if new.GetGeneration() == 0 {
+			return true
+		}
+		return old.GetGeneration() != new.GetGeneration() ||
+			(old.GetDeletionTimestamp() == nil) != (new.GetDeletionTimestamp() == nil)
+	})
+}
+
+// LabelsChanged accepts updates changing the labels of an object.
+func LabelsChanged() Predicate {
+	return UpdatePredicate(func(old, new resources.Object) bool {
+		return !equalStringMaps(old.GetLabels(), new.GetLabels())
+	})
+}
+
+// AnnotationChanged accepts updates changing one of the given annotations,
+// or any annotation if no key is given.
+func AnnotationChanged(keys ...string) Predicate {
+	return UpdatePredicate(func(old, new resources.Object) bool {
+		oa, na := old.GetAnnotations(), new.GetAnnotations()
+		if len(keys) == 0 {
+			return !equalStringMaps(oa, na)
+		}
+		for _, k := range keys {
+			ov, ook := oa[k]
+			nv, nok := na[k]
+			if ook != nok || ov != nv {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ResyncOnly accepts only updates without change, as sent for the
+// periodic resyncs of the informers.
+func ResyncOnly() Predicate {
+	return UpdatePredicate(func(old, new resources.Object) bool {
+		return old.GetResourceVersion() == new.GetResourceVersion()
+	})
+}
+
+// OwnerMatches accepts events for objects with an owner reference
+// to a resource of the given kind.
+func OwnerMatches(key ResourceKey) Predicate {
+	gk := key.GroupKind()
+	return ObjectPredicate(func(obj resources.Object) bool {
+		for _, ref := range obj.GetOwnerReferences() {
+			gv, err := schema.ParseGroupVersion(ref.APIVersion)
+			if err == nil && gv.Group == gk.Group && ref.Kind == gk.Kind {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// And accepts events accepted by all given predicates.
+func And(preds ...Predicate) Predicate {
+	return PredicateFuncs{
+		AddFunc: func(obj resources.Object) bool {
+			return acceptedByAll(preds, func(p Predicate) bool { return p.Add(obj) })
+		},
+		UpdateFunc: func(old, new resources.Object) bool {
+			return acceptedByAll(preds, func(p Predicate) bool { return p.Update(old, new) })
+		},
+		DeleteFunc: func(obj resources.Object) bool {
+			return acceptedByAll(preds, func(p Predicate) bool { return p.Delete(obj) })
+		},
+	}
+}
+
+// Or accepts events accepted by at least one of the given predicates.
+func Or(preds ...Predicate) Predicate {
+	return PredicateFuncs{
+		AddFunc: func(obj resources.Object) bool {
+			return acceptedByAny(preds, func(p Predicate) bool { return p.Add(obj) })
+		},
+		UpdateFunc: func(old, new resources.Object) bool {
+			return acceptedByAny(preds, func(p Predicate) bool { return p.Update(old, new) })
+		},
+		DeleteFunc: func(obj resources.Object) bool {
+			return acceptedByAny(preds, func(p Predicate) bool { return p.Delete(obj) })
+		},
+	}
+}
+
+// Not accepts events rejected by the given predicate.
+func Not(pred Predicate) Predicate {
+	return PredicateFuncs{
+		AddFunc:    func(obj resources.Object) bool { return !pred.Add(obj) },
+		UpdateFunc: func(old, new resources.Object) bool { return !pred.Update(old, new) },
+		DeleteFunc: func(obj resources.Object) bool { return !pred.Delete(obj) },
+	}
+}
+
+func acceptedByAll(preds []Predicate, f func(p Predicate) bool) bool {
+	for _, p := range preds {
+		if !f(p) {
+			return false
+		}
+	}
+	return true
+}
+
+func acceptedByAny(preds []Predicate, f func(p Predicate) bool) bool {
+	for _, p := range preds {
+		if f(p) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalStringMaps(a, b map[string]string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
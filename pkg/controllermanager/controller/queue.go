@@ -0,0 +1,106 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+// trackingQueue is a rate limiting work queue remembering the point in
+// time each pending item became due. It is used to determine the age of
+// the oldest item waiting for processing and to record the queue metrics
+// of a controller pool.
+type trackingQueue struct {
+	workqueue.RateLimitingInterface
+	limiter    *dynamicRateLimiter
+	controller string
+	name       string
+	lock       sync.Mutex
+	due        map[interface{}]time.Time
+}
+
+var _ workqueue.RateLimitingInterface = &trackingQueue{}
+
+func newTrackingQueue(limiter workqueue.RateLimiter, controller, name string) *trackingQueue {
+	dynamic := &dynamicRateLimiter{limiter: limiter}
+	return &trackingQueue{
+		RateLimitingInterface: workqueue.NewNamedRateLimitingQueue(dynamic, name),
+		limiter:               dynamic,
+		controller:            controller,
+		name:                  name,
+		due:                   map[interface{}]time.Time{},
+	}
+}
+
+func (this *trackingQueue) track(item interface{}, due time.Time) {
+	if this.ShuttingDown() {
+		return
+	}
+	queueAdds.WithLabelValues(this.controller, this.name).Inc()
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if t, ok := this.due[item]; !ok || due.Before(t) {
+		this.due[item] = due
+	}
+}
+
+func (this *trackingQueue) Add(item interface{}) {
+	this.track(item, time.Now())
+	this.RateLimitingInterface.Add(item)
+}
+
+func (this *trackingQueue) AddAfter(item interface{}, duration time.Duration) {
+	this.track(item, time.Now().Add(duration))
+	this.RateLimitingInterface.AddAfter(item, duration)
+}
+
+func (this *trackingQueue) AddRateLimited(item interface{}) {
+	this.AddAfter(item, this.limiter.When(item))
+}
+
+func (this *trackingQueue) Get() (interface{}, bool) {
+	item, shutdown := this.RateLimitingInterface.Get()
+	if !shutdown {
+		this.lock.Lock()
+		due, ok := this.due[item]
+		delete(this.due, item)
+		this.lock.Unlock()
+		if ok && !due.After(time.Now()) {
+			queueLatency.WithLabelValues(this.controller, this.name).Observe(time.Since(due).Seconds())
+		}
+	}
+	return item, shutdown
+}
+
+// OldestItemAge returns the duration the oldest due item is waiting
+// for processing.
+func (this *trackingQueue) OldestItemAge() time.Duration {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	now := time.Now()
+	age := time.Duration(0)
+	for _, t := range this.due {
+		if d := now.Sub(t); d > age {
+			age = d
+		}
+	}
+	return age
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/config"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const RATELIMIT_BASE_DELAY_OPTION = "ratelimit.base-delay"
+const RATELIMIT_MAX_DELAY_OPTION = "ratelimit.max-delay"
+const RATELIMIT_QPS_OPTION = "ratelimit.qps"
+const RATELIMIT_BURST_OPTION = "ratelimit.burst"
+
+// defaults of workqueue.DefaultControllerRateLimiter
+const DEFAULT_RATELIMIT_BASE_DELAY = 5 * time.Millisecond
+const DEFAULT_RATELIMIT_MAX_DELAY = 1000 * time.Second
+const DEFAULT_RATELIMIT_QPS = 10
+const DEFAULT_RATELIMIT_BURST = 100
+
+// RateLimiterFactory creates a new rate limiter for the workqueue of a pool.
+type RateLimiterFactory func() workqueue.RateLimiter
+
+///////////////////////////////////////////////////////////////////////////////
+
+type ratelimiterdef struct {
+	baseDelay time.Duration
+	maxDelay  time.Duration
+	qps       int
+	burst     int
+	factory   RateLimiterFactory
+}
+
+var defaultRateLimiter = &ratelimiterdef{
+	baseDelay: DEFAULT_RATELIMIT_BASE_DELAY,
+	maxDelay:  DEFAULT_RATELIMIT_MAX_DELAY,
+	qps:       DEFAULT_RATELIMIT_QPS,
+	burst:     DEFAULT_RATELIMIT_BURST,
+}
+
+func (this *ratelimiterdef) BaseDelay() time.Duration {
+	return this.baseDelay
+}
+func (this *ratelimiterdef) MaxDelay() time.Duration {
+	return this.maxDelay
+}
+func (this *ratelimiterdef) QPS() int {
+	return this.qps
+}
+func (this *ratelimiterdef) Burst() int {
+	return this.burst
+}
+func (this *ratelimiterdef) Factory() RateLimiterFactory {
+	return this.factory
+}
+
+func (this *ratelimiterdef) String() string {
+	if this.factory != nil {
+		return "custom"
+	}
+	return fmt.Sprintf("base delay %s, max delay %s, qps %d, burst %d", this.baseDelay, this.maxDelay, this.qps, this.burst)
+}
+
+///////////////////////////////////////////////////////////////////////////////
+
+// NewRateLimiter creates a rate limiter combining a per-item exponential
+// backoff (disabled for baseDelay 0) and an overall token bucket
+// (disabled for qps 0). If both are used, the maximum delay is taken.
+func NewRateLimiter(baseDelay, maxDelay time.Duration, qps, burst int) (workqueue.RateLimiter, error) {
+	limiters := []workqueue.RateLimiter{}
+	if baseDelay < 0 || qps < 0 {
+		return nil, fmt.Errorf("negative rate limiter settings")
+	}
+	if baseDelay > 0 {
+		if maxDelay < baseDelay {
+			maxDelay = baseDelay
+		}
+		limiters = append(limiters, workqueue.NewItemExponentialFailureRateLimiter(baseDelay, maxDelay))
+	}
+	if qps > 0 {
+		if burst <= 0 {
+			burst = qps
+		}
+		limiters = append(limiters, &workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(qps), burst)})
+	}
+	switch len(limiters) {
+	case 0:
+		return nil, fmt.Errorf("per-item and overall rate limiting disabled")
+	case 1:
+		return limiters[0], nil
+	default:
+		return workqueue.NewMaxOfRateLimiter(limiters...), nil
+	}
+}
+
+func RateLimiterOptionName(controller, name string) string {
+	return ControllerOption(controller, name)
+}
+
+// newRateLimiter creates the rate limiter for a pool of the controller and
+// returns it together with a description of its settings.
+func (this *controller) newRateLimiter() (workqueue.RateLimiter, string) {
+	def := this.definition.RateLimiter()
+	changed := false
+
+	lookup := func(name string) *config.ArbitraryOption {
+		cfg := this.env.GetConfig()
+		for _, n := range []string{RateLimiterOptionName(this.GetName(), name), name} {
+			if opt := cfg.GetOption(n); opt != nil && opt.Changed() {
+				changed = true
+				return opt
+			}
+		}
+		return nil
+	}
+
+	baseDelay := def.BaseDelay()
+	if opt := lookup(RATELIMIT_BASE_DELAY_OPTION); opt != nil {
+		baseDelay = opt.DurationValue()
+	}
+	maxDelay := def.MaxDelay()
+	if opt := lookup(RATELIMIT_MAX_DELAY_OPTION); opt != nil {
+		maxDelay = opt.DurationValue()
+	}
+	qps := def.QPS()
+	if opt := lookup(RATELIMIT_QPS_OPTION); opt != nil {
+		qps = opt.IntValue()
+	}
+	burst := def.Burst()
+	if opt := lookup(RATELIMIT_BURST_OPTION); opt != nil {
+		burst = opt.IntValue()
+	}
+
+	if !changed && def.Factory() != nil {
+		return def.Factory()(), "custom"
+	}
+	limiter, err := NewRateLimiter(baseDelay, maxDelay, qps, burst)
+	if err != nil {
+		this.Warnf("invalid rate limiter settings: %s: using default", err)
+		return workqueue.DefaultControllerRateLimiter(), defaultRateLimiter.String()
+	}
+	return limiter, (&ratelimiterdef{baseDelay: baseDelay, maxDelay: maxDelay, qps: qps, burst: burst}).String()
+}
@@ -0,0 +1,33 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package reconcile
+
+import (
+	"context"
+
+	"github.com/gardener/controller-manager-library/pkg/logger"
+)
+
+// Context returns the context for a reconciler call given by the logger
+// passed to the reconciler. It is cancelled if the reconcile deadline of
+// the controller is exceeded.
+func Context(logger logger.LogContext) context.Context {
+	if c, ok := logger.(interface{ Context() context.Context }); ok {
+		return c.Context()
+	}
+	return context.Background()
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package reconcile
+
+import (
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/resources"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// The error classes below control the requeue behavior of the controller
+// for an item whose reconcilation failed with such an error, regardless of
+// the completion flag of the status. Other errors are handled according
+// to the status contract.
+
+type permanentError struct {
+	error
+}
+
+type transientError struct {
+	error
+	after time.Duration
+}
+
+type conflictError struct {
+	error
+}
+
+// Permanent classifies an error as permanent: the item is not requeued
+// until it is changed again, a warning event is recorded for the object
+// and the error is counted by the metric reconcile_permanent_errors_total.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err}
+}
+
+// Transient classifies an error as transient: the item is requeued after
+// the given duration without applying the rate limiter.
+func Transient(err error, after time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &transientError{err, after}
+}
+
+// Conflict classifies an error as update conflict: the item is requeued
+// immediately.
+func Conflict(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &conflictError{err}
+}
+
+func IsPermanent(err error) bool {
+	_, ok := err.(*permanentError)
+	return ok
+}
+
+// RequeueAfter returns the requeue hint of a transient error. Errors of a
+// throttling API server are requeued after the (jittered) delay
+// suggested by the server.
+func RequeueAfter(err error) (time.Duration, bool) {
+	if t, ok := err.(*transientError); ok {
+		return t.after, true
+	}
+	return resources.RetryAfter(err)
+}
+
+// IsConflict reports conflict errors, either classified by Conflict or
+// returned by the API server.
+func IsConflict(err error) bool {
+	if _, ok := err.(*conflictError); ok {
+		return true
+	}
+	return err != nil && errors.IsConflict(err)
+}
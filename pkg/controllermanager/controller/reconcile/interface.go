@@ -48,7 +48,12 @@ type Status struct {
 	// Interval selects a modified reconcilation reschedule for the actual item
 	// -1 (default) no modification
 	//  0 no reschedule
-	//  >0 rescgule after given interval
+	//  >0 reschedule after given interval
+	// The reschedule is handled by the delaying workqueue of the pool, it is
+	// limited by the resync period of the pool, if configured.
+	// Use RescheduleAfter or RescheduleAt to request a dedicated reconcilation
+	// for a successfully reconciled item (for example for certificate renewals
+	// or TTL based cleanups) instead of returning an error.
 	// If multiple reconcilers are called for an item the Intervals are combined as follows.
 	// - if there is at least one status with Interval> 0,the minimum is used
 	// - if all status disable reschedule it will be disabled
@@ -56,6 +61,38 @@ type Status struct {
 	Interval time.Duration
 }
 
+// FinalizerDeclaration may be implemented by reconcilers to let the
+// framework maintain a finalizer on the objects of the main resource.
+// The finalizer is added before the first call of Reconcile and removed
+// after a successful call of Delete. Both is done by patching only the
+// finalizer list of the object.
+type FinalizerDeclaration interface {
+	Finalizer() string
+}
+
+// ShutdownHandler may be implemented by reconcilers to flush pending
+// work, for example buffered status updates, on shutdown. It is called
+// after the in-flight reconcilations of the controller have finished and
+// before the lease of the controller manager is released.
+type ShutdownHandler interface {
+	Shutdown()
+}
+
+// EventRecorder records events for objects of the clusters used by a
+// controller. Events are recorded at the cluster of the object.
+type EventRecorder interface {
+	Eventf(obj resources.Object, eventtype, reason, msgfmt string, args ...interface{})
+	NormalEventf(obj resources.Object, reason, msgfmt string, args ...interface{})
+	WarningEventf(obj resources.Object, reason, msgfmt string, args ...interface{})
+}
+
+// EventRecorderInjection may be implemented by reconcilers to get the
+// event recorder of their controller injected directly after their
+// creation.
+type EventRecorderInjection interface {
+	InjectEventRecorder(EventRecorder)
+}
+
 type Interface interface {
 	Setup()
 	Start()
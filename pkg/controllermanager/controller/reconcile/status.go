@@ -41,6 +41,12 @@ func (this Status) RescheduleAfter(d time.Duration) Status {
 	return this
 }
 
+// RescheduleAt requests a reschedule of the actual item at the given time.
+// Times in the past lead to an immediate reschedule.
+func (this Status) RescheduleAt(t time.Time) Status {
+	return this.RescheduleAfter(until(t))
+}
+
 func (this Status) Stop() Status {
 	this.Interval = 0
 	return this
@@ -52,3 +58,12 @@ func (this Status) StopIfSucceeded() Status {
 	}
 	return this
 }
+
+func until(t time.Time) time.Duration {
+	d := time.Until(t)
+	if d <= 0 {
+		// 0 would disable the reschedule
+		d = time.Nanosecond
+	}
+	return d
+}
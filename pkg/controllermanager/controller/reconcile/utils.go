@@ -32,6 +32,24 @@ func Succeeded(logger logger.LogContext, msg ...interface{}) Status {
 	return Status{true, nil, -1}
 }
 
+// RescheduleAfter reports a successful reconcilation and requests
+// the item to be reconciled again after the given duration.
+func RescheduleAfter(logger logger.LogContext, d time.Duration, msg ...interface{}) Status {
+	if len(msg) > 0 {
+		logger.Info(msg...)
+	}
+	if d <= 0 {
+		d = time.Nanosecond
+	}
+	return Status{true, nil, d}
+}
+
+// RescheduleAt reports a successful reconcilation and requests
+// the item to be reconciled again at the given time.
+func RescheduleAt(logger logger.LogContext, t time.Time, msg ...interface{}) Status {
+	return RescheduleAfter(logger, until(t), msg...)
+}
+
 func Repeat(logger logger.LogContext, err ...error) Status {
 	for _, e := range err {
 		logger.Error(e)
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package reconcile
+
+// Wrapped is implemented by reconcilers wrapping another reconciler,
+// for example by a middleware.
+type Wrapped interface {
+	Unwrap() Interface
+}
+
+// Wrapper can be embedded by wrapping reconcilers. It forwards all calls
+// to the wrapped reconciler, so only the intercepted methods have to be
+// implemented.
+type Wrapper struct {
+	Interface
+}
+
+var _ Wrapped = Wrapper{}
+
+func (this Wrapper) Unwrap() Interface {
+	return this.Interface
+}
+
+// Unwrap returns the innermost reconciler of a chain of wrapping
+// reconcilers. It is used to check for optional interfaces like
+// FinalizerDeclaration or ShutdownHandler.
+func Unwrap(r Interface) Interface {
+	for {
+		w, ok := r.(Wrapped)
+		if !ok {
+			return r
+		}
+		r = w.Unwrap()
+	}
+}
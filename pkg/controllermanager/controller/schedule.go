@@ -0,0 +1,207 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule determines the points in time a trigger fires.
+type Schedule interface {
+	// Next returns the first activation after the given time.
+	Next(time.Time) time.Time
+	String() string
+}
+
+// ParseSchedule parses a schedule spec. It is either a fixed interval
+// (@every <duration>), a descriptor (@yearly, @monthly, @weekly, @daily,
+// @hourly) or a standard cron expression with the five fields minute,
+// hour, day of month, month and day of week evaluated in local time.
+// The fields support lists, ranges and steps.
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(spec[len("@every "):]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval in schedule %q: %s", spec, err)
+		}
+		if d < time.Second {
+			return nil, fmt.Errorf("interval of schedule %q must be at least one second", spec)
+		}
+		return interval(d), nil
+	}
+	switch spec {
+	case "@yearly", "@annually":
+		spec = "0 0 1 1 *"
+	case "@monthly":
+		spec = "0 0 1 * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	case "@daily", "@midnight":
+		spec = "0 0 * * *"
+	case "@hourly":
+		spec = "0 * * * *"
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("schedule %q must have five fields", spec)
+	}
+	s := &cronSchedule{spec: spec}
+	var err error
+	for i, b := range cronBounds {
+		if s.fields[i], err = parseCronField(fields[i], b.min, b.max); err != nil {
+			return nil, fmt.Errorf("invalid %s in schedule %q: %s", b.name, spec, err)
+		}
+	}
+	// sunday may be given as 7
+	if s.fields[4]&(1<<7) != 0 {
+		s.fields[4] |= 1
+	}
+	s.domStar = fields[2] == "*" || strings.HasPrefix(fields[2], "*/")
+	s.dowStar = fields[4] == "*" || strings.HasPrefix(fields[4], "*/")
+	return s, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type interval time.Duration
+
+func (this interval) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(this))
+}
+
+func (this interval) String() string {
+	return "@every " + time.Duration(this).String()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+var cronBounds = []struct {
+	name     string
+	min, max int
+}{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7},
+}
+
+// cronSchedule keeps the allowed values of the fields as bit sets.
+type cronSchedule struct {
+	spec    string
+	fields  [5]uint64
+	domStar bool
+	dowStar bool
+}
+
+func (this *cronSchedule) String() string {
+	return this.spec
+}
+
+func (this *cronSchedule) matches(field int, v int) bool {
+	return this.fields[field]&(1<<uint(v)) != 0
+}
+
+// matchesDay follows the cron convention: if both, day of month and day
+// of week, are restricted, a day matching any of them is selected.
+func (this *cronSchedule) matchesDay(t time.Time) bool {
+	dom := this.matches(2, t.Day())
+	dow := this.matches(4, int(t.Weekday()))
+	if this.domStar || this.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+func (this *cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if !this.matches(3, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !this.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !this.matches(1, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !this.matches(0, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	// impossible dates like 30th of february
+	return time.Time{}
+}
+
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("invalid step %q", part[i+1:])
+			}
+			step = s
+			part = part[:i]
+		}
+		lo, hi := min, max
+		if part != "*" {
+			var err error
+			if i := strings.Index(part, "-"); i >= 0 {
+				if lo, err = cronValue(part[:i], min, max); err != nil {
+					return 0, err
+				}
+				if hi, err = cronValue(part[i+1:], min, max); err != nil {
+					return 0, err
+				}
+				if hi < lo {
+					return 0, fmt.Errorf("invalid range %q", part)
+				}
+			} else {
+				if lo, err = cronValue(part, min, max); err != nil {
+					return 0, err
+				}
+				if step == 1 {
+					hi = lo
+				}
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func cronValue(s string, min, max int) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v < min || v > max {
+		return 0, fmt.Errorf("invalid value %q (allowed %d-%d)", s, min, max)
+	}
+	return v, nil
+}
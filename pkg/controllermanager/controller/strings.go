@@ -19,6 +19,8 @@ package controller
 import (
 	"fmt"
 	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/utils"
 )
 
 func toString(o interface{}) string {
@@ -63,6 +65,11 @@ func toString(o interface{}) string {
 		}
 	case Command:
 		return fmt.Sprintf("%s in %s with %s", v.Key(), v.PoolName(), v.Reconciler())
+	case map[string][]string:
+		for n, l := range v {
+			s = fmt.Sprintf("%s%s%s: %s", s, sep, n, utils.Strings(l...))
+			sep = ", "
+		}
 	default:
 		return fmt.Sprintf("%s", o)
 	}
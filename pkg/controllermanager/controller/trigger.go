@@ -0,0 +1,85 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/ctxutil"
+)
+
+const TRIGGER_OPTION_PREFIX = "trigger"
+
+func TriggerOptionName(controller, name string) string {
+	return fmt.Sprintf("%s.%s.%s", controller, TRIGGER_OPTION_PREFIX, name)
+}
+
+// triggerSchedule returns the schedule of a trigger, which may be
+// overwritten by a command line option. An empty option disables the trigger.
+func (this *controller) triggerSchedule(t Trigger) Schedule {
+	opt := this.env.GetConfig().GetOption(TriggerOptionName(this.GetName(), t.GetName()))
+	if opt == nil || !opt.Changed() {
+		return t.Schedule()
+	}
+	if opt.StringValue() == "" {
+		return nil
+	}
+	s, err := ParseSchedule(opt.StringValue())
+	if err != nil {
+		this.Warnf("trigger %q: %s: using %q", t.GetName(), err, t.Schedule())
+		return t.Schedule()
+	}
+	return s
+}
+
+// startTriggers starts the triggers of the controller. They run until the
+// controller is stopped.
+func (this *controller) startTriggers() {
+	for _, t := range this.definition.Triggers() {
+		s := this.triggerSchedule(t)
+		if s == nil {
+			this.Infof("trigger %q disabled", t.GetName())
+			continue
+		}
+		p := this.getPool(t.PoolName())
+		this.Infof("starting trigger %q (%s) for commands %v of pool %s", t.GetName(), s, t.Commands(), p.GetName())
+		cmds := t.Commands()
+		ctxutil.SyncPointRun(this.ctx, func() { p.runTrigger(s, cmds) })
+	}
+}
+
+func (p *pool) runTrigger(s Schedule, cmds []string) {
+	for {
+		now := time.Now()
+		next := s.Next(now)
+		if next.IsZero() {
+			p.Warnf("schedule %s never fires", s)
+			return
+		}
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-p.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		for _, cmd := range cmds {
+			p.EnqueueCommand(cmd)
+		}
+	}
+}
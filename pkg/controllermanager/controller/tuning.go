@@ -0,0 +1,267 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package controller
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/server/debug"
+
+	"k8s.io/client-go/util/workqueue"
+)
+
+func init() {
+	debug.Register("/debug/pools", Pools)
+}
+
+// dynamicRateLimiter is a rate limiter whose implementation can be
+// replaced at runtime.
+type dynamicRateLimiter struct {
+	lock    sync.RWMutex
+	limiter workqueue.RateLimiter
+}
+
+var _ workqueue.RateLimiter = &dynamicRateLimiter{}
+
+func (this *dynamicRateLimiter) get() workqueue.RateLimiter {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.limiter
+}
+
+func (this *dynamicRateLimiter) set(limiter workqueue.RateLimiter) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.limiter = limiter
+}
+
+func (this *dynamicRateLimiter) When(item interface{}) time.Duration {
+	return this.get().When(item)
+}
+
+func (this *dynamicRateLimiter) Forget(item interface{}) {
+	this.get().Forget(item)
+}
+
+func (this *dynamicRateLimiter) NumRequeues(item interface{}) int {
+	return this.get().NumRequeues(item)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// SetSize changes the number of workers of the pool at runtime.
+// Superfluous workers exit after finishing their actual item.
+func (p *pool) SetSize(size int) error {
+	if size <= 0 {
+		return fmt.Errorf("invalid pool size %d", size)
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if size != p.size {
+		p.Infof("changing pool size from %d to %d", p.size, size)
+		p.size = size
+	}
+	if p.running {
+		p.startWorkers()
+	}
+	return nil
+}
+
+// startWorkers starts the missing workers, the lock must be held.
+func (p *pool) startWorkers() {
+	if p.ctx.Err() != nil {
+		return
+	}
+	for i := 0; i < p.size; i++ {
+		if !p.workers[i] {
+			p.workers[i] = true
+			p.startWorker(i, p.ctx.Done())
+		}
+	}
+}
+
+// retireWorker reports whether the worker with the given number is
+// superfluous for the actual pool size. In this case it is deregistered
+// and must exit.
+func (p *pool) retireWorker(number int) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if number < p.size {
+		return false
+	}
+	delete(p.workers, number)
+	return true
+}
+
+// SetRateLimits replaces the rate limiter of the pool at runtime
+// (see NewRateLimiter). The failure history of the items is reset.
+func (p *pool) SetRateLimits(baseDelay, maxDelay time.Duration, qps, burst int) error {
+	limiter, err := NewRateLimiter(baseDelay, maxDelay, qps, burst)
+	if err != nil {
+		return err
+	}
+	desc := (&ratelimiterdef{baseDelay: baseDelay, maxDelay: maxDelay, qps: qps, burst: burst}).String()
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.Infof("changing rate limiter to %s", desc)
+	p.workqueue.limiter.set(limiter)
+	p.limits = desc
+	return nil
+}
+
+// RateLimits describes the actual rate limiter of the pool.
+func (p *pool) RateLimits() string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.limits
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+var (
+	poolLock sync.Mutex
+	pools    = map[string]*pool{}
+)
+
+func registerPool(p *pool) {
+	poolLock.Lock()
+	defer poolLock.Unlock()
+	pools[p.Key()] = p
+}
+
+func unregisterPool(p *pool) {
+	poolLock.Lock()
+	defer poolLock.Unlock()
+	delete(pools, p.Key())
+}
+
+func selectPools(controller, name string) []*pool {
+	poolLock.Lock()
+	defer poolLock.Unlock()
+	selected := []*pool{}
+	for _, p := range pools {
+		if (controller == "" || p.controller.GetName() == controller || p.controller.GetInstanceName() == controller) && (name == "" || p.name == name) {
+			selected = append(selected, p)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Key() < selected[j].Key() })
+	return selected
+}
+
+// Pools is a HTTP handler listing the running pools (GET) or changing
+// their size and rate limits (POST). The query parameters controller and
+// pool restrict the selected pools. The parameter size sets the number of
+// workers, the parameters base-delay, max-delay, qps and burst the rate
+// limits. Rate limit parameters not given are taken from the defaults.
+func Pools(w http.ResponseWriter, r *http.Request) {
+	selected := selectPools(r.FormValue("controller"), r.FormValue("pool"))
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	switch r.Method {
+	case http.MethodGet:
+		for _, p := range selected {
+			fmt.Fprintf(w, "%s: size %d, active workers %d, busy workers %d, rate limiter %s\n",
+				p.Key(), p.Size(), p.ActiveWorkers(), p.BusyWorkers(), p.RateLimits())
+		}
+	case http.MethodPost:
+		if len(selected) == 0 {
+			http.Error(w, "no pool selected", http.StatusNotFound)
+			return
+		}
+		size, limits, err := tuningParams(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, p := range selected {
+			if size > 0 {
+				p.SetSize(size)
+			}
+			if limits != nil {
+				p.SetRateLimits(limits.baseDelay, limits.maxDelay, limits.qps, limits.burst)
+			}
+		}
+		io.WriteString(w, fmt.Sprintf("%d pools updated\n", len(selected)))
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// tuningParams parses the pool size (0 if not given) and the rate limits
+// (nil if not given) of a tuning request.
+func tuningParams(r *http.Request) (int, *ratelimiterdef, error) {
+	size, err := intParam(r, "size", 0)
+	if err != nil {
+		return 0, nil, err
+	}
+	given := false
+	for _, n := range []string{"base-delay", "max-delay", "qps", "burst"} {
+		if r.FormValue(n) != "" {
+			given = true
+		}
+	}
+	if !given {
+		return size, nil, nil
+	}
+	limits := *defaultRateLimiter
+	if limits.baseDelay, err = durationParam(r, "base-delay", limits.baseDelay); err != nil {
+		return 0, nil, err
+	}
+	if limits.maxDelay, err = durationParam(r, "max-delay", limits.maxDelay); err != nil {
+		return 0, nil, err
+	}
+	if limits.qps, err = intParam(r, "qps", limits.qps); err != nil {
+		return 0, nil, err
+	}
+	if limits.burst, err = intParam(r, "burst", limits.burst); err != nil {
+		return 0, nil, err
+	}
+	if _, err = NewRateLimiter(limits.baseDelay, limits.maxDelay, limits.qps, limits.burst); err != nil {
+		return 0, nil, err
+	}
+	return size, &limits, nil
+}
+
+func intParam(r *http.Request, name string, def int) (int, error) {
+	v := r.FormValue(name)
+	if v == "" {
+		return def, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, v)
+	}
+	return i, nil
+}
+
+func durationParam(r *http.Request, name string, def time.Duration) (time.Duration, error) {
+	v := r.FormValue(name)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, v)
+	}
+	return d, nil
+}
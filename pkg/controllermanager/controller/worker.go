@@ -22,11 +22,13 @@ import (
 	"github.com/gardener/controller-manager-library/pkg/controllermanager/controller/reconcile"
 	"github.com/gardener/controller-manager-library/pkg/ctxutil"
 	"github.com/gardener/controller-manager-library/pkg/logger"
+	"github.com/gardener/controller-manager-library/pkg/resources"
 	"github.com/gardener/controller-manager-library/pkg/server/healthz"
+	"runtime/debug"
 	"strconv"
+	"sync/atomic"
 	"time"
 
-	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/util/workqueue"
 )
@@ -41,8 +43,10 @@ type worker struct {
 	logger.LogContext
 
 	ctx        context.Context
+	rctx       context.Context
 	logContext logger.LogContext
 	pool       *pool
+	number     int
 	workqueue  workqueue.RateLimitingInterface
 }
 
@@ -52,20 +56,39 @@ func newWorker(p *pool, number int) *worker {
 	return &worker{
 		LogContext: lgr,
 
-		ctx:        p.ctx,
+		ctx:        p.rctx,
 		logContext: lgr,
 		pool:       p,
+		number:     number,
 		workqueue:  p.workqueue,
 	}
 }
 
 func (w *worker) Run() {
 	w.Infof("starting worker")
+	atomic.AddInt32(&w.pool.active, 1)
+	defer atomic.AddInt32(&w.pool.active, -1)
 	for w.processNextWorkItem() {
+		if w.pool.ctx.Err() != nil {
+			// on shutdown only finish the in-flight item, queued items are dropped
+			break
+		}
+		if w.pool.retireWorker(w.number) {
+			w.Infof("pool size reduced")
+			break
+		}
 	}
 	w.Infof("exit worker")
 }
 
+// Context returns the context of the actual reconcilation.
+func (w *worker) Context() context.Context {
+	if w.rctx != nil {
+		return w.rctx
+	}
+	return w.ctx
+}
+
 func (w *worker) internalErr(obj interface{}, err error) bool {
 	w.Error(err)
 	w.workqueue.Forget(obj)
@@ -73,7 +96,7 @@ func (w *worker) internalErr(obj interface{}, err error) bool {
 }
 
 func (w *worker) loggerForKey(key string) func() {
-	w.LogContext = w.logContext.NewContext("resources", key)
+	w.LogContext = w.logContext.NewContext("object", key)
 	return func() { w.LogContext = w.logContext }
 }
 
@@ -83,6 +106,8 @@ func (w *worker) processNextWorkItem() bool {
 		return false
 	}
 	w.Debugf("GOT: %s", obj)
+	atomic.AddInt32(&w.pool.busy, 1)
+	defer atomic.AddInt32(&w.pool.busy, -1)
 	defer w.workqueue.Done(obj)
 	defer w.Debugf("DONE %s", obj)
 	healthz.Tick(w.pool.Key())
@@ -93,6 +118,9 @@ func (w *worker) processNextWorkItem() bool {
 	}
 
 	defer w.loggerForKey(key)()
+	if key != tickCmd {
+		defer w.pool.observeReconcile(time.Now())
+	}
 
 	cmd, rkey, r, err := w.pool.controller.DecodeKey(key)
 
@@ -100,6 +128,8 @@ func (w *worker) processNextWorkItem() bool {
 		// The resources may no longer exist, in which case we stop processing.
 		if !errors.IsNotFound(err) {
 			w.Errorf("error syncing '%s': %s", key, err)
+			w.pool.countError()
+			w.pool.countRequeue()
 			w.workqueue.AddRateLimited(key)
 			return true
 		}
@@ -112,7 +142,7 @@ func (w *worker) processNextWorkItem() bool {
 		reconcilers := w.pool.getReconcilers(cmd)
 		if reconcilers != nil && len(reconcilers) > 0 {
 			for _, reconciler := range reconcilers {
-				status := reconciler.Command(w, cmd)
+				status := w.protect(key, func() reconcile.Status { return reconciler.Command(w, cmd) })
 				if !status.Completed {
 					ok = false
 				}
@@ -158,14 +188,14 @@ func (w *worker) processNextWorkItem() bool {
 		}
 
 		for _, reconciler := range reconcilers {
-			status := f(reconciler)
+			status := w.protect(key, func() reconcile.Status { return f(reconciler) })
 			if !status.Completed {
 				ok = false
 			}
 			if status.Error != nil {
 				err = status.Error
-				if ok && r != nil {
-					r.Eventf(corev1.EventTypeWarning, "sync", "%s", err.Error())
+				if ok && r != nil && !reconcile.IsPermanent(err) {
+					w.pool.controller.events.WarningEventf(r, "sync", "%s", err.Error())
 				}
 			}
 			if status.Interval >= 0 {
@@ -176,13 +206,22 @@ func (w *worker) processNextWorkItem() bool {
 
 	}
 	if err != nil {
+		w.pool.countError()
+		if !reconcile.IsPermanent(err) && w.deadLetter(key, r, err) {
+			return true
+		}
+		if w.requeueClassified(obj, r, err) {
+			return true
+		}
 		if ok {
+			w.pool.countRequeue()
 			w.Warnf("add rate limited because of problem: %s", err)
 			// valid resources, but resources not ready yet (required state for reconciliation/deletion not yet) reached, re-add to the queue rate-limited
 			w.workqueue.AddRateLimited(obj)
 		} else {
 			// invalid resources (not suitable for controller)
 			if reschedule > 0 {
+				w.pool.countRequeue()
 				w.Infof("request reschedule %q after %d seconds", obj, reschedule/time.Second)
 				w.workqueue.AddAfter(obj, reschedule)
 			} else {
@@ -192,6 +231,7 @@ func (w *worker) processNextWorkItem() bool {
 	} else {
 		if ok {
 			// valid resources, everything ok, just continue normally
+			w.pool.deadletters.succeeded(key)
 			w.workqueue.Forget(obj)
 			if reschedule < 0 || (w.pool.Period() > 0 && w.pool.Period() < reschedule) {
 				if !deleted {
@@ -215,6 +255,7 @@ func (w *worker) processNextWorkItem() bool {
 			}
 		} else {
 			// valid resources, but reconciliation failed temporarily, just re-add to the queue
+			w.pool.countRequeue()
 			w.Infof("redo reconcile %q", obj)
 			w.workqueue.Add(obj)
 		}
@@ -222,6 +263,97 @@ func (w *worker) processNextWorkItem() bool {
 	return true
 }
 
+// deadLetter records a failure for the item and requeues it with the
+// retry period of the dead letters, if it has failed too often.
+func (w *worker) deadLetter(key string, r resources.Object, err error) bool {
+	dead, moved := w.pool.deadletters.failed(key)
+	if !dead {
+		return false
+	}
+	retry := w.pool.deadletters.retryPeriod()
+	if moved {
+		w.pool.countDeadLetter()
+		w.Errorf("%q failed %d consecutive times: moved to dead letters (retry period %s): %s",
+			key, w.pool.deadletters.threshold, retry, err)
+		if r != nil {
+			w.pool.controller.events.WarningEventf(r, "deadletter",
+				"reconcilation failed %d consecutive times, retrying every %s: %s", w.pool.deadletters.threshold, retry, err)
+		}
+	} else {
+		w.Warnf("dead letter %q still failing, retry after %s: %s", key, retry, err)
+	}
+	w.workqueue.Forget(key)
+	w.workqueue.AddAfter(key, retry)
+	return true
+}
+
+// requeueClassified handles the requeue of an item for classified reconcile
+// errors. It returns false for unclassified errors.
+func (w *worker) requeueClassified(obj interface{}, r resources.Object, err error) bool {
+	switch {
+	case reconcile.IsPermanent(err):
+		w.pool.countPermanentError()
+		w.Errorf("permanent problem, wait for new change: %s", err)
+		w.workqueue.Forget(obj)
+		if r != nil {
+			w.pool.controller.events.WarningEventf(r, "permanent", "%s", err.Error())
+		}
+	case reconcile.IsConflict(err):
+		w.pool.countRequeue()
+		w.Infof("redo reconcile %q because of conflict: %s", obj, err)
+		w.workqueue.Add(obj)
+	default:
+		after, ok := reconcile.RequeueAfter(err)
+		if !ok {
+			return false
+		}
+		w.pool.countRequeue()
+		w.Warnf("transient problem, requeue %q after %s: %s", obj, after, err)
+		w.workqueue.Forget(obj)
+		w.workqueue.AddAfter(obj, after)
+	}
+	return true
+}
+
+// protect calls a reconciler and converts a panic or an exceeded
+// reconcile deadline into a delayed status, which requeues the item
+// rate limited.
+func (w *worker) protect(key string, f func() reconcile.Status) (status reconcile.Status) {
+	timeout := w.pool.timeout
+	if timeout > 0 {
+		ctx, cancel := context.WithTimeout(w.ctx, timeout)
+		done := make(chan struct{})
+		w.rctx = ctx
+		lgr := w.LogContext
+		go func() {
+			select {
+			case <-done:
+			case <-ctx.Done():
+				if ctx.Err() == context.DeadlineExceeded {
+					reconcileTimeouts.WithLabelValues(w.pool.controller.GetInstanceName(), w.pool.name).Inc()
+					lgr.Warnf("reconcilation of %q exceeded deadline %s: cancelling", key, timeout)
+				}
+			}
+		}()
+		defer func() {
+			close(done)
+			cancel()
+			w.rctx = nil
+			if ctx.Err() == context.DeadlineExceeded && status.Error == nil {
+				status = reconcile.Status{Completed: true, Error: fmt.Errorf("reconcilation exceeded deadline %s", timeout), Interval: -1}
+			}
+		}()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			reconcilePanics.WithLabelValues(w.pool.controller.GetInstanceName(), w.pool.name).Inc()
+			w.Errorf("panic during reconcilation of %q: %v\n%s", key, r, debug.Stack())
+			status = reconcile.Status{Completed: true, Error: fmt.Errorf("reconciler panicked: %v", r), Interval: -1}
+		}
+	}()
+	return f()
+}
+
 func updateSchedule(reschedule *time.Duration, interval time.Duration) {
 	if interval >= 0 && (*reschedule <= 0 || interval < *reschedule) {
 		*reschedule = interval
@@ -35,6 +35,8 @@ import (
 	"github.com/gardener/controller-manager-library/pkg/ctxutil"
 	"github.com/gardener/controller-manager-library/pkg/logger"
 	"github.com/gardener/controller-manager-library/pkg/server"
+	"github.com/gardener/controller-manager-library/pkg/server/debug"
+	_ "github.com/gardener/controller-manager-library/pkg/server/readyz"
 )
 
 type ControllerManager struct {
@@ -45,9 +47,11 @@ type ControllerManager struct {
 	definition *Definition
 
 	ctx           context.Context
+	leaseCtx      context.Context
 	config        *config.Config
 	clusters      cluster.Clusters
 	registrations controller.Registrations
+	controllers   map[string]Controller
 	plain_groups  map[string]StartupGroup
 	lease_groups  map[string]StartupGroup
 	//shared_options map[string]*config.ArbitraryOption
@@ -60,6 +64,8 @@ type Controller interface {
 	Owning() controller.ResourceKey
 	GetDefinition() controller.Definition
 	GetClusterHandler(name string) (*controller.ClusterHandler, error)
+	GetCluster(name string) cluster.Interface
+	IsReady() bool
 
 	Check() error
 	Prepare() error
@@ -76,6 +82,11 @@ func NewControllerManager(ctx context.Context, def *Definition) (*ControllerMana
 				return nil, fmt.Errorf("controller %q requires controller %q, which is not declared", n, r)
 			}
 		}
+		for _, r := range def.controller_defs.Get(n).After() {
+			if def.controller_defs.Get(r) == nil {
+				return nil, fmt.Errorf("controller %q should be started after controller %q, which is not declared", n, r)
+			}
+		}
 	}
 
 	if config.NamespaceRestriction && config.DisableNamespaceRestriction {
@@ -154,6 +165,10 @@ func NewControllerManager(ctx context.Context, def *Definition) (*ControllerMana
 	if len(registrations) == 0 {
 		return nil, fmt.Errorf("no controller activated")
 	}
+	err = checkStartupOrder(registrations)
+	if err != nil {
+		return nil, err
+	}
 
 	set, err := def.ControllerDefinitions().DetermineRequestedClusters(def.ClusterDefinitions(), registrations.Names())
 	if err != nil {
@@ -177,6 +192,7 @@ func NewControllerManager(ctx context.Context, def *Definition) (*ControllerMana
 		config:        config,
 		registrations: registrations,
 
+		controllers:  map[string]Controller{},
 		plain_groups: map[string]StartupGroup{},
 		lease_groups: map[string]StartupGroup{},
 	}
@@ -184,6 +200,8 @@ func NewControllerManager(ctx context.Context, def *Definition) (*ControllerMana
 	ctx = logger.Set(ctxutil.SyncContext(ctx), lgr)
 	ctx = context.WithValue(ctx, cmkey, cm)
 	cm.ctx = ctx
+	// leases are released only after the controllers have been shut down
+	cm.leaseCtx = ctxutil.SyncContext(ctxutil.CancelContext(ctxutil.Detached(ctx)))
 	return cm, nil
 }
 
@@ -213,7 +231,19 @@ func (c *ControllerManager) Run() error {
 	if c.config.ServerPortHTTP > 0 {
 		server.Serve(c.ctx, "", c.config.ServerPortHTTP)
 	}
+	if c.config.DebugPort > 0 {
+		if err := c.serveDebug(); err != nil {
+			return err
+		}
+	}
+	if c.config.LogLevelConfigMap != "" {
+		if err := c.watchLogLevels(); err != nil {
+			return err
+		}
+	}
 
+	dynamic := map[string]controller.Registrations{}
+	started := []Controller{}
 	for _, def := range c.registrations {
 		lines := strings.Split(def.String(), "\n")
 		c.Infof("creating %s", lines[0])
@@ -224,26 +254,91 @@ func (c *ControllerManager) Run() error {
 		if err != nil {
 			return err
 		}
+		dyn, err := c.dynamicClusterFor(def, cmp)
+		if err != nil {
+			return err
+		}
+		if dyn != "" {
+			c.Infof("controller %q is started for every cluster provided for %q", def.GetName(), dyn)
+			if dynamic[dyn] == nil {
+				dynamic[dyn] = controller.Registrations{}
+			}
+			dynamic[dyn][def.GetName()] = def
+			continue
+		}
 		cntr, err := controller.NewController(c, def, cmp)
 		if err != nil {
 			return err
 		}
+		c.lock.Lock()
+		c.controllers[def.GetName()] = cntr
+		c.lock.Unlock()
+		started = append(started, cntr)
 
-		if def.RequireLease() {
-			c.getLeaseStartupGroup(cntr.GetMainCluster()).Add(cntr)
+		lease, omit, err := c.definition.Groups().LeaseFor(def.GetName())
+		if err != nil {
+			return err
+		}
+		if def.RequireLease() && !omit {
+			c.getLeaseStartupGroup(cntr.GetMainCluster(), lease).Add(cntr)
 		} else {
+			if def.RequireLease() {
+				c.Infof("controller %q runs without lease according to its group", def.GetName())
+			}
 			c.getPlainStartupGroup(cntr.GetMainCluster()).Add(cntr)
 		}
 	}
 
-	err := c.startGroups(c.plain_groups, c.lease_groups)
+	for name, registrations := range dynamic {
+		// the registry uses the lease of the controllers it starts
+		requireLease := false
+		lease := ""
+		for n, def := range registrations {
+			l, omit, err := c.definition.Groups().LeaseFor(n)
+			if err != nil {
+				return err
+			}
+			if def.RequireLease() && !omit {
+				if requireLease && l != lease {
+					return fmt.Errorf("controllers for dynamic cluster %q require different leases (%q and %q)", name, lease, l)
+				}
+				requireLease = true
+				lease = l
+			}
+		}
+		cdef := c.definition.ClusterDefinitions().Get(name)
+		cntr, err := c.newClusterRegistry(cdef, registrations, requireLease)
+		if err != nil {
+			return err
+		}
+		started = append(started, cntr)
+		main := c.clusters.GetCluster(cdef.DynamicSource())
+		if requireLease {
+			c.getLeaseStartupGroup(main, lease).Add(cntr)
+		} else {
+			c.getPlainStartupGroup(main).Add(cntr)
+		}
+	}
+
+	if err := c.runHooks("pre start", c.definition.PreStartHooks()); err != nil {
+		return err
+	}
+	// lease groups start asynchronously, so plain controllers may wait for them
+	err := c.startGroups(c.lease_groups, c.plain_groups)
 	if err != nil {
 		return err
 	}
+	go c.runPostStartHooks(started)
 
 	<-c.ctx.Done()
 	c.Info("waiting for controllers to shutdown")
-	ctxutil.SyncPointWait(c.ctx, 120*time.Second)
+	ctxutil.SyncPointWait(c.ctx, c.config.ShutdownGracePeriod+20*time.Second)
+	if err := c.runHooks("pre stop", c.definition.PreStopHooks()); err != nil {
+		c.Errorf("%s", err)
+	}
+	c.Info("releasing leases")
+	ctxutil.Cancel(c.leaseCtx)
+	ctxutil.SyncPointWait(c.leaseCtx, 10*time.Second)
 	c.Info("exit controller manager")
 	return nil
 }
@@ -259,11 +354,38 @@ func (c *ControllerManager) checkController(cntr Controller) error {
 // in checkController, so after a successful checkController
 // startController MUST not return an error.
 func (c *ControllerManager) startController(cntr Controller) error {
-	err := cntr.Prepare()
+	err := c.prepareController(cntr)
 	if err != nil {
 		return err
 	}
+	c.runController(cntr)
+	return nil
+}
+
+// prepareController sets up the reconcilers and watches of a controller.
+// Events are queued but not processed before the controller is run.
+func (c *ControllerManager) prepareController(cntr Controller) error {
+	return cntr.Prepare()
+}
 
+// runController starts the worker pools of a prepared controller.
+func (c *ControllerManager) runController(cntr Controller) {
 	ctxutil.SyncPointRunAndCancelOnExit(c.ctx, cntr.Run)
-	return nil
+}
+
+// serveDebug starts the debug server. It is kept running during the
+// shutdown of the controllers to be able to diagnose a stuck shutdown.
+func (c *ControllerManager) serveDebug() error {
+	token := ""
+	if c.config.DebugTokenFile != "" {
+		data, err := ioutil.ReadFile(c.config.DebugTokenFile)
+		if err != nil {
+			return fmt.Errorf("cannot read debug token file: %s", err)
+		}
+		token = strings.TrimSpace(string(data))
+		if token == "" {
+			return fmt.Errorf("debug token file %q is empty", c.config.DebugTokenFile)
+		}
+	}
+	return debug.Serve(c.leaseCtx, c.config.DebugBindAddress, c.config.DebugPort, token)
 }
@@ -29,6 +29,10 @@ type Definition struct {
 	description     string
 	cluster_defs    cluster.Definitions
 	controller_defs controller.Definitions
+	leaseLost       []LeadershipLostHandler
+	preStart        []LifecycleHook
+	postStart       []LifecycleHook
+	preStop         []LifecycleHook
 }
 
 func (this *Definition) GetName() string {
@@ -59,6 +63,22 @@ func (this *Definition) GetMappingsFor(name string) (mappings.Definition, error)
 	return this.controller_defs.GetMappingsFor(name)
 }
 
+func (this *Definition) LeadershipLostHandlers() []LeadershipLostHandler {
+	return this.leaseLost
+}
+
+func (this *Definition) PreStartHooks() []LifecycleHook {
+	return this.preStart
+}
+
+func (this *Definition) PostStartHooks() []LifecycleHook {
+	return this.postStart
+}
+
+func (this *Definition) PreStopHooks() []LifecycleHook {
+	return this.preStop
+}
+
 func (this *Definition) ExtendConfig(cfg *config.Config) {
 	this.cluster_defs.ExtendConfig(cfg)
 	this.controller_defs.ExtendConfig(cfg)
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package controllermanager
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/controller"
+	"github.com/gardener/controller-manager-library/pkg/resources"
+)
+
+// KeyEnqueuer is used by other subsystems (like webhook handlers or
+// message consumers) to trigger the reconcilation of dedicated objects
+// for out-of-band signals.
+type KeyEnqueuer interface {
+	// EnqueueKey enqueues an object given by its logical cluster name,
+	// resource type, namespace and name.
+	EnqueueKey(cluster string, gvk schema.GroupVersionKind, namespace, name string) error
+}
+
+var _ KeyEnqueuer = &ControllerManager{}
+
+// GetKeyEnqueuer returns the KeyEnqueuer of the controller manager
+// the given context belongs to.
+func GetKeyEnqueuer(ctx context.Context) KeyEnqueuer {
+	return Get(ctx)
+}
+
+// keyEnqueuingController is implemented by the controllers able to
+// handle external keys.
+type keyEnqueuingController interface {
+	Controller
+	IsWatching(cluster string, key controller.ResourceKey) bool
+	EnqueueKey(key resources.ClusterObjectKey) error
+}
+
+// EnqueueKey enqueues the object for all controllers running in this
+// process, that watch its resource type on the given cluster. The version
+// of the resource type is ignored. It fails if there is no such controller,
+// for example because the lease is held by another controller manager.
+func (c *ControllerManager) EnqueueKey(cluster string, gvk schema.GroupVersionKind, namespace, name string) error {
+	cl := c.clusters.GetCluster(cluster)
+	if cl == nil {
+		return fmt.Errorf("unknown cluster %q", cluster)
+	}
+	gk := gvk.GroupKind()
+	rk := controller.NewResourceKey(gk.Group, gk.Kind)
+	key := resources.NewClusterKey(cl.GetId(), gk, namespace, name)
+
+	c.lock.Lock()
+	cntrs := make([]Controller, 0, len(c.controllers))
+	for _, cntr := range c.controllers {
+		cntrs = append(cntrs, cntr)
+	}
+	c.lock.Unlock()
+
+	found := false
+	for _, cntr := range cntrs {
+		e, ok := cntr.(keyEnqueuingController)
+		if !ok || !e.IsReady() || !e.IsWatching(cl.GetName(), rk) {
+			continue
+		}
+		if err := e.EnqueueKey(key); err != nil {
+			return fmt.Errorf("controller %q: %s", e.GetName(), err)
+		}
+		found = true
+	}
+	if !found {
+		return fmt.Errorf("no running controller watches %s in cluster %q", rk, cluster)
+	}
+	return nil
+}
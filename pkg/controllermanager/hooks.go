@@ -0,0 +1,71 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package controllermanager
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/controller"
+	"github.com/gardener/controller-manager-library/pkg/ctxutil"
+)
+
+// LifecycleHook is called by the controller manager at dedicated points
+// of its lifecycle.
+type LifecycleHook func(env controller.Environment) error
+
+// runHooks calls the given lifecycle hooks in the order of their
+// registration and stops at the first failing one.
+func (c *ControllerManager) runHooks(kind string, hooks []LifecycleHook) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+	c.Infof("running %d %s hook(s)", len(hooks), kind)
+	for i, h := range hooks {
+		if err := h(c); err != nil {
+			return fmt.Errorf("%s hook %d failed: %s", kind, i+1, err)
+		}
+	}
+	return nil
+}
+
+// runPostStartHooks calls the post start hooks as soon as all given
+// controllers are running, which for controllers requiring a lease
+// is after the leadership has been acquired.
+func (c *ControllerManager) runPostStartHooks(cntrs []Controller) {
+	hooks := c.definition.PostStartHooks()
+	if len(hooks) == 0 {
+		return
+	}
+	err := wait.PollImmediateUntil(time.Second, func() (bool, error) {
+		for _, cntr := range cntrs {
+			if !cntr.IsReady() {
+				return false, nil
+			}
+		}
+		return true, nil
+	}, c.ctx.Done())
+	if err != nil {
+		return
+	}
+	if err := c.runHooks("post start", hooks); err != nil {
+		c.Errorf("%s", err)
+		ctxutil.Cancel(c.ctx)
+	}
+}
@@ -23,20 +23,32 @@ import (
 	"time"
 
 	"github.com/gardener/controller-manager-library/pkg/controllermanager/cluster"
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/config"
 	"github.com/gardener/controller-manager-library/pkg/ctxutil"
+	"github.com/gardener/controller-manager-library/pkg/server/healthz"
 
 	k8s "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 )
 
+// LeadershipLostHandler is called with the name of the cluster and
+// the name of the lease the leadership has been lost for.
+type LeadershipLostHandler func(cluster, lease string)
+
 type leasestartupgroup struct {
 	startupgroup
+	name string
 }
 
 func (g *leasestartupgroup) Startup() error {
+	g.controllers = orderControllers(g.controllers)
 	for _, c := range g.controllers {
-		err := g.manager.checkController(c)
+		err := g.manager.waitForCRDs(c)
+		if err != nil {
+			return err
+		}
+		err = g.manager.checkController(c)
 		if err != nil {
 			return err
 		}
@@ -54,47 +66,101 @@ func (g *leasestartupgroup) Startup() error {
 	}
 	msg += ")"
 
+	standby := g.manager.GetConfig().LeaseWarmStandby && !g.manager.GetConfig().OmitLease
+	if standby {
+		g.manager.Infof("warm standby: preparing controllers for %s", msg)
+		for _, c := range g.controllers {
+			err := g.manager.prepareController(c)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	runit := func() {
 		g.manager.Infof("Acquired leadership, starting controllers for %s.", msg)
 		for _, c := range g.controllers {
-			g.manager.startController(c)
+			if err := g.manager.waitForControllers(c); err != nil {
+				g.manager.Errorf("%s", err)
+				ctxutil.Cancel(g.manager.ctx)
+				return
+			}
+			if standby {
+				g.manager.runController(c)
+			} else if err := g.manager.startController(c); err != nil {
+				g.manager.Errorf("%s", err)
+				ctxutil.Cancel(g.manager.ctx)
+				return
+			}
 		}
 	}
 
 	if g.manager.GetConfig().OmitLease {
 		g.manager.Infof("omitting lease %q for cluster %s in namespace %q",
-			g.manager.GetName(), msg, g.manager.GetConfig().Namespace)
+			g.name, msg, g.manager.GetConfig().Namespace)
 		ctxutil.SyncPointRun(g.manager.ctx, runit)
 	} else {
 		g.manager.Infof("requesting lease %q for cluster %s in namespace %q",
-			g.manager.GetName(), msg, g.manager.GetConfig().Namespace)
-		leaderElectionConfig, err := makeLeaderElectionConfig(g.cluster,
-			g.manager.GetConfig().Namespace, g.manager.GetName())
+			g.name, msg, g.manager.GetConfig().Namespace)
+		leaderElectionConfig, err := makeLeaderElectionConfig(g.cluster, g.manager.GetConfig(), g.name)
 		if err != nil {
 			return err
 		}
+		g.manager.Infof("using %s lock with identity %q (lease duration %s, renew deadline %s, retry period %s)",
+			leaderElectionConfig.Lock.Describe(), leaderElectionConfig.Lock.Identity(),
+			leaderElectionConfig.LeaseDuration, leaderElectionConfig.RenewDeadline, leaderElectionConfig.RetryPeriod)
 
 		leaderElectionConfig.Callbacks = leaderelection.LeaderCallbacks{
-			OnStartedLeading: func(ctx context.Context) { runit() },
+			OnStartedLeading: func(ctx context.Context) {
+				if g.manager.ctx.Err() == nil {
+					runit()
+				}
+			},
 			OnStoppedLeading: func() {
-				g.manager.Infof("Lost leadership, cleaning up %s.", msg)
+				if g.manager.ctx.Err() != nil {
+					g.manager.Infof("Released leadership for %s.", msg)
+					return
+				}
+				g.manager.Errorf("Lost leadership, shutting down controllers for %s.", msg)
+				for _, h := range g.manager.definition.LeadershipLostHandlers() {
+					h(g.cluster.GetName(), g.name)
+				}
+				ctxutil.Cancel(g.manager.ctx)
 			},
 		}
+		// the health check fails if the lease is held but could not be renewed
+		watchdog := leaderelection.NewLeaderHealthzAdaptor(leaderElectionConfig.LeaseDuration)
+		leaderElectionConfig.WatchDog = watchdog
 		leaderElector, err := leaderelection.NewLeaderElector(*leaderElectionConfig)
 		if err != nil {
 			return fmt.Errorf("couldn't create leader elector: %v", err)
 		}
-		ctxutil.SyncPointRun(g.manager.ctx, func() { leaderElector.Run(g.manager.ctx) })
+		key := fmt.Sprintf("lease:%s/cluster:%s", g.name, g.cluster.GetName())
+		healthz.Register(key, func() error { return watchdog.Check(nil) })
+		ctxutil.SyncPointRun(g.manager.leaseCtx, func() {
+			defer healthz.Unregister(key)
+			leaderElector.Run(g.manager.leaseCtx)
+		})
 	}
 
 	return nil
 }
 
-func makeLeaderElectionConfig(cluster cluster.Interface, namespace, name string) (*leaderelection.LeaderElectionConfig, error) {
-	hostname, err := os.Hostname()
-	hostname = fmt.Sprintf("%s/%d", hostname, os.Getpid())
-	if err != nil {
-		return nil, fmt.Errorf("unable to get hostname: %v", err)
+func makeLeaderElectionConfig(cluster cluster.Interface, config *config.Config, name string) (*leaderelection.LeaderElectionConfig, error) {
+	identity := config.LeaseIdentity
+	if identity == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("unable to get hostname: %v", err)
+		}
+		identity = fmt.Sprintf("%s/%d", hostname, os.Getpid())
+	}
+	// the default is kept at configmaps: the used client version offers
+	// no multi lock, so replicas using different lock types would both
+	// become leader during a rolling update
+	lockType := config.LeaseResourceLock
+	if lockType == "" {
+		lockType = resourcelock.ConfigMapsResourceLock
 	}
 
 	cfg := cluster.Config()
@@ -103,13 +169,13 @@ func makeLeaderElectionConfig(cluster cluster.Interface, namespace, name string)
 		return nil, err
 	}
 	lock, err := resourcelock.New(
-		"configmaps",
-		namespace,
+		lockType,
+		config.Namespace,
 		name,
 		client.CoreV1(),
 		client.CoordinationV1(),
 		resourcelock.ResourceLockConfig{
-			Identity:      hostname,
+			Identity:      identity,
 			EventRecorder: cluster.Resources(),
 		},
 	)
@@ -118,9 +184,18 @@ func makeLeaderElectionConfig(cluster cluster.Interface, namespace, name string)
 	}
 
 	return &leaderelection.LeaderElectionConfig{
-		Lock:          lock,
-		LeaseDuration: 15 * time.Second,
-		RenewDeadline: 10 * time.Second,
-		RetryPeriod:   2 * time.Second,
+		Lock:            lock,
+		LeaseDuration:   durationOrDefault(config.LeaseDuration, 15*time.Second),
+		RenewDeadline:   durationOrDefault(config.LeaseRenewDeadline, 10*time.Second),
+		RetryPeriod:     durationOrDefault(config.LeaseRetryPeriod, 2*time.Second),
+		ReleaseOnCancel: true,
+		Name:            name,
 	}, nil
 }
+
+func durationOrDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package controllermanager
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/cluster"
+	"github.com/gardener/controller-manager-library/pkg/logger"
+	"github.com/gardener/controller-manager-library/pkg/resources"
+)
+
+// watchLogLevels watches the config map with the log levels of the log
+// level domains (the controllers) on the default cluster. The keys of the
+// config map are the domain names, the values the log levels. Domains
+// removed from the config map are reset to the global log level.
+func (c *ControllerManager) watchLogLevels() error {
+	parts := strings.Split(c.config.LogLevelConfigMap, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("invalid log level config map %q (expected <namespace>/<name>)", c.config.LogLevelConfigMap)
+	}
+	cl := c.clusters.GetCluster(cluster.DEFAULT)
+	if cl == nil {
+		return fmt.Errorf("log level config map requires the default cluster")
+	}
+	r, err := cl.GetResource(schema.GroupKind{Group: corev1.GroupName, Kind: "ConfigMap"})
+	if err != nil {
+		return err
+	}
+
+	// the handlers are called sequentially by the informer
+	set := map[string]bool{}
+	update := func(obj resources.Object) {
+		levels := map[string]logger.Level{}
+		if obj != nil {
+			if cm, ok := obj.Data().(*corev1.ConfigMap); ok {
+				for n, v := range cm.Data {
+					l, err := logger.ParseLevel(strings.TrimSpace(v))
+					if err != nil {
+						c.Warnf("log level config map %s: domain %s: %s", c.config.LogLevelConfigMap, n, err)
+						continue
+					}
+					levels[n] = l
+				}
+			}
+		}
+		for n := range set {
+			if _, ok := levels[n]; !ok {
+				logger.ResetDomainLevel(n)
+				delete(set, n)
+			}
+		}
+		for n, l := range levels {
+			logger.SetDomainLevel(n, l)
+			set[n] = true
+		}
+	}
+	handlers := resources.ResourceEventHandlerFuncs{
+		AddFunc: func(obj resources.Object) {
+			update(obj)
+		},
+		UpdateFunc: func(old, new resources.Object) {
+			update(new)
+		},
+		DeleteFunc: func(obj resources.Object) {
+			update(nil)
+		},
+	}
+	c.Infof("watching log levels in config map %s", c.config.LogLevelConfigMap)
+	_, err = r.AddDedicatedEventHandler(c.ctx, handlers, parts[0], func(opts *metav1.ListOptions) {
+		opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", parts[1]).String()
+	})
+	return err
+}
@@ -129,9 +129,16 @@ func run(ctx context.Context, def *Definition) error {
 	var err error
 	var controllerManager *ControllerManager
 
+	cfg := config.Get(ctx)
+	if cfg.LogFormat != "" {
+		if err := logger.SetFormat(cfg.LogFormat); err != nil {
+			ctxutil.Cancel(ctx)
+			return err
+		}
+	}
+
 	logger.Infof("starting controller manager")
 
-	cfg := config.Get(ctx)
 	if cfg.CPUProfile != "" {
 		f, err := os.Create(cfg.CPUProfile)
 		if err != nil {
@@ -17,7 +17,17 @@
 package controllermanager
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/gardener/controller-manager-library/pkg/controllermanager/cluster"
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/controller"
+	"github.com/gardener/controller-manager-library/pkg/resources/apiextensions"
+	"github.com/gardener/controller-manager-library/pkg/utils"
+
+	"k8s.io/apimachinery/pkg/util/wait"
 )
 
 type StartupGroup interface {
@@ -36,8 +46,12 @@ func (this *startupgroup) Add(c Controller) {
 }
 
 func (this *startupgroup) Startup() error {
-	for _, c := range this.controllers {
-		err := this.manager.startController(c)
+	for _, c := range orderControllers(this.controllers) {
+		err := this.manager.waitForDependencies(c)
+		if err != nil {
+			return err
+		}
+		err = this.manager.startController(c)
 		if err != nil {
 			return err
 		}
@@ -56,11 +70,16 @@ func (c *ControllerManager) getPlainStartupGroup(cluster cluster.Interface) Star
 	return g
 }
 
-func (c *ControllerManager) getLeaseStartupGroup(cluster cluster.Interface) StartupGroup {
-	g := c.lease_groups[cluster.GetName()]
+func (c *ControllerManager) getLeaseStartupGroup(cluster cluster.Interface, lease string) StartupGroup {
+	name := c.GetName()
+	if lease != "" {
+		name = fmt.Sprintf("%s-%s", name, lease)
+	}
+	key := cluster.GetName() + "/" + name
+	g := c.lease_groups[key]
 	if g == nil {
-		g = &leasestartupgroup{startupgroup{c, cluster, nil}}
-		c.lease_groups[cluster.GetName()] = g
+		g = &leasestartupgroup{startupgroup{c, cluster, nil}, name}
+		c.lease_groups[key] = g
 	}
 	return g
 }
@@ -76,3 +95,126 @@ func (c *ControllerManager) startGroups(grps ...map[string]StartupGroup) error {
 	}
 	return nil
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// startup dependencies
+
+// checkStartupOrder checks the startup dependencies of the activated
+// controllers for cycles.
+func checkStartupOrder(registrations controller.Registrations) error {
+	done := utils.StringSet{}
+	var visit func(path []string, name string) error
+	visit = func(path []string, name string) error {
+		for i, n := range path {
+			if n == name {
+				return fmt.Errorf("cyclic startup dependency: %s", strings.Join(append(path[i:], name), " -> "))
+			}
+		}
+		if done.Contains(name) {
+			return nil
+		}
+		def := registrations[name]
+		if def == nil {
+			return nil
+		}
+		path = append(path, name)
+		for _, a := range def.After() {
+			err := visit(path, a)
+			if err != nil {
+				return err
+			}
+		}
+		done.Add(name)
+		return nil
+	}
+	for n := range registrations {
+		err := visit(nil, n)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orderControllers sorts controllers according to their startup
+// dependencies. Dependencies not contained in the list are ignored.
+func orderControllers(list []Controller) []Controller {
+	byName := map[string]Controller{}
+	names := []string{}
+	for _, c := range list {
+		byName[c.GetName()] = c
+		names = append(names, c.GetName())
+	}
+	sort.Strings(names)
+
+	result := []Controller{}
+	done := utils.StringSet{}
+	var add func(name string)
+	add = func(name string) {
+		if done.Contains(name) {
+			return
+		}
+		done.Add(name)
+		for _, a := range byName[name].GetDefinition().After() {
+			if byName[a] != nil {
+				add(a)
+			}
+		}
+		result = append(result, byName[name])
+	}
+	for _, n := range names {
+		add(n)
+	}
+	return result
+}
+
+func (c *ControllerManager) startupTimeout() time.Duration {
+	return durationOrDefault(c.config.StartupTimeout, 2*time.Minute)
+}
+
+// waitForDependencies waits for the controllers and CRDs
+// a controller depends on.
+func (c *ControllerManager) waitForDependencies(cntr Controller) error {
+	err := c.waitForCRDs(cntr)
+	if err != nil {
+		return err
+	}
+	return c.waitForControllers(cntr)
+}
+
+// waitForCRDs waits until the CRDs required by a controller are established.
+func (c *ControllerManager) waitForCRDs(cntr Controller) error {
+	for cname, crds := range cntr.GetDefinition().RequiredCRDs() {
+		cluster := cntr.GetCluster(cname)
+		if cluster == nil {
+			return fmt.Errorf("cluster %q for required crds of controller %q not found", cname, cntr.GetName())
+		}
+		for _, crd := range crds {
+			c.Infof("controller %q waits for crd %q in cluster %q", cntr.GetName(), crd, cluster.GetName())
+			err := apiextensions.WaitCRDEstablished(cluster, crd, c.startupTimeout())
+			if err != nil {
+				return fmt.Errorf("controller %q: crd %q in cluster %q not established: %s", cntr.GetName(), crd, cluster.GetName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// waitForControllers waits until the activated controllers a controller
+// should be started after are running.
+func (c *ControllerManager) waitForControllers(cntr Controller) error {
+	for _, name := range cntr.GetDefinition().After() {
+		dep := c.controllers[name]
+		if dep == nil || dep.IsReady() {
+			continue
+		}
+		c.Infof("controller %q waits for controller %q", cntr.GetName(), name)
+		err := wait.PollImmediate(time.Second, c.startupTimeout(), func() (bool, error) {
+			return dep.IsReady(), c.ctx.Err()
+		})
+		if err != nil {
+			return fmt.Errorf("controller %q: controller %q not started: %s", cntr.GetName(), name, err)
+		}
+	}
+	return nil
+}
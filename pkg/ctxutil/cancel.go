@@ -18,6 +18,7 @@ package ctxutil
 
 import (
 	"context"
+	"time"
 )
 
 var cancelkey = ""
@@ -28,5 +29,21 @@ func CancelContext(ctx context.Context) context.Context {
 }
 
 func Cancel(ctx context.Context) {
-	ctx.Value(&cancelkey).(context.CancelFunc)()
+	if cancel, ok := ctx.Value(&cancelkey).(context.CancelFunc); ok {
+		cancel()
+	}
+}
+
+type detached struct {
+	context.Context
+}
+
+func (detached) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detached) Done() <-chan struct{}       { return nil }
+func (detached) Err() error                  { return nil }
+
+// Detached returns a context providing the values of the given context,
+// which is not cancelled together with it.
+func Detached(ctx context.Context) context.Context {
+	return detached{ctx}
 }
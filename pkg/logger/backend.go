@@ -0,0 +1,137 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package logger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Level int
+
+const (
+	ErrorLevel Level = iota
+	WarnLevel
+	InfoLevel
+	DebugLevel
+)
+
+var levelNames = map[Level]string{
+	ErrorLevel: "error",
+	WarnLevel:  "warning",
+	InfoLevel:  "info",
+	DebugLevel: "debug",
+}
+
+func (this Level) String() string {
+	if n, ok := levelNames[this]; ok {
+		return n
+	}
+	return fmt.Sprintf("level(%d)", int(this))
+}
+
+// ParseLevel parses a level name. The levels of logrus are mapped to the
+// next matching level (panic and fatal to error, trace to debug).
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(name) {
+	case "panic", "fatal", "error":
+		return ErrorLevel, nil
+	case "warn", "warning":
+		return WarnLevel, nil
+	case "info":
+		return InfoLevel, nil
+	case "debug", "trace":
+		return DebugLevel, nil
+	}
+	return ErrorLevel, fmt.Errorf("invalid log level %q", name)
+}
+
+// Backend is the logger implementation used by the log contexts.
+// The default backend uses logrus, other structured loggers (for example
+// zap) can be used by implementing this interface and setting it with
+// SetBackend.
+type Backend interface {
+	// With returns a backend adding the given field to all log entries.
+	With(key, value string) Backend
+	// Enabled reports whether entries of the given level are logged.
+	// Messages are only formatted for enabled levels.
+	Enabled(level Level) bool
+	Log(level Level, msg string)
+	// SetLevel sets the level of the backend and all backends derived
+	// from it with With.
+	SetLevel(level Level)
+	// Structured reports whether the backend logs the fields separately.
+	// Otherwise the values of the log contexts prefix the message.
+	Structured() bool
+}
+
+var backend Backend = &logrusBackend{logrus.NewEntry(defaultLogger)}
+
+// SetBackend sets the backend used by the log contexts. It should be
+// called before any log context is created, log contexts created before
+// keep their backend.
+func SetBackend(b Backend) {
+	backend = b
+	updateBackendLevel()
+	defaultLogContext = New().(_context)
+}
+
+// GetBackend returns the actual backend.
+func GetBackend() Backend {
+	return backend
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// logrus
+
+var logrusLevels = map[Level]logrus.Level{
+	ErrorLevel: logrus.ErrorLevel,
+	WarnLevel:  logrus.WarnLevel,
+	InfoLevel:  logrus.InfoLevel,
+	DebugLevel: logrus.DebugLevel,
+}
+
+type logrusBackend struct {
+	entry *logrus.Entry
+}
+
+// NewLogrusBackend returns a backend for a logrus logger.
+func NewLogrusBackend(logger *logrus.Logger) Backend {
+	return &logrusBackend{logrus.NewEntry(logger)}
+}
+
+func (this *logrusBackend) With(key, value string) Backend {
+	return &logrusBackend{this.entry.WithField(key, value)}
+}
+
+func (this *logrusBackend) Enabled(level Level) bool {
+	return this.entry.Logger.IsLevelEnabled(logrusLevels[level])
+}
+
+func (this *logrusBackend) Log(level Level, msg string) {
+	this.entry.Log(logrusLevels[level], msg)
+}
+
+func (this *logrusBackend) SetLevel(level Level) {
+	this.entry.Logger.SetLevel(logrusLevels[level])
+}
+
+func (this *logrusBackend) Structured() bool {
+	return structured && this.entry.Logger == defaultLogger
+}
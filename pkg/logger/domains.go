@@ -0,0 +1,145 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package logger
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Log level domains group log contexts (for example the log contexts of a
+// controller or a package), whose level can be changed at runtime
+// independently of the global log level.
+
+const noLevel = -1
+
+type domain struct {
+	name  string
+	level int32
+}
+
+var (
+	domainLock  sync.RWMutex
+	domains     = map[string]*domain{}
+	globalLevel = int32(InfoLevel)
+)
+
+func getDomain(name string) *domain {
+	domainLock.RLock()
+	d := domains[name]
+	domainLock.RUnlock()
+	if d != nil {
+		return d
+	}
+	domainLock.Lock()
+	defer domainLock.Unlock()
+	d = domains[name]
+	if d == nil {
+		d = &domain{name: name, level: noLevel}
+		domains[name] = d
+	}
+	return d
+}
+
+// DomainContext returns a log context for the given log level domain.
+func DomainContext(name string) LogContext {
+	return _context{key: "", backend: backend, domain: getDomain(name)}
+}
+
+// WithDomain returns a log context for the given log level domain. Log
+// contexts derived from it belong to the same domain.
+func WithDomain(log LogContext, name string) LogContext {
+	if c, ok := log.(_context); ok {
+		c.domain = getDomain(name)
+		return c
+	}
+	return log
+}
+
+// GetLevel returns the global log level.
+func GetLevel() Level {
+	return Level(atomic.LoadInt32(&globalLevel))
+}
+
+// SetDomainLevel sets the log level of a domain, overriding the global
+// log level for the log contexts of the domain.
+func SetDomainLevel(name string, level Level) {
+	d := getDomain(name)
+	if atomic.SwapInt32(&d.level, int32(level)) != int32(level) {
+		Infof("Setting log level of %s to %s", name, level)
+	}
+	updateBackendLevel()
+}
+
+// ResetDomainLevel resets the log level of a domain to the global log level.
+func ResetDomainLevel(name string) {
+	domainLock.RLock()
+	d := domains[name]
+	domainLock.RUnlock()
+	if d != nil && atomic.SwapInt32(&d.level, noLevel) != noLevel {
+		Infof("Resetting log level of %s", name)
+		updateBackendLevel()
+	}
+}
+
+// DomainLevels returns the log levels set for domains.
+func DomainLevels() map[string]Level {
+	domainLock.RLock()
+	defer domainLock.RUnlock()
+	levels := map[string]Level{}
+	for n, d := range domains {
+		if l := atomic.LoadInt32(&d.level); l != noLevel {
+			levels[n] = Level(l)
+		}
+	}
+	return levels
+}
+
+// Domains returns the names of the known domains.
+func Domains() []string {
+	domainLock.RLock()
+	defer domainLock.RUnlock()
+	names := []string{}
+	for n := range domains {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (this *domain) enabled(level Level) bool {
+	if this != nil {
+		if l := atomic.LoadInt32(&this.level); l != noLevel {
+			return int32(level) <= l
+		}
+	}
+	return int32(level) <= atomic.LoadInt32(&globalLevel)
+}
+
+// updateBackendLevel sets the level of the backend to the most verbose
+// level used by any domain, the log contexts filter the entries
+// according to their domain.
+func updateBackendLevel() {
+	max := atomic.LoadInt32(&globalLevel)
+	for _, l := range DomainLevels() {
+		if int32(l) > max {
+			max = int32(l)
+		}
+	}
+	backend.SetLevel(Level(max))
+}
@@ -19,6 +19,8 @@ package logger
 import (
 	"fmt"
 	"os"
+	"strings"
+	"sync/atomic"
 
 	"github.com/sirupsen/logrus"
 )
@@ -38,70 +40,137 @@ type LogContext interface {
 }
 
 func SetLevel(name string) error {
-	lvl, err := logrus.ParseLevel(name)
+	lvl, err := ParseLevel(name)
 	if err != nil {
 		return err
 	}
-	defaultLogger.Infof("Setting log level to %s", lvl.String())
-	logrus.SetLevel(lvl)
-	defaultLogger.SetLevel(lvl)
+	Infof("Setting log level to %s", lvl.String())
+	if l, err := logrus.ParseLevel(name); err == nil {
+		logrus.SetLevel(l)
+	}
+	atomic.StoreInt32(&globalLevel, int32(lvl))
+	updateBackendLevel()
+	return nil
+}
+
+// SetFormat sets the output format of the default logrus backend (text or
+// json). The json format puts the context values of a log context (for
+// example controller, cluster and object key) into separate fields instead
+// of prefixing the message.
+func SetFormat(name string) error {
+	switch strings.ToLower(name) {
+	case "text":
+		defaultLogger.SetFormatter(newTextFormatter())
+		structured = false
+	case "json":
+		defaultLogger.SetFormatter(&logrus.JSONFormatter{
+			FieldMap: logrus.FieldMap{
+				logrus.FieldKeyTime: "timestamp",
+				logrus.FieldKeyMsg:  "message",
+			},
+		})
+		structured = true
+	default:
+		return fmt.Errorf("invalid log format %q (possible values: text, json)", name)
+	}
 	return nil
 }
 
+// textFormatter omits the context fields, which are already
+// part of the message.
+type textFormatter struct {
+	logrus.TextFormatter
+}
+
+func newTextFormatter() logrus.Formatter {
+	return &textFormatter{logrus.TextFormatter{DisableColors: true}}
+}
+
+func (this *textFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	e := *entry
+	e.Data = logrus.Fields{}
+	return this.TextFormatter.Format(&e)
+}
+
 type _context struct {
-	key   string
-	entry *logrus.Entry
+	key     string
+	backend Backend
+	domain  *domain
 }
 
 var _ LogContext = _context{}
 
 var defaultLogContext = New().(_context)
 var defaultLogger = &logrus.Logger{
-	Out:   os.Stderr,
-	Level: logrus.InfoLevel,
-	Formatter: &logrus.TextFormatter{
-		DisableColors: true,
-	},
+	Out:       os.Stderr,
+	Level:     logrus.InfoLevel,
+	Formatter: newTextFormatter(),
 }
 
+var structured = false
+
 func NewContext(key, value string) LogContext {
-	return _context{key: fmt.Sprintf("%s: ", value), entry: defaultLogger.WithFields(nil)}
+	return _context{key: fmt.Sprintf("%s: ", value), backend: backend.With(key, value)}
 }
 
 func New() LogContext {
-	return _context{key: "", entry: logrus.NewEntry(defaultLogger)}
+	return _context{key: "", backend: backend}
 }
 
 func (this _context) NewContext(key, value string) LogContext {
-	return _context{key: fmt.Sprintf("%s%s: ", this.key, value), entry: this.entry}
+	return _context{key: fmt.Sprintf("%s%s: ", this.key, value), backend: this.backend.With(key, value), domain: this.domain}
+}
+
+func (this _context) enabled(level Level) bool {
+	return this.domain.enabled(level) && this.backend.Enabled(level)
+}
+
+func (this _context) log(level Level, msg ...interface{}) {
+	if this.enabled(level) {
+		if this.backend.Structured() {
+			this.backend.Log(level, fmt.Sprint(msg...))
+		} else {
+			this.backend.Log(level, this.key+fmt.Sprint(msg...))
+		}
+	}
+}
+
+func (this _context) logf(level Level, msgfmt string, args ...interface{}) {
+	if this.enabled(level) {
+		if this.backend.Structured() {
+			this.backend.Log(level, fmt.Sprintf(msgfmt, args...))
+		} else {
+			this.backend.Log(level, fmt.Sprintf(this.key+msgfmt, args...))
+		}
+	}
 }
 
 func (this _context) Info(msg ...interface{}) {
-	this.entry.Infof("%s%s", this.key, fmt.Sprint(msg...))
+	this.log(InfoLevel, msg...)
 }
 func (this _context) Infof(msgfmt string, args ...interface{}) {
-	this.entry.Infof(this.key+msgfmt, args...)
+	this.logf(InfoLevel, msgfmt, args...)
 }
 
 func (this _context) Debug(msg ...interface{}) {
-	this.entry.Debugf("%s%s", this.key, fmt.Sprint(msg...))
+	this.log(DebugLevel, msg...)
 }
 func (this _context) Debugf(msgfmt string, args ...interface{}) {
-	this.entry.Debugf(this.key+msgfmt, args...)
+	this.logf(DebugLevel, msgfmt, args...)
 }
 
 func (this _context) Warn(msg ...interface{}) {
-	this.entry.Warnf("%s%s", this.key, fmt.Sprint(msg...))
+	this.log(WarnLevel, msg...)
 }
 func (this _context) Warnf(msgfmt string, args ...interface{}) {
-	this.entry.Warnf(this.key+msgfmt, args...)
+	this.logf(WarnLevel, msgfmt, args...)
 }
 
 func (this _context) Error(msg ...interface{}) {
-	this.entry.Errorf("%s%s", this.key, fmt.Sprint(msg...))
+	this.log(ErrorLevel, msg...)
 }
 func (this _context) Errorf(msgfmt string, args ...interface{}) {
-	this.entry.Errorf(this.key+msgfmt, args...)
+	this.logf(ErrorLevel, msgfmt, args...)
 }
 
 func Info(msg ...interface{}) {
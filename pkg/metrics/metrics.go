@@ -0,0 +1,329 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Collector is a metric family that can be exposed by a registry
+type Collector interface {
+	GetName() string
+	Write(w io.Writer)
+}
+
+type family struct {
+	lock   sync.Mutex
+	name   string
+	help   string
+	mtype  string
+	labels []string
+}
+
+func (this *family) GetName() string {
+	return this.name
+}
+
+func (this *family) key(values []string) string {
+	if len(values) != len(this.labels) {
+		panic(fmt.Sprintf("metric %q requires %d label values, but got %d", this.name, len(this.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+func (this *family) header(w io.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", this.name, escape(this.help, false))
+	fmt.Fprintf(w, "# TYPE %s %s\n", this.name, this.mtype)
+}
+
+func (this *family) labelString(values []string, extra ...string) string {
+	if len(values) == 0 && len(extra) == 0 {
+		return ""
+	}
+	s := "{"
+	sep := ""
+	for i, l := range this.labels {
+		s = fmt.Sprintf("%s%s%s=\"%s\"", s, sep, l, escape(values[i], true))
+		sep = ","
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		s = fmt.Sprintf("%s%s%s=\"%s\"", s, sep, extra[i], escape(extra[i+1], true))
+		sep = ","
+	}
+	return s + "}"
+}
+
+func escape(s string, quote bool) string {
+	s = strings.Replace(s, "\\", "\\\\", -1)
+	s = strings.Replace(s, "\n", "\\n", -1)
+	if quote {
+		s = strings.Replace(s, "\"", "\\\"", -1)
+	}
+	return s
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Value is a single float value of a counter or gauge
+type Value struct {
+	lock  sync.Mutex
+	value float64
+}
+
+func (this *Value) Set(v float64) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.value = v
+}
+
+func (this *Value) Add(v float64) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.value += v
+}
+
+func (this *Value) Inc() {
+	this.Add(1)
+}
+
+func (this *Value) Dec() {
+	this.Add(-1)
+}
+
+func (this *Value) Get() float64 {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return this.value
+}
+
+type valueVec struct {
+	family
+	values map[string]*Value
+	keys   map[string][]string
+}
+
+func newValueVec(mtype, name, help string, labels ...string) valueVec {
+	return valueVec{
+		family: family{name: name, help: help, mtype: mtype, labels: labels},
+		values: map[string]*Value{},
+		keys:   map[string][]string{},
+	}
+}
+
+func (this *valueVec) WithLabelValues(values ...string) *Value {
+	key := this.key(values)
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	v := this.values[key]
+	if v == nil {
+		v = &Value{}
+		this.values[key] = v
+		this.keys[key] = append([]string{}, values...)
+	}
+	return v
+}
+
+func (this *valueVec) Delete(values ...string) {
+	key := this.key(values)
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	delete(this.values, key)
+	delete(this.keys, key)
+}
+
+func (this *valueVec) Write(w io.Writer) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.header(w)
+	for _, k := range sortedKeys(this.keys) {
+		fmt.Fprintf(w, "%s%s %s\n", this.name, this.labelString(this.keys[k]), formatValue(this.values[k].Get()))
+	}
+}
+
+// CounterVec is a family of counters partitioned by label values
+type CounterVec struct {
+	valueVec
+}
+
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{newValueVec("counter", name, help, labels...)}
+}
+
+// GaugeVec is a family of gauges partitioned by label values
+type GaugeVec struct {
+	valueVec
+}
+
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{newValueVec("gauge", name, help, labels...)}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// DefBuckets are the default histogram buckets (in seconds)
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Histogram counts observations in configurable buckets
+type Histogram struct {
+	lock    sync.Mutex
+	buckets []float64
+	counts  []uint64
+	count   uint64
+	sum     float64
+}
+
+func (this *Histogram) Observe(v float64) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	for i, b := range this.buckets {
+		if v <= b {
+			this.counts[i]++
+		}
+	}
+	this.count++
+	this.sum += v
+}
+
+// HistogramVec is a family of histograms partitioned by label values
+type HistogramVec struct {
+	family
+	buckets    []float64
+	histograms map[string]*Histogram
+	keys       map[string][]string
+}
+
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefBuckets
+	}
+	buckets = append([]float64{}, buckets...)
+	sort.Float64s(buckets)
+	return &HistogramVec{
+		family:     family{name: name, help: help, mtype: "histogram", labels: labels},
+		buckets:    buckets,
+		histograms: map[string]*Histogram{},
+		keys:       map[string][]string{},
+	}
+}
+
+func (this *HistogramVec) WithLabelValues(values ...string) *Histogram {
+	key := this.key(values)
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	h := this.histograms[key]
+	if h == nil {
+		h = &Histogram{buckets: this.buckets, counts: make([]uint64, len(this.buckets))}
+		this.histograms[key] = h
+		this.keys[key] = append([]string{}, values...)
+	}
+	return h
+}
+
+func (this *HistogramVec) Delete(values ...string) {
+	key := this.key(values)
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	delete(this.histograms, key)
+	delete(this.keys, key)
+}
+
+func (this *HistogramVec) Write(w io.Writer) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.header(w)
+	for _, k := range sortedKeys(this.keys) {
+		values := this.keys[k]
+		h := this.histograms[k]
+		h.lock.Lock()
+		for i, b := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", this.name, this.labelString(values, "le", formatValue(b)), h.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", this.name, this.labelString(values, "le", "+Inf"), h.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", this.name, this.labelString(values), formatValue(h.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", this.name, this.labelString(values), h.count)
+		h.lock.Unlock()
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// GaugeFuncVec is a family of gauges whose values are determined
+// by functions evaluated at collection time
+type GaugeFuncVec struct {
+	family
+	funcs map[string]func() float64
+	keys  map[string][]string
+}
+
+func NewGaugeFuncVec(name, help string, labels ...string) *GaugeFuncVec {
+	return &GaugeFuncVec{
+		family: family{name: name, help: help, mtype: "gauge", labels: labels},
+		funcs:  map[string]func() float64{},
+		keys:   map[string][]string{},
+	}
+}
+
+func (this *GaugeFuncVec) Set(f func() float64, values ...string) {
+	key := this.key(values)
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.funcs[key] = f
+	this.keys[key] = append([]string{}, values...)
+}
+
+func (this *GaugeFuncVec) Delete(values ...string) {
+	key := this.key(values)
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	delete(this.funcs, key)
+	delete(this.keys, key)
+}
+
+func (this *GaugeFuncVec) Write(w io.Writer) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.header(w)
+	for _, k := range sortedKeys(this.keys) {
+		fmt.Fprintf(w, "%s%s %s\n", this.name, this.labelString(this.keys[k]), formatValue(this.funcs[k]()))
+	}
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/gardener/controller-manager-library/pkg/server"
+)
+
+func init() {
+	server.RegisterHandler("/metrics", Handler())
+}
+
+// Registry is a set of metric collectors exposed in the
+// prometheus text format
+type Registry struct {
+	lock       sync.RWMutex
+	collectors map[string]Collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{collectors: map[string]Collector{}}
+}
+
+func (this *Registry) Register(c Collector) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.collectors[c.GetName()] != nil {
+		return fmt.Errorf("metric %q already registered", c.GetName())
+	}
+	this.collectors[c.GetName()] = c
+	return nil
+}
+
+func (this *Registry) MustRegister(c ...Collector) {
+	for _, m := range c {
+		if err := this.Register(m); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func (this *Registry) Unregister(c Collector) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.collectors[c.GetName()] == c {
+		delete(this.collectors, c.GetName())
+	}
+}
+
+func (this *Registry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	this.lock.RLock()
+	names := make([]string, 0, len(this.collectors))
+	for n := range this.collectors {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	buf := &bytes.Buffer{}
+	for _, n := range names {
+		this.collectors[n].Write(buf)
+	}
+	this.lock.RUnlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write(buf.Bytes())
+}
+
+var defaultRegistry = NewRegistry()
+
+// Handler returns the HTTP handler for the default registry
+func Handler() http.Handler {
+	return defaultRegistry
+}
+
+func Register(c Collector) error {
+	return defaultRegistry.Register(c)
+}
+
+func MustRegister(c ...Collector) {
+	defaultRegistry.MustRegister(c...)
+}
+
+func Unregister(c Collector) {
+	defaultRegistry.Unregister(c)
+}
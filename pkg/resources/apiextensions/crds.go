@@ -17,14 +17,18 @@
 package apiextensions
 
 import (
+	"bytes"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/gardener/controller-manager-library/pkg/logger"
 	"github.com/gardener/controller-manager-library/pkg/resources"
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/util/yaml"
 )
 
 func init() {
@@ -88,11 +92,125 @@ func CreateCRDFromObject(cluster resources.Cluster, crd *v1beta1.CustomResourceD
 	return WaitCRDReady(cluster, crd.Name)
 }
 
+// CreateCRDObjectFromManifest parses a CRD manifest given in YAML or JSON.
+func CreateCRDObjectFromManifest(manifest []byte) (*v1beta1.CustomResourceDefinition, error) {
+	crd := &v1beta1.CustomResourceDefinition{}
+	err := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifest), 4096).Decode(crd)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CRD manifest: %s", err)
+	}
+	if crd.Kind != "" && crd.Kind != "CustomResourceDefinition" {
+		return nil, fmt.Errorf("invalid CRD manifest: unexpected kind %q", crd.Kind)
+	}
+	if crd.Name == "" {
+		return nil, fmt.Errorf("invalid CRD manifest: name missing")
+	}
+	return crd, nil
+}
+
+// CreateOrUpdateCRDFromObject creates a CRD or updates the spec of an
+// existing one and waits until it is established.
+// Versions still listed in the stored versions of an existing CRD are kept
+// (not served) to keep existing objects readable until they are migrated.
+// If the storage version of an existing CRD is unknown to the given CRD,
+// the existing CRD is assumed to be newer and is not modified.
+func CreateOrUpdateCRDFromObject(cluster resources.Cluster, crd *v1beta1.CustomResourceDefinition, timeout time.Duration) error {
+	desired := crd.DeepCopy()
+	normalizeVersions(desired)
+
+	old := &v1beta1.CustomResourceDefinition{}
+	o, err := cluster.Resources().GetObjectInto(resources.NewObjectName(desired.Name), old)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to get CRD %s: %s", desired.Name, err)
+		}
+		_, err = cluster.Resources().CreateObject(desired)
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create CRD %s: %s", desired.Name, err)
+		}
+		return WaitCRDEstablished(cluster, desired.Name, timeout)
+	}
+
+	if storage := storageVersion(old); storage != "" && findVersion(desired, storage) < 0 {
+		logger.Warnf("CRD %s in cluster %s uses unknown storage version %q: keeping newer CRD", desired.Name, cluster.GetName(), storage)
+		return WaitCRDEstablished(cluster, desired.Name, timeout)
+	}
+	for _, v := range old.Status.StoredVersions {
+		if findVersion(desired, v) < 0 {
+			desired.Spec.Versions = append(desired.Spec.Versions, v1beta1.CustomResourceDefinitionVersion{Name: v})
+		}
+	}
+	if desired.Spec.Conversion == nil {
+		// defaulted by the API server
+		desired.Spec.Conversion = old.Spec.Conversion
+	}
+	// only the fields set by the controller are compared, fields defaulted
+	// by the API server would otherwise cause an update on every start
+	update, diff, err := resources.NeedsUpdate(desired, old, resources.DiffOptions{})
+	if err != nil {
+		return fmt.Errorf("cannot compare CRD %s: %s", desired.Name, err)
+	}
+	if update {
+		logger.Infof("updating CRD %s in cluster %s: %s", desired.Name, cluster.GetName(), strings.Join(diff.Paths(), ", "))
+		old.Spec = desired.Spec
+		for k, v := range desired.Labels {
+			resources.SetLabel(old, k, v)
+		}
+		for k, v := range desired.Annotations {
+			resources.SetAnnotation(old, k, v)
+		}
+		if err := o.Update(); err != nil {
+			return fmt.Errorf("failed to update CRD %s: %s", desired.Name, err)
+		}
+	}
+	return WaitCRDEstablished(cluster, desired.Name, timeout)
+}
+
+// normalizeVersions converts the deprecated version field into the
+// version list.
+func normalizeVersions(crd *v1beta1.CustomResourceDefinition) {
+	if len(crd.Spec.Versions) == 0 && crd.Spec.Version != "" {
+		crd.Spec.Versions = []v1beta1.CustomResourceDefinitionVersion{
+			{Name: crd.Spec.Version, Served: true, Storage: true},
+		}
+	}
+	if crd.Spec.Version == "" && len(crd.Spec.Versions) > 0 {
+		crd.Spec.Version = crd.Spec.Versions[0].Name
+	}
+}
+
+func storageVersion(crd *v1beta1.CustomResourceDefinition) string {
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			return v.Name
+		}
+	}
+	return crd.Spec.Version
+}
+
+func findVersion(crd *v1beta1.CustomResourceDefinition, name string) int {
+	for i, v := range crd.Spec.Versions {
+		if v.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
 func WaitCRDReady(cluster resources.Cluster, crdName string) error {
-	err := wait.PollImmediate(5*time.Second, 60*time.Second, func() (bool, error) {
+	return WaitCRDEstablished(cluster, crdName, 60*time.Second)
+}
+
+// WaitCRDEstablished waits until the given CRD is established. A CRD not
+// yet existing is waited for, too.
+func WaitCRDEstablished(cluster resources.Cluster, crdName string, timeout time.Duration) error {
+	err := wait.PollImmediate(5*time.Second, timeout, func() (bool, error) {
 		crd := &v1beta1.CustomResourceDefinition{}
 		_, err := cluster.Resources().GetObjectInto(resources.NewObjectName(crdName), crd)
 		if err != nil {
+			if errors.IsNotFound(err) {
+				return false, nil
+			}
 			return false, err
 		}
 		for _, cond := range crd.Status.Conditions {
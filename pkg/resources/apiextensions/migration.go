@@ -0,0 +1,151 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package apiextensions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gardener/controller-manager-library/pkg/logger"
+	"github.com/gardener/controller-manager-library/pkg/metrics"
+	"github.com/gardener/controller-manager-library/pkg/resources"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// ANNOTATION_STORAGE_MIGRATION stores the progress of a storage version
+// migration (<storage version>:<continue token>) at the CRD.
+const ANNOTATION_STORAGE_MIGRATION = "resources.gardener.cloud/storage-migration"
+
+const migrationPageSize = 500
+
+var (
+	migrationRunning = metrics.NewGaugeVec("crd_storage_migration_running",
+		"Whether a storage version migration is running for a CRD", "crd")
+	migrationObjects = metrics.NewCounterVec("crd_storage_migration_objects_total",
+		"Number of objects rewritten by storage version migrations", "crd")
+	migrationStoredVersions = metrics.NewGaugeVec("crd_storage_migration_stored_versions",
+		"Number of stored versions of a CRD handled by a storage version migration", "crd")
+)
+
+func init() {
+	metrics.MustRegister(migrationRunning, migrationObjects, migrationStoredVersions)
+}
+
+// MigrateStorageVersion rewrites all objects of a CRD to store them in the
+// actual storage version and prunes all other versions from the stored
+// versions of the CRD afterwards. The progress is kept at the CRD, so an
+// interrupted migration is resumed by the next call.
+func MigrateStorageVersion(ctx context.Context, cluster resources.Cluster, crdName string) error {
+	crd := &v1beta1.CustomResourceDefinition{}
+	o, err := cluster.Resources().GetObjectInto(resources.NewObjectName(crdName), crd)
+	if err != nil {
+		return fmt.Errorf("failed to get CRD %s: %s", crdName, err)
+	}
+	storage := storageVersion(crd)
+	migrationStoredVersions.WithLabelValues(crdName).Set(float64(len(crd.Status.StoredVersions)))
+	if len(crd.Status.StoredVersions) == 1 && crd.Status.StoredVersions[0] == storage {
+		return nil
+	}
+
+	cont := ""
+	if progress := crd.Annotations[ANNOTATION_STORAGE_MIGRATION]; strings.HasPrefix(progress, storage+":") {
+		cont = strings.TrimPrefix(progress, storage+":")
+		logger.Infof("resuming migration of CRD %s in cluster %s to storage version %s", crdName, cluster.GetName(), storage)
+	} else {
+		logger.Infof("migrating CRD %s in cluster %s from stored versions %v to storage version %s",
+			crdName, cluster.GetName(), crd.Status.StoredVersions, storage)
+	}
+	migrationRunning.WithLabelValues(crdName).Set(1)
+	defer migrationRunning.WithLabelValues(crdName).Set(0)
+
+	cfg := cluster.Config()
+	client, err := dynamic.NewForConfig(&cfg)
+	if err != nil {
+		return err
+	}
+	res := client.Resource(schema.GroupVersionResource{Group: crd.Spec.Group, Version: storage, Resource: crd.Spec.Names.Plural})
+
+	for {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		list, err := res.List(metav1.ListOptions{Limit: migrationPageSize, Continue: cont})
+		if err != nil {
+			if cont != "" && errors.IsResourceExpired(err) {
+				logger.Warnf("migration of CRD %s: continue token expired, restarting", crdName)
+				cont = ""
+				continue
+			}
+			return fmt.Errorf("failed to list objects of CRD %s: %s", crdName, err)
+		}
+		for i := range list.Items {
+			if err := rewrite(res, &list.Items[i]); err != nil {
+				return fmt.Errorf("failed to migrate %s/%s of CRD %s: %s", list.Items[i].GetNamespace(), list.Items[i].GetName(), crdName, err)
+			}
+			migrationObjects.WithLabelValues(crdName).Inc()
+		}
+		cont = list.GetContinue()
+		if cont == "" {
+			break
+		}
+		_, err = o.Modify(func(data resources.ObjectData) (bool, error) {
+			return resources.SetAnnotation(data, ANNOTATION_STORAGE_MIGRATION, storage+":"+cont), nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to store migration progress for CRD %s: %s", crdName, err)
+		}
+	}
+
+	_, err = o.ModifyStatus(func(data resources.ObjectData) (bool, error) {
+		c := data.(*v1beta1.CustomResourceDefinition)
+		if len(c.Status.StoredVersions) == 1 && c.Status.StoredVersions[0] == storage {
+			return false, nil
+		}
+		c.Status.StoredVersions = []string{storage}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to prune stored versions of CRD %s: %s", crdName, err)
+	}
+	_, err = o.Modify(func(data resources.ObjectData) (bool, error) {
+		return resources.RemoveAnnotation(data, ANNOTATION_STORAGE_MIGRATION), nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to cleanup migration progress for CRD %s: %s", crdName, err)
+	}
+	migrationStoredVersions.WithLabelValues(crdName).Set(1)
+	logger.Infof("migration of CRD %s in cluster %s to storage version %s done", crdName, cluster.GetName(), storage)
+	return nil
+}
+
+// rewrite writes an unchanged object to let the API server store it in
+// the actual storage version.
+func rewrite(res dynamic.NamespaceableResourceInterface, obj *unstructured.Unstructured) error {
+	_, err := res.Namespace(obj.GetNamespace()).Update(obj, metav1.UpdateOptions{})
+	if err == nil || errors.IsNotFound(err) || errors.IsConflict(err) {
+		// a conflicting change has stored the object in the actual version, too
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,405 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package apiextensions
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gardener/controller-manager-library/pkg/resources"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// SchemaCacheTTL is the time a schema of a custom resource definition
+// is cached by a SchemaValidator.
+var SchemaCacheTTL = time.Minute
+
+// SchemaValidator validates objects of custom resources against the
+// OpenAPI v3 schemas of their custom resource definitions, which are
+// fetched from the cluster and cached. Validation errors are reported as
+// Invalid API errors with precise field paths, instead of failing
+// with a server round trip.
+type SchemaValidator struct {
+	lock    sync.Mutex
+	cluster resources.Cluster
+	schemas map[schema.GroupVersionKind]*cachedSchema
+}
+
+type cachedSchema struct {
+	schema    *v1beta1.JSONSchemaProps
+	timestamp time.Time
+}
+
+func NewSchemaValidator(cluster resources.Cluster) *SchemaValidator {
+	return &SchemaValidator{cluster: cluster, schemas: map[schema.GroupVersionKind]*cachedSchema{}}
+}
+
+// EnableSchemaValidation validates the objects of the given custom
+// resources read from or written to the cluster against the schemas of
+// their custom resource definitions.
+func EnableSchemaValidation(cluster resources.Cluster, gks ...schema.GroupKind) (*SchemaValidator, error) {
+	v := NewSchemaValidator(cluster)
+	for _, gk := range gks {
+		// the validator is used for typed and unstructured objects
+		r, err := cluster.Resources().GetUnstructuredByGK(gk)
+		if err != nil {
+			return nil, err
+		}
+		r.SetValidator(v.Validate)
+	}
+	return v, nil
+}
+
+// Validate validates an object against the schema of its custom resource
+// definition. Objects without schema are valid.
+func (this *SchemaValidator) Validate(obj resources.ObjectData) error {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.Empty() {
+		r, err := this.cluster.Resources().GetByExample(obj)
+		if err != nil {
+			return err
+		}
+		gvk = r.GroupVersionKind()
+	}
+	s, err := this.Schema(gvk)
+	if err != nil || s == nil {
+		return err
+	}
+	var content map[string]interface{}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		content = u.UnstructuredContent()
+	} else {
+		content, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return err
+		}
+	}
+	errs := ValidateSchema(s, content, nil)
+	if len(errs) > 0 {
+		return errors.NewInvalid(gvk.GroupKind(), obj.GetName(), errs)
+	}
+	return nil
+}
+
+// Schema returns the (cached) schema for a version of a custom resource
+// or nil if the custom resource definition does not declare a schema.
+func (this *SchemaValidator) Schema(gvk schema.GroupVersionKind) (*v1beta1.JSONSchemaProps, error) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if c := this.schemas[gvk]; c != nil && time.Now().Sub(c.timestamp) < SchemaCacheTTL {
+		return c.schema, nil
+	}
+	r, err := this.cluster.Resources().GetByGVK(gvk)
+	if err != nil {
+		return nil, err
+	}
+	crd := &v1beta1.CustomResourceDefinition{}
+	_, err = this.cluster.Resources().GetObjectInto(resources.NewObjectName(r.Name()+"."+gvk.Group), crd)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get custom resource definition for %s: %s", gvk, err)
+	}
+	validation := crd.Spec.Validation
+	for _, v := range crd.Spec.Versions {
+		if v.Name == gvk.Version && v.Schema != nil {
+			validation = v.Schema
+		}
+	}
+	var s *v1beta1.JSONSchemaProps
+	if validation != nil {
+		s = validation.OpenAPIV3Schema
+	}
+	this.schemas[gvk] = &cachedSchema{s, time.Now()}
+	return s, nil
+}
+
+// Invalidate removes all cached schemas.
+func (this *SchemaValidator) Invalidate() {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.schemas = map[schema.GroupVersionKind]*cachedSchema{}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// ValidateSchema validates a value in unstructured form against an
+// OpenAPI v3 schema. The type meta and object meta of an object are
+// validated by the API server and ignored on the top level.
+func ValidateSchema(s *v1beta1.JSONSchemaProps, obj map[string]interface{}, path *field.Path) field.ErrorList {
+	content := map[string]interface{}{}
+	for k, v := range obj {
+		switch k {
+		case "apiVersion", "kind", "metadata":
+		default:
+			content[k] = v
+		}
+	}
+	top := *s
+	top.Properties = map[string]v1beta1.JSONSchemaProps{}
+	for k, p := range s.Properties {
+		switch k {
+		case "apiVersion", "kind", "metadata":
+		default:
+			top.Properties[k] = p
+		}
+	}
+	return validateValue(&top, content, path)
+}
+
+func validateValue(s *v1beta1.JSONSchemaProps, v interface{}, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if v == nil {
+		if s.Type != "" {
+			errs = append(errs, field.Invalid(path, v, fmt.Sprintf("must be of type %s", s.Type)))
+		}
+		return errs
+	}
+	switch s.Type {
+	case "object":
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return append(errs, field.Invalid(path, v, "must be of type object"))
+		}
+		errs = append(errs, validateObject(s, m, path)...)
+	case "array":
+		a, ok := v.([]interface{})
+		if !ok {
+			return append(errs, field.Invalid(path, v, "must be of type array"))
+		}
+		errs = append(errs, validateArray(s, a, path)...)
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return append(errs, field.Invalid(path, v, "must be of type string"))
+		}
+		errs = append(errs, validateString(s, str, path)...)
+	case "integer":
+		f, ok := number(v)
+		if !ok || f != math.Trunc(f) {
+			return append(errs, field.Invalid(path, v, "must be of type integer"))
+		}
+		errs = append(errs, validateNumber(s, f, v, path)...)
+	case "number":
+		f, ok := number(v)
+		if !ok {
+			return append(errs, field.Invalid(path, v, "must be of type number"))
+		}
+		errs = append(errs, validateNumber(s, f, v, path)...)
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return append(errs, field.Invalid(path, v, "must be of type boolean"))
+		}
+	}
+	if len(s.Enum) > 0 {
+		errs = append(errs, validateEnum(s, v, path)...)
+	}
+	for i := range s.AllOf {
+		errs = append(errs, validateValue(&s.AllOf[i], v, path)...)
+	}
+	if len(s.AnyOf) > 0 {
+		matched := 0
+		for i := range s.AnyOf {
+			if len(validateValue(&s.AnyOf[i], v, path)) == 0 {
+				matched++
+			}
+		}
+		if matched == 0 {
+			errs = append(errs, field.Invalid(path, v, "must match at least one schema of anyOf"))
+		}
+	}
+	if len(s.OneOf) > 0 {
+		matched := 0
+		for i := range s.OneOf {
+			if len(validateValue(&s.OneOf[i], v, path)) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			errs = append(errs, field.Invalid(path, v, "must match exactly one schema of oneOf"))
+		}
+	}
+	if s.Not != nil && len(validateValue(s.Not, v, path)) == 0 {
+		errs = append(errs, field.Invalid(path, v, "must not match the schema of not"))
+	}
+	return errs
+}
+
+func validateObject(s *v1beta1.JSONSchemaProps, m map[string]interface{}, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	for _, r := range s.Required {
+		if _, ok := m[r]; !ok {
+			errs = append(errs, field.Required(path.Child(r), ""))
+		}
+	}
+	if s.MinProperties != nil && int64(len(m)) < *s.MinProperties {
+		errs = append(errs, field.Invalid(path, len(m), fmt.Sprintf("must have at least %d properties", *s.MinProperties)))
+	}
+	if s.MaxProperties != nil && int64(len(m)) > *s.MaxProperties {
+		errs = append(errs, field.Invalid(path, len(m), fmt.Sprintf("must have at most %d properties", *s.MaxProperties)))
+	}
+	for k, v := range m {
+		if p, ok := s.Properties[k]; ok {
+			errs = append(errs, validateValue(&p, v, path.Child(k))...)
+			continue
+		}
+		matched := false
+		for pattern, p := range s.PatternProperties {
+			if re, err := regexp.Compile(pattern); err == nil && re.MatchString(k) {
+				matched = true
+				errs = append(errs, validateValue(&p, v, path.Child(k))...)
+			}
+		}
+		if matched || s.AdditionalProperties == nil {
+			continue
+		}
+		if s.AdditionalProperties.Schema != nil {
+			errs = append(errs, validateValue(s.AdditionalProperties.Schema, v, path.Key(k))...)
+		} else if !s.AdditionalProperties.Allows {
+			errs = append(errs, field.Forbidden(path.Child(k), "unknown field"))
+		}
+	}
+	return errs
+}
+
+func validateArray(s *v1beta1.JSONSchemaProps, a []interface{}, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if s.MinItems != nil && int64(len(a)) < *s.MinItems {
+		errs = append(errs, field.Invalid(path, len(a), fmt.Sprintf("must have at least %d items", *s.MinItems)))
+	}
+	if s.MaxItems != nil && int64(len(a)) > *s.MaxItems {
+		errs = append(errs, field.TooLong(path, a, int(*s.MaxItems)))
+	}
+	if s.UniqueItems {
+		for i := range a {
+			for j := 0; j < i; j++ {
+				if reflect.DeepEqual(a[i], a[j]) {
+					errs = append(errs, field.Duplicate(path.Index(i), a[i]))
+					break
+				}
+			}
+		}
+	}
+	if s.Items != nil {
+		for i, e := range a {
+			switch {
+			case s.Items.Schema != nil:
+				errs = append(errs, validateValue(s.Items.Schema, e, path.Index(i))...)
+			case i < len(s.Items.JSONSchemas):
+				errs = append(errs, validateValue(&s.Items.JSONSchemas[i], e, path.Index(i))...)
+			}
+		}
+	}
+	return errs
+}
+
+func validateString(s *v1beta1.JSONSchemaProps, str string, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	l := int64(utf8.RuneCountInString(str))
+	if s.MinLength != nil && l < *s.MinLength {
+		errs = append(errs, field.Invalid(path, str, fmt.Sprintf("must be at least %d characters long", *s.MinLength)))
+	}
+	if s.MaxLength != nil && l > *s.MaxLength {
+		errs = append(errs, field.TooLong(path, str, int(*s.MaxLength)))
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			errs = append(errs, field.InternalError(path, fmt.Errorf("invalid pattern %q: %s", s.Pattern, err)))
+		} else if !re.MatchString(str) {
+			errs = append(errs, field.Invalid(path, str, fmt.Sprintf("must match pattern %q", s.Pattern)))
+		}
+	}
+	return errs
+}
+
+func validateNumber(s *v1beta1.JSONSchemaProps, f float64, v interface{}, path *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if s.Minimum != nil && (f < *s.Minimum || (s.ExclusiveMinimum && f == *s.Minimum)) {
+		errs = append(errs, field.Invalid(path, v, fmt.Sprintf("must be greater than %s%v", orEqual(!s.ExclusiveMinimum), *s.Minimum)))
+	}
+	if s.Maximum != nil && (f > *s.Maximum || (s.ExclusiveMaximum && f == *s.Maximum)) {
+		errs = append(errs, field.Invalid(path, v, fmt.Sprintf("must be less than %s%v", orEqual(!s.ExclusiveMaximum), *s.Maximum)))
+	}
+	if s.MultipleOf != nil && *s.MultipleOf != 0 {
+		if q := f / *s.MultipleOf; q != math.Trunc(q) {
+			errs = append(errs, field.Invalid(path, v, fmt.Sprintf("must be a multiple of %v", *s.MultipleOf)))
+		}
+	}
+	return errs
+}
+
+func validateEnum(s *v1beta1.JSONSchemaProps, v interface{}, path *field.Path) field.ErrorList {
+	value := normalize(v)
+	allowed := []string{}
+	for _, e := range s.Enum {
+		var ev interface{}
+		if err := json.Unmarshal(e.Raw, &ev); err != nil {
+			continue
+		}
+		if reflect.DeepEqual(value, ev) {
+			return nil
+		}
+		allowed = append(allowed, string(e.Raw))
+	}
+	return field.ErrorList{field.NotSupported(path, v, allowed)}
+}
+
+func orEqual(b bool) string {
+	if b {
+		return "or equal to "
+	}
+	return ""
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// normalize converts a value to the representation of generic JSON decoding.
+func normalize(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var r interface{}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return v
+	}
+	return r
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ApplyConfiguration is a partial object for server side apply. In
+// contrast to a complete object it only contains the explicitly set fields,
+// so the field manager of the apply request owns exactly these fields.
+type ApplyConfiguration struct {
+	unstructured.Unstructured
+}
+
+var _ ObjectData = &ApplyConfiguration{}
+
+func NewApplyConfiguration(gvk schema.GroupVersionKind, namespace, name string) *ApplyConfiguration {
+	a := &ApplyConfiguration{}
+	a.SetGroupVersionKind(gvk)
+	a.SetNamespace(namespace)
+	a.SetName(name)
+	return a
+}
+
+// ApplyConfigurationFor creates an empty apply configuration for the
+// given object.
+func ApplyConfigurationFor(obj Object) *ApplyConfiguration {
+	return NewApplyConfiguration(obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+}
+
+// Set sets the field given by its path to the given value. Values of
+// other types than those supported by unstructured objects are converted
+// by their JSON representation. Non serializable values cause a panic.
+func (this *ApplyConfiguration) Set(value interface{}, fields ...string) *ApplyConfiguration {
+	if len(fields) == 0 {
+		panic("field path required for apply configuration")
+	}
+	v, err := toUnstructuredValue(value)
+	if err != nil {
+		panic(fmt.Sprintf("invalid value for field %v: %s", fields, err))
+	}
+	if err := unstructured.SetNestedField(this.Object, v, fields...); err != nil {
+		panic(fmt.Sprintf("cannot set field %v: %s", fields, err))
+	}
+	return this
+}
+
+func (this *ApplyConfiguration) SetSpec(value interface{}) *ApplyConfiguration {
+	return this.Set(value, "spec")
+}
+
+func (this *ApplyConfiguration) SetLabel(name, value string) *ApplyConfiguration {
+	return this.Set(value, "metadata", "labels", name)
+}
+
+func (this *ApplyConfiguration) SetAnnotation(name, value string) *ApplyConfiguration {
+	return this.Set(value, "metadata", "annotations", name)
+}
+
+func (this *ApplyConfiguration) AddOwnerReference(ref metav1.OwnerReference) *ApplyConfiguration {
+	this.SetOwnerReferences(append(this.GetOwnerReferences(), ref))
+	return this
+}
+
+func (this *ApplyConfiguration) DeepCopyObject() runtime.Object {
+	return &ApplyConfiguration{*this.Unstructured.DeepCopy()}
+}
+
+func toUnstructuredValue(value interface{}) (interface{}, error) {
+	switch value.(type) {
+	case nil, string, bool, int64, float64, map[string]interface{}, []interface{}:
+		return runtime.DeepCopyJSONValue(value), nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return fixNumbers(v), nil
+}
+
+// fixNumbers converts integral numbers to int64, because plain JSON
+// decoding uses float64 for all numbers.
+func fixNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case float64:
+		if t == float64(int64(t)) {
+			return int64(t)
+		}
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = fixNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = fixNumbers(e)
+		}
+	}
+	return v
+}
@@ -20,25 +20,38 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	kscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	restclient "k8s.io/client-go/rest"
 	"sync"
 )
 
+const ATTR_DISABLE_PROTOBUF = "disable-protobuf"
+
+const ContentTypeProtobuf = "application/vnd.kubernetes.protobuf"
+const ContentTypeJSON = "application/json"
+
+type clientKey struct {
+	gv   schema.GroupVersion
+	json bool
+}
+
 type Clients struct {
 	lock           sync.Mutex
 	scheme         *runtime.Scheme
 	config         restclient.Config
 	codecfactory   serializer.CodecFactory
 	parametercodec runtime.ParameterCodec
-	clients        map[schema.GroupVersion]restclient.Interface
+	protobuf       bool
+	clients        map[clientKey]restclient.Interface
 }
 
 func NewClients(config restclient.Config, scheme *runtime.Scheme) *Clients {
 	client := &Clients{
 		config:         config,
 		scheme:         scheme,
-		clients:        map[schema.GroupVersion]restclient.Interface{},
+		protobuf:       true,
+		clients:        map[clientKey]restclient.Interface{},
 		codecfactory:   serializer.NewCodecFactory(scheme),
 		parametercodec: runtime.NewParameterCodec(scheme),
 	}
@@ -47,7 +60,24 @@ func NewClients(config restclient.Config, scheme *runtime.Scheme) *Clients {
 }
 
 func (c *Clients) NewFor(config restclient.Config) *Clients {
-	return NewClients(config, c.scheme)
+	clients := NewClients(config, c.scheme)
+	clients.protobuf = c.protobuf
+	return clients
+}
+
+// SetProtobuf enables or disables the negotiation of protobuf for the
+// built-in API groups. It must be called before the first client is created.
+func (c *Clients) SetProtobuf(enabled bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.protobuf = enabled
+}
+
+// UsesProtobuf reports whether protobuf is used for a group version. This
+// is the case for the API groups known by the kubernetes client, custom
+// resources only support JSON.
+func (c *Clients) UsesProtobuf(gv schema.GroupVersion) bool {
+	return c.protobuf && kscheme.Scheme.IsVersionRegistered(gv)
 }
 
 func (c *Clients) GetCodecFactory() serializer.CodecFactory {
@@ -58,14 +88,32 @@ func (c *Clients) GetParameterCodec() runtime.ParameterCodec {
 	return c.parametercodec
 }
 
+// GetClient returns the client for typed objects of a group version,
+// which uses protobuf for the built-in API groups.
 func (c *Clients) GetClient(gv schema.GroupVersion) (restclient.Interface, error) {
+	return c.getClient(gv, false)
+}
+
+// GetJSONClient returns a client for a group version always using JSON,
+// as required for unstructured objects.
+func (c *Clients) GetJSONClient(gv schema.GroupVersion) (restclient.Interface, error) {
+	return c.getClient(gv, true)
+}
+
+func (c *Clients) getClient(gv schema.GroupVersion, json bool) (restclient.Interface, error) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 	var err error
-	client := c.clients[gv]
+	json = json || !c.protobuf || !kscheme.Scheme.IsVersionRegistered(gv)
+	key := clientKey{gv, json}
+	client := c.clients[key]
 	if client == nil {
 		config := c.config
 		config.GroupVersion = &gv
+		if !json {
+			config.ContentType = ContentTypeProtobuf
+			config.AcceptContentTypes = ContentTypeProtobuf + "," + ContentTypeJSON
+		}
 		if gv.Group == "" {
 			config.APIPath = "/api"
 
@@ -81,7 +129,7 @@ func (c *Clients) GetClient(gv schema.GroupVersion) (restclient.Interface, error
 		if err != nil {
 			return nil, err
 		}
-		c.clients[gv] = client
+		c.clients[key] = client
 	}
 	return client, nil
 }
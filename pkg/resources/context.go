@@ -28,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 )
 
 type ResourceContext interface {
@@ -43,6 +44,12 @@ type ResourceContext interface {
 	Get(gvk schema.GroupVersionKind) (*Info, error)
 
 	GetServerVersion() *semver.Version
+
+	InvalidateDiscovery()
+	WatchDiscoveryChanges() error
+
+	Impersonation() restclient.ImpersonationConfig
+	Impersonate(cfg restclient.ImpersonationConfig) ResourceContext
 }
 
 type resourceContext struct {
@@ -54,8 +61,14 @@ type resourceContext struct {
 	lock                  sync.Mutex
 	ctx                   context.Context
 	defaultResync         time.Duration
+	listChunkSize         int64
+	namespaces            []string
 	resources             *_resources
 	sharedInformerFactory *sharedInformerFactory
+	indexers              map[schema.GroupKind]cache.Indexers
+	transforms            map[schema.GroupKind][]TransformFunc
+	impersonated          map[string]*resourceContext
+	validators            map[schema.GroupKind]ObjectValidator
 }
 
 func NewResourceContext(ctx context.Context, c Cluster, scheme *runtime.Scheme, defaultResync time.Duration) (ResourceContext, error) {
@@ -67,13 +80,19 @@ func NewResourceContext(ctx context.Context, c Cluster, scheme *runtime.Scheme,
 	if err != nil {
 		return nil, err
 	}
+	clients := NewClients(c.Config(), scheme)
+	if ctx.Value(ATTR_DISABLE_PROTOBUF) == true {
+		clients.SetProtobuf(false)
+	}
 	return &resourceContext{
 		Scheme:        scheme,
 		Cluster:       c,
 		ResourceInfos: res,
-		Clients:       NewClients(c.Config(), scheme),
+		Clients:       clients,
 		ctx:           ctx,
 		defaultResync: defaultResync,
+		listChunkSize: listChunkSize(ctx.Value(ATTR_LIST_CHUNK_SIZE)),
+		namespaces:    namespaces(ctx.Value(ATTR_NAMESPACES)),
 	}, nil
 
 }
@@ -115,6 +134,14 @@ func (c *resourceContext) SharedInformerFactory() SharedInformerFactory {
 	return c.sharedInformerFactory
 }
 
+// fieldManager is the default field manager for server side apply.
+func (c *resourceContext) fieldManager() string {
+	if r, ok := c.Resources().(*_resources); ok {
+		return r.eventSource
+	}
+	return ""
+}
+
 func (c *resourceContext) Resources() Resources {
 	c.SharedInformerFactory()
 
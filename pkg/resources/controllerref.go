@@ -0,0 +1,217 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// GetControllerOf returns the controller reference of an object or nil.
+func GetControllerOf(data metav1.Object) *metav1.OwnerReference {
+	return metav1.GetControllerOf(data)
+}
+
+// IsControlledBy reports whether an object is controlled by the given
+// owner. The owner is identified by its uid, so that a recreated owner
+// with the same name is not accepted.
+func IsControlledBy(data metav1.Object, owner metav1.Object) bool {
+	ref := GetControllerOf(data)
+	return ref != nil && ref.UID == owner.GetUID()
+}
+
+// SetControllerReference sets the owner as controller of an object.
+// It fails if the object is controlled by another owner or the owner
+// cannot be referenced by the object.
+func SetControllerReference(data metav1.Object, owner Object) (bool, error) {
+	if owner.GetUID() == "" {
+		return false, fmt.Errorf("owner %s has no uid", owner.Description())
+	}
+	if owner.GetNamespace() != "" && owner.GetNamespace() != data.GetNamespace() {
+		return false, fmt.Errorf("owner %s must be in namespace %q", owner.Description(), data.GetNamespace())
+	}
+	if ref := GetControllerOf(data); ref != nil {
+		if ref.UID == owner.GetUID() {
+			return false, nil
+		}
+		return false, fmt.Errorf("object %s/%s is already controlled by %s %s", data.GetNamespace(), data.GetName(), ref.Kind, ref.Name)
+	}
+	ref := *owner.GetOwnerReference()
+	refs := []metav1.OwnerReference{}
+	for _, r := range data.GetOwnerReferences() {
+		if r.UID != ref.UID {
+			refs = append(refs, r)
+		}
+	}
+	data.SetOwnerReferences(append(refs, ref))
+	return true, nil
+}
+
+// RemoveControllerReference removes the controller reference of the given
+// owner from an object.
+func RemoveControllerReference(data metav1.Object, owner metav1.Object) bool {
+	refs := []metav1.OwnerReference{}
+	found := false
+	for _, r := range data.GetOwnerReferences() {
+		if r.UID == owner.GetUID() && r.Controller != nil && *r.Controller {
+			found = true
+		} else {
+			refs = append(refs, r)
+		}
+	}
+	if found {
+		data.SetOwnerReferences(refs)
+	}
+	return found
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// ControllerRefManager claims the children of an owner: children matching
+// the selector without controller are adopted, children controlled by the
+// owner but not matching the selector any more are released.
+// Before the first adoption the owner is read from the server to assure
+// that it still exists with the same uid and is not being deleted.
+type ControllerRefManager struct {
+	lock     sync.Mutex
+	owner    Object
+	selector labels.Selector
+	checked  bool
+	checkErr error
+}
+
+func NewControllerRefManager(owner Object, selector labels.Selector) *ControllerRefManager {
+	return &ControllerRefManager{owner: owner, selector: selector}
+}
+
+// ClaimObjects claims the given children and returns the children
+// controlled by the owner after adoptions and releases.
+func (this *ControllerRefManager) ClaimObjects(objs []Object) ([]Object, error) {
+	claimed := []Object{}
+	errs := []error{}
+	for _, obj := range objs {
+		ok, err := this.ClaimObject(obj)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			claimed = append(claimed, obj)
+		}
+	}
+	return claimed, utilerrors.NewAggregate(errs)
+}
+
+// ClaimObject claims a child and reports whether it is controlled by the
+// owner afterwards.
+func (this *ControllerRefManager) ClaimObject(obj Object) (bool, error) {
+	ref := GetControllerOf(obj)
+	matches := this.selector.Matches(labels.Set(obj.GetLabels()))
+	if ref != nil {
+		if ref.UID != this.owner.GetUID() {
+			// controlled by someone else
+			return false, nil
+		}
+		if matches {
+			return true, nil
+		}
+		if this.owner.IsDeleting() {
+			return false, nil
+		}
+		if err := this.ReleaseObject(obj); err != nil {
+			if errors.IsNotFound(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return false, nil
+	}
+	if this.owner.IsDeleting() || !matches || obj.IsDeleting() {
+		return false, nil
+	}
+	if err := this.AdoptObject(obj); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// AdoptObject sets the owner as controller of an orphan.
+func (this *ControllerRefManager) AdoptObject(obj Object) error {
+	if err := this.canAdopt(); err != nil {
+		return fmt.Errorf("cannot adopt %s: %s", obj.Description(), err)
+	}
+	uid := obj.GetUID()
+	_, err := obj.ModifyByOptimisticPatch(func(data ObjectData) (bool, error) {
+		if err := checkUID(data, uid); err != nil {
+			return false, err
+		}
+		return SetControllerReference(data, this.owner)
+	})
+	return err
+}
+
+// ReleaseObject removes the controller reference of the owner from a child.
+func (this *ControllerRefManager) ReleaseObject(obj Object) error {
+	uid := obj.GetUID()
+	_, err := obj.ModifyByOptimisticPatch(func(data ObjectData) (bool, error) {
+		if err := checkUID(data, uid); err != nil {
+			return false, err
+		}
+		return RemoveControllerReference(data, this.owner), nil
+	})
+	return err
+}
+
+func (this *ControllerRefManager) canAdopt() error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if !this.checked {
+		this.checked = true
+		this.checkErr = this.checkOwner()
+	}
+	return this.checkErr
+}
+
+func (this *ControllerRefManager) checkOwner() error {
+	fresh, err := this.owner.GetResource().Live().Get(this.owner.ObjectName())
+	if err != nil {
+		return err
+	}
+	if fresh.GetUID() != this.owner.GetUID() {
+		return fmt.Errorf("original %s is gone: got uid %s, wanted %s", this.owner.Description(), fresh.GetUID(), this.owner.GetUID())
+	}
+	if fresh.IsDeleting() {
+		return fmt.Errorf("%s is being deleted", this.owner.Description())
+	}
+	return nil
+}
+
+func checkUID(data metav1.Object, uid types.UID) error {
+	if data.GetUID() != uid {
+		return fmt.Errorf("object %s/%s has been replaced", data.GetNamespace(), data.GetName())
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// WithPreconditions returns the options with preconditions for the uid
+// and resource version of the given object. This way an object is only
+// deleted if it has not been replaced or changed in the meantime.
+func (this DeleteOptions) WithPreconditions(obj metav1.Object) DeleteOptions {
+	this.UID = obj.GetUID()
+	this.ResourceVersion = obj.GetResourceVersion()
+	return this
+}
+
+func (this DeleteOptions) deleteOptions() *metav1.DeleteOptions {
+	opts := &metav1.DeleteOptions{
+		GracePeriodSeconds: this.GracePeriodSeconds,
+	}
+	if this.PropagationPolicy != "" {
+		policy := this.PropagationPolicy
+		opts.PropagationPolicy = &policy
+	}
+	if this.UID != "" || this.ResourceVersion != "" {
+		opts.Preconditions = &metav1.Preconditions{}
+		if this.UID != "" {
+			uid := this.UID
+			opts.Preconditions.UID = &uid
+		}
+		if this.ResourceVersion != "" {
+			rv := this.ResourceVersion
+			opts.Preconditions.ResourceVersion = &rv
+		}
+	}
+	return opts
+}
+
+func (this *AbstractResource) DeleteWith(obj ObjectDataName, opts DeleteOptions) error {
+	if o, ok := obj.(Object); ok {
+		obj = o.Data()
+	}
+	return this.self.I_delete(obj, opts)
+}
+
+func (this *AbstractObject) DeleteWith(opts DeleteOptions) error {
+	return this.self.I_resource().I_delete(this, opts)
+}
+
+func (this *_resources) DeleteObjectWith(obj ObjectData, opts DeleteOptions) error {
+	r, err := this.GetByExample(obj)
+	if err != nil {
+		return err
+	}
+	return r.DeleteWith(obj, opts)
+}
@@ -0,0 +1,283 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ServerManagedFields are the metadata fields maintained by the API
+// server, which are ignored by ObjectDiff.
+var ServerManagedFields = []string{
+	"uid", "resourceVersion", "generation", "creationTimestamp", "deletionTimestamp",
+	"deletionGracePeriodSeconds", "managedFields", "selfLink",
+}
+
+// DiffOptions control the comparison of ObjectDiff.
+type DiffOptions struct {
+	// Strict reports fields set in the actual, but not in the desired
+	// object. By default such fields are considered as defaulted by the
+	// API server and ignored. Additional list entries are always reported.
+	Strict bool
+	// IncludeStatus compares the status, too.
+	IncludeStatus bool
+	// IgnorePaths are field paths (for example "metadata.annotations")
+	// excluded from the comparison, including their sub fields.
+	IgnorePaths []string
+}
+
+// Difference is a difference of a field of two objects.
+// A nil value means the field is not set.
+type Difference struct {
+	Path    string
+	Desired interface{}
+	Actual  interface{}
+}
+
+func (this Difference) String() string {
+	return fmt.Sprintf("%s: %s (desired %s)", this.Path, diffValueString(this.Actual), diffValueString(this.Desired))
+}
+
+// Diff is the field path based difference of two objects.
+type Diff []Difference
+
+func (this Diff) Empty() bool {
+	return len(this) == 0
+}
+
+// Paths returns the field paths of the differences.
+func (this Diff) Paths() []string {
+	paths := []string{}
+	for _, d := range this {
+		paths = append(paths, d.Path)
+	}
+	return paths
+}
+
+func (this Diff) String() string {
+	lines := []string{}
+	for _, d := range this {
+		lines = append(lines, d.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// NeedsUpdate reports whether the actual object differs from the desired
+// one according to ObjectDiff, and returns the differences, which can be
+// logged to explain the update.
+func NeedsUpdate(desired, actual runtime.Object, opts DiffOptions) (bool, Diff, error) {
+	diff, err := ObjectDiff(desired, actual, opts)
+	if err != nil {
+		return false, nil, err
+	}
+	return !diff.Empty(), diff, nil
+}
+
+// ObjectDiff determines the semantic differences between a desired and an
+// actual object. Fields not set in the desired object are assumed to be
+// defaulted by the API server and ignored (unless Strict is set), the
+// fields maintained by the API server and the status are always ignored.
+// Quantities are compared by their value.
+func ObjectDiff(desired, actual runtime.Object, opts DiffOptions) (Diff, error) {
+	d, err := diffContent(desired, opts)
+	if err != nil {
+		return nil, err
+	}
+	a, err := diffContent(actual, opts)
+	if err != nil {
+		return nil, err
+	}
+	diff := Diff{}
+	diffValue(nil, d, a, &opts, &diff)
+	return diff, nil
+}
+
+func diffContent(obj runtime.Object, opts DiffOptions) (map[string]interface{}, error) {
+	var content map[string]interface{}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		content = runtime.DeepCopyJSON(u.UnstructuredContent())
+	} else {
+		var err error
+		content, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// type meta is not always set for typed objects
+	delete(content, "apiVersion")
+	delete(content, "kind")
+	if !opts.IncludeStatus {
+		delete(content, "status")
+	}
+	if meta, ok := content["metadata"].(map[string]interface{}); ok {
+		for _, f := range ServerManagedFields {
+			delete(meta, f)
+		}
+	}
+	return content, nil
+}
+
+func diffValue(path *field.Path, desired, actual interface{}, opts *DiffOptions, diff *Diff) {
+	if path != nil && ignoredPath(path.String(), opts.IgnorePaths) {
+		return
+	}
+	switch d := desired.(type) {
+	case nil:
+		if opts.Strict && actual != nil {
+			diff.add(path, nil, actual)
+		}
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			diff.add(path, desired, actual)
+			return
+		}
+		keys := map[string]bool{}
+		for k := range d {
+			keys[k] = true
+		}
+		if opts.Strict {
+			for k := range a {
+				keys[k] = true
+			}
+		}
+		for _, k := range sortedStringKeys(keys) {
+			diffValue(diffChild(path, k), d[k], a[k], opts, diff)
+		}
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			diff.add(path, desired, actual)
+			return
+		}
+		for i := 0; i < len(d) || i < len(a); i++ {
+			var de, ae interface{}
+			if i < len(d) {
+				de = d[i]
+			}
+			if i < len(a) {
+				ae = a[i]
+			}
+			if i >= len(d) {
+				diff.add(pathIndex(path, i), nil, ae)
+				continue
+			}
+			diffValue(pathIndex(path, i), de, ae, opts, diff)
+		}
+	default:
+		if !equalScalars(desired, actual) {
+			diff.add(path, desired, actual)
+		}
+	}
+}
+
+func (this *Diff) add(path *field.Path, desired, actual interface{}) {
+	p := ""
+	if path != nil {
+		p = path.String()
+	}
+	*this = append(*this, Difference{Path: p, Desired: desired, Actual: actual})
+}
+
+func diffChild(path *field.Path, name string) *field.Path {
+	if path == nil {
+		return field.NewPath(name)
+	}
+	if strings.ContainsAny(name, "./") {
+		return path.Key(name)
+	}
+	return path.Child(name)
+}
+
+func pathIndex(path *field.Path, i int) *field.Path {
+	if path == nil {
+		return field.NewPath("").Index(i)
+	}
+	return path.Index(i)
+}
+
+func ignoredPath(path string, ignored []string) bool {
+	for _, i := range ignored {
+		if path == i || strings.HasPrefix(path, i+".") || strings.HasPrefix(path, i+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+func equalScalars(a, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	if fa, ok := diffNumber(a); ok {
+		if fb, ok := diffNumber(b); ok {
+			return fa == fb
+		}
+	}
+	if sa, ok := a.(string); ok {
+		if sb, ok := b.(string); ok {
+			qa, err := resource.ParseQuantity(sa)
+			if err != nil {
+				return false
+			}
+			qb, err := resource.ParseQuantity(sb)
+			return err == nil && qa.Cmp(qb) == 0
+		}
+	}
+	return false
+}
+
+func diffNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func diffValueString(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "<unset>"
+	case map[string]interface{}, []interface{}:
+		return fmt.Sprintf("%v", v)
+	}
+	return fmt.Sprintf("%#v", v)
+}
+
+func sortedStringKeys(m map[string]bool) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	"github.com/gardener/controller-manager-library/pkg/logger"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+// GroupKinds of resources whose changes affect the discovery information
+var (
+	CustomResourceDefinitionGroupKind = schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}
+	APIServiceGroupKind               = schema.GroupKind{Group: "apiregistration.k8s.io", Kind: "APIService"}
+)
+
+// InvalidateDiscovery marks the cached discovery information and
+// REST mappings of the cluster as stale.
+func (c *resourceContext) InvalidateDiscovery() {
+	c.ResourceInfos.Invalidate()
+}
+
+// WatchDiscoveryChanges watches custom resource definitions and
+// api services and invalidates the cached discovery information on changes.
+// This way resources installed after the start are found without restart.
+func (c *resourceContext) WatchDiscoveryChanges() error {
+	handlers := cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.InvalidateDiscovery()
+		},
+		UpdateFunc: func(old, new interface{}) {
+			if resourceVersion(old) != resourceVersion(new) {
+				c.InvalidateDiscovery()
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.InvalidateDiscovery()
+		},
+	}
+	for _, gk := range []schema.GroupKind{CustomResourceDefinitionGroupKind, APIServiceGroupKind} {
+		if _, err := c.GetPreferred(gk); err != nil {
+			logger.Infof("%s not served by cluster %s: skipping discovery watch", gk, c.GetName())
+			continue
+		}
+		r, err := c.Resources().GetUnstructuredByGK(gk)
+		if err != nil {
+			return err
+		}
+		if err := r.AddRawEventHandler(handlers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resourceVersion(obj interface{}) string {
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return ""
+	}
+	return o.GetResourceVersion()
+}
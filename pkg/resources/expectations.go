@@ -0,0 +1,159 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/logger"
+)
+
+// DefaultExpectationsTimeout is the time after which unfulfilled
+// expectations are considered satisfied, to recover from missed events.
+const DefaultExpectationsTimeout = 5 * time.Minute
+
+// Expectations keeps track of the creations and deletions of children
+// issued for an owner, which have not yet been observed in the cache
+// (like the expectations of the ReplicaSet controller).
+// A reconciler records the number of children it just created or deleted,
+// the watch of the children reports the observation of these objects.
+// As long as the expectations of an owner are not satisfied, the cache
+// does not reflect the actual state, and a reconcilation of the owner
+// must not create or delete further children, to avoid duplicates under
+// cache lag.
+type Expectations struct {
+	lock    sync.Mutex
+	timeout time.Duration
+	owners  map[ClusterObjectKey]*expectation
+}
+
+type expectation struct {
+	adds      int64
+	dels      int64
+	timestamp time.Time
+}
+
+func (this *expectation) fulfilled() bool {
+	return this.adds <= 0 && this.dels <= 0
+}
+
+// NewExpectations creates expectations expiring after the given timeout,
+// DefaultExpectationsTimeout is used for a non-positive timeout.
+func NewExpectations(timeout time.Duration) *Expectations {
+	if timeout <= 0 {
+		timeout = DefaultExpectationsTimeout
+	}
+	return &Expectations{timeout: timeout, owners: map[ClusterObjectKey]*expectation{}}
+}
+
+// ExpectCreations sets the number of expected creations for an owner,
+// replacing former expectations.
+func (this *Expectations) ExpectCreations(owner ClusterObjectKey, n int) {
+	this.set(owner, int64(n), 0)
+}
+
+// ExpectDeletions sets the number of expected deletions for an owner,
+// replacing former expectations.
+func (this *Expectations) ExpectDeletions(owner ClusterObjectKey, n int) {
+	this.set(owner, 0, int64(n))
+}
+
+func (this *Expectations) set(owner ClusterObjectKey, adds, dels int64) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.owners[owner] = &expectation{adds: adds, dels: dels, timestamp: time.Now()}
+}
+
+// RaiseExpectations increases the expected creations and deletions for
+// an owner.
+func (this *Expectations) RaiseExpectations(owner ClusterObjectKey, adds, dels int) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	e := this.owners[owner]
+	if e == nil {
+		e = &expectation{}
+		this.owners[owner] = e
+	}
+	e.adds += int64(adds)
+	e.dels += int64(dels)
+	e.timestamp = time.Now()
+}
+
+// LowerExpectations decreases the expected creations and deletions for
+// an owner, for example for failed requests.
+func (this *Expectations) LowerExpectations(owner ClusterObjectKey, adds, dels int) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if e := this.owners[owner]; e != nil {
+		e.adds -= int64(adds)
+		e.dels -= int64(dels)
+	}
+}
+
+// CreationObserved reports the observation of a created child of an owner.
+func (this *Expectations) CreationObserved(owner ClusterObjectKey) {
+	this.LowerExpectations(owner, 1, 0)
+}
+
+// DeletionObserved reports the observation of a deleted child of an owner.
+func (this *Expectations) DeletionObserved(owner ClusterObjectKey) {
+	this.LowerExpectations(owner, 0, 1)
+}
+
+// Satisfied reports whether the cache reflects all creations and deletions
+// issued for an owner. This is the case if no expectations are recorded,
+// all expected events have been observed or the expectations expired.
+func (this *Expectations) Satisfied(owner ClusterObjectKey) bool {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	e := this.owners[owner]
+	if e == nil || e.fulfilled() {
+		return true
+	}
+	if time.Now().Sub(e.timestamp) > this.timeout {
+		logger.Warnf("expectations for %s expired (%d creations, %d deletions pending)", owner, e.adds, e.dels)
+		return true
+	}
+	return false
+}
+
+// Pending returns the number of expected, but not yet observed creations
+// and deletions for an owner.
+func (this *Expectations) Pending(owner ClusterObjectKey) (adds, dels int) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if e := this.owners[owner]; e != nil {
+		return int(e.adds), int(e.dels)
+	}
+	return 0, 0
+}
+
+// DeleteOwner removes the expectations of an owner, for example if the
+// owner is deleted.
+func (this *Expectations) DeleteOwner(owner ClusterObjectKey) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	delete(this.owners, owner)
+}
+
+// Size returns the number of owners with recorded expectations.
+func (this *Expectations) Size() int {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	return len(this.owners)
+}
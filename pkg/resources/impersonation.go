@@ -0,0 +1,90 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	restclient "k8s.io/client-go/rest"
+)
+
+// ImpersonateServiceAccount returns the impersonation configuration to
+// act on behalf of a service account.
+func ImpersonateServiceAccount(namespace, name string) restclient.ImpersonationConfig {
+	return restclient.ImpersonationConfig{
+		UserName: fmt.Sprintf("system:serviceaccount:%s:%s", namespace, name),
+		Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:" + namespace, "system:authenticated"},
+	}
+}
+
+// Impersonation returns the impersonation configuration used by the
+// clients of the resource context.
+func (c *resourceContext) Impersonation() restclient.ImpersonationConfig {
+	return c.Clients.config.Impersonate
+}
+
+// Impersonate returns a resource context using the given impersonation
+// for all requests. It shares the discovery information with this context,
+// but uses its own clients and informers. Contexts are reused for the
+// same impersonation. An empty impersonation returns this context.
+func (c *resourceContext) Impersonate(cfg restclient.ImpersonationConfig) ResourceContext {
+	key := impersonationKey(cfg)
+	if key == "" {
+		return c
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.impersonated == nil {
+		c.impersonated = map[string]*resourceContext{}
+	}
+	if r := c.impersonated[key]; r != nil {
+		return r
+	}
+	config := c.Clients.config
+	config.Impersonate = cfg
+	r := &resourceContext{
+		Scheme:        c.Scheme,
+		Cluster:       c.Cluster,
+		ResourceInfos: c.ResourceInfos,
+		Clients:       c.Clients.NewFor(config),
+		ctx:           c.ctx,
+		defaultResync: c.defaultResync,
+		listChunkSize: c.listChunkSize,
+		namespaces:    c.namespaces,
+	}
+	c.impersonated[key] = r
+	return r
+}
+
+func impersonationKey(cfg restclient.ImpersonationConfig) string {
+	if cfg.UserName == "" && len(cfg.Groups) == 0 && len(cfg.Extra) == 0 {
+		return ""
+	}
+	groups := append([]string{}, cfg.Groups...)
+	sort.Strings(groups)
+	extra := []string{}
+	for k, v := range cfg.Extra {
+		values := append([]string{}, v...)
+		sort.Strings(values)
+		extra = append(extra, k+"="+strings.Join(values, ","))
+	}
+	sort.Strings(extra)
+	return cfg.UserName + "|" + strings.Join(groups, ",") + "|" + strings.Join(extra, ";")
+}
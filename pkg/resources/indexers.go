@@ -0,0 +1,201 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+// IndexFunc determines the index values of an object.
+type IndexFunc func(obj ObjectData) ([]string, error)
+
+// FieldIndex returns an index function indexing objects by the values of
+// the field with the given dot separated path (e.g. "spec.secretName").
+// Lists found on the path are traversed, so "spec.tls.secretName" indexes
+// all secret names of an ingress.
+func FieldIndex(path string) IndexFunc {
+	fields := strings.Split(path, ".")
+	return func(obj ObjectData) ([]string, error) {
+		var data map[string]interface{}
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			data = u.Object
+		} else {
+			var err error
+			data, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return fieldValues(data, fields, nil), nil
+	}
+}
+
+func fieldValues(v interface{}, path []string, values []string) []string {
+	switch t := v.(type) {
+	case nil:
+		return values
+	case []interface{}:
+		for _, e := range t {
+			values = fieldValues(e, path, values)
+		}
+		return values
+	case map[string]interface{}:
+		if len(path) == 0 {
+			return values
+		}
+		return fieldValues(t[path[0]], path[1:], values)
+	default:
+		if len(path) > 0 {
+			return values
+		}
+		if s := fmt.Sprintf("%v", t); s != "" {
+			values = append(values, s)
+		}
+		return values
+	}
+}
+
+func (this IndexFunc) cacheIndexFunc() cache.IndexFunc {
+	return func(obj interface{}) ([]string, error) {
+		data, ok := obj.(ObjectData)
+		if !ok {
+			return nil, nil
+		}
+		return this(data)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// AddIndexer registers an index for the cached objects of the resource.
+// Indices are shared by all users of a cluster, an index already registered
+// under the given name is kept. Indices must be registered before the
+// informers of the resource are started.
+func (this *_resource) AddIndexer(name string, f IndexFunc) error {
+	return this.context.addIndexer(this.GroupKind(), name, f)
+}
+
+// ListCachedByIndex lists the cached objects with the given value for the
+// index registered under the given name.
+func (this *_resource) ListCachedByIndex(name, value string) (ret []Object, err error) {
+	informer, err := this.self.I_getInformer("", nil)
+	if err != nil {
+		return nil, err
+	}
+	list, err := informer.GetIndexer().ByIndex(name, value)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range list {
+		ret = append(ret, this.helper.ObjectAsResource(obj.(ObjectData)))
+	}
+	return ret, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func (c *resourceContext) addIndexer(gk schema.GroupKind, name string, f IndexFunc) error {
+	if name == cache.NamespaceIndex {
+		return fmt.Errorf("index name %q is reserved", name)
+	}
+	c.lock.Lock()
+	if c.indexers == nil {
+		c.indexers = map[schema.GroupKind]cache.Indexers{}
+	}
+	indexers := c.indexers[gk]
+	if indexers == nil {
+		indexers = cache.Indexers{}
+		c.indexers[gk] = indexers
+	}
+	if _, ok := indexers[name]; ok {
+		c.lock.Unlock()
+		return nil
+	}
+	indexers[name] = f.cacheIndexFunc()
+	factory := c.sharedInformerFactory
+	c.lock.Unlock()
+
+	if factory == nil {
+		return nil
+	}
+	add := cache.Indexers{name: indexers[name]}
+	if err := factory.structured.addIndexers(gk, add); err != nil {
+		return err
+	}
+	return factory.unstructured.addIndexers(gk, add)
+}
+
+// getIndexers returns the indexers registered for a group kind.
+func (c *resourceContext) getIndexers(gk schema.GroupKind) cache.Indexers {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	indexers := cache.Indexers{}
+	for n, f := range c.indexers[gk] {
+		indexers[n] = f
+	}
+	return indexers
+}
+
+func (f *sharedFilteredInformerFactory) addIndexers(gk schema.GroupKind, indexers cache.Indexers) error {
+	f.lock.Lock()
+	factories := make([]*genericInformerFactory, 0, len(f.filters))
+	for _, factory := range f.filters {
+		factories = append(factories, factory)
+	}
+	f.lock.Unlock()
+
+	for _, factory := range factories {
+		if err := factory.addIndexers(gk, indexers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addIndexers adds indexers to the already created informers for a
+// group kind. Informers created later get them from the resource context.
+func (f *genericInformerFactory) addIndexers(gk schema.GroupKind, indexers cache.Indexers) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for gvk, informer := range f.informers {
+		if gvk.GroupKind() != gk {
+			continue
+		}
+		existing := informer.GetIndexer().GetIndexers()
+		add := cache.Indexers{}
+		for n, i := range indexers {
+			if _, ok := existing[n]; !ok {
+				add[n] = i
+			}
+		}
+		if len(add) == 0 {
+			continue
+		}
+		if err := informer.AddIndexers(add); err != nil {
+			return fmt.Errorf("cannot add indexers for %s: %s", gvk, err)
+		}
+	}
+	return nil
+}
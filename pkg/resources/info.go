@@ -95,6 +95,7 @@ type ResourceInfos struct {
 	cluster           Cluster
 	mapper            meta.RESTMapper
 	version           *semver.Version
+	stale             bool
 }
 
 func NewResourceInfos(c Cluster) (*ResourceInfos, error) {
@@ -107,14 +108,52 @@ func NewResourceInfos(c Cluster) (*ResourceInfos, error) {
 	return res, err
 }
 
+// Invalidate marks the cached discovery information as stale. It is
+// refreshed with the next lookup.
+func (this *ResourceInfos) Invalidate() {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if !this.stale {
+		logger.Infof("discovery information for cluster %s invalidated", this.cluster.GetName())
+	}
+	this.stale = true
+}
+
+// refreshIfStale refreshes invalidated discovery information.
+func (this *ResourceInfos) refreshIfStale() {
+	this.lock.RLock()
+	stale := this.stale
+	this.lock.RUnlock()
+	if stale {
+		if err := this.update(); err != nil {
+			logger.Warnf("refresh of discovery information for cluster %s failed: %s", this.cluster.GetName(), err)
+		}
+	}
+}
+
 func (this *ResourceInfos) RESTMapping(gk schema.GroupKind, versions ...string) (*meta.RESTMapping, error) {
-	m, err := this.mapper.RESTMapping(gk, versions...)
+	this.refreshIfStale()
+	this.lock.RLock()
+	mapper := this.mapper
+	this.lock.RUnlock()
+	if mapper == nil {
+		if err := this.updateRestMapper(); err != nil {
+			return nil, err
+		}
+		this.lock.RLock()
+		mapper = this.mapper
+		this.lock.RUnlock()
+	}
+	m, err := mapper.RESTMapping(gk, versions...)
 	if err != nil {
 		err = this.updateRestMapper()
 		if err != nil {
 			return nil, err
 		}
-		m, err = this.mapper.RESTMapping(gk, versions...)
+		this.lock.RLock()
+		mapper = this.mapper
+		this.lock.RUnlock()
+		m, err = mapper.RESTMapping(gk, versions...)
 	}
 	return m, err
 }
@@ -129,7 +168,10 @@ func (this *ResourceInfos) updateRestMapper() error {
 	if err != nil {
 		return err
 	}
-	this.mapper = restmapper.NewDiscoveryRESTMapper(gr)
+	mapper := restmapper.NewDiscoveryRESTMapper(gr)
+	this.lock.Lock()
+	this.mapper = mapper
+	this.lock.Unlock()
 	return nil
 }
 
@@ -147,6 +189,9 @@ func (this *ResourceInfos) update() error {
 	}
 	//list, err := discovery.ServerResources(dc)
 	list, err := dc.ServerResources()
+	// on partial failures the information of the failed groups is kept,
+	// otherwise the information is replaced to get rid of vanished resources
+	partial := err != nil
 	if err != nil {
 		logger.Warnf("failed to get all server resources for cluster %s: %s", this.cluster.GetName(), err)
 		if len(list) == 0 {
@@ -154,16 +199,23 @@ func (this *ResourceInfos) update() error {
 		}
 		logger.Infof("found %d resources", len(list))
 	}
+	groupVersionKinds := map[schema.GroupVersion]map[string]*Info{}
+	preferredVersions := map[string]string{}
 	this.lock.Lock()
 	defer this.lock.Unlock()
+	if partial || !this.stale {
+		for gv, m := range this.groupVersionKinds {
+			groupVersionKinds[gv] = m
+		}
+		for g, v := range this.preferredVersions {
+			preferredVersions[g] = v
+		}
+	}
 	for _, rl := range list {
 		gv, _ := schema.ParseGroupVersion(rl.GroupVersion)
 
-		m := this.groupVersionKinds[gv]
-		if m == nil {
-			m = map[string]*Info{}
-			this.groupVersionKinds[gv] = m
-		}
+		m := map[string]*Info{}
+		groupVersionKinds[gv] = m
 		for _, r := range rl.APIResources {
 			if strings.Index(r.Name, "/") < 0 {
 				m[r.Kind] = &Info{groupVersion: &gv, resourcename: r.Name, kind: r.Kind, namespaced: r.Namespaced, subresources: utils.StringSet{}}
@@ -189,13 +241,19 @@ func (this *ResourceInfos) update() error {
 	}
 	for _, rl := range list {
 		gv, _ := schema.ParseGroupVersion(rl.GroupVersion)
-		this.preferredVersions[gv.Group] = gv.Version
+		preferredVersions[gv.Group] = gv.Version
+	}
+	this.groupVersionKinds = groupVersionKinds
+	this.preferredVersions = preferredVersions
+	if this.stale {
+		this.mapper = nil
+		this.stale = false
 	}
-
 	return nil
 }
 
 func (this *ResourceInfos) GetGroups() []schema.GroupVersion {
+	this.refreshIfStale()
 	this.lock.RLock()
 	defer this.lock.RUnlock()
 	grps := make([]schema.GroupVersion, len(this.preferredVersions))[0:0]
@@ -206,6 +264,7 @@ func (this *ResourceInfos) GetGroups() []schema.GroupVersion {
 }
 
 func (this *ResourceInfos) GetResourceInfos(gv schema.GroupVersion) []*Info {
+	this.refreshIfStale()
 	this.lock.RLock()
 	defer this.lock.RUnlock()
 	m := this.groupVersionKinds[gv]
@@ -220,6 +279,7 @@ func (this *ResourceInfos) GetResourceInfos(gv schema.GroupVersion) []*Info {
 }
 
 func (this *ResourceInfos) GetPreferred(gk schema.GroupKind) (*Info, error) {
+	this.refreshIfStale()
 	i := this.getPreferred(gk)
 	if i == nil {
 		err := this.update()
@@ -249,6 +309,7 @@ func (this *ResourceInfos) getPreferred(gk schema.GroupKind) *Info {
 }
 
 func (this *ResourceInfos) Get(gvk schema.GroupVersionKind) (*Info, error) {
+	this.refreshIfStale()
 	i := this.get(gvk)
 	if i == nil {
 		err := this.update()
@@ -49,6 +49,7 @@ type genericInformerFactory struct {
 	context     *resourceContext
 	optionsFunc TweakListOptionsFunc
 	namespace   string
+	dedicated   bool
 
 	defaultResync time.Duration
 	informers     map[schema.GroupVersionKind]GenericInformer
@@ -118,7 +119,7 @@ func (f *genericInformerFactory) informerFor(informerType reflect.Type, gvk sche
 		return nil, fmt.Errorf("no list type found for %s", informerType)
 	}
 
-	client, err := f.getClient(gvk.GroupVersion())
+	client, err := f.getClient(gvk.GroupVersion(), informerType == unstructuredType)
 	if err != nil {
 		return nil, err
 	}
@@ -144,28 +145,45 @@ func (f *genericInformerFactory) queryInformerFor(informerType reflect.Type, gvk
 	return nil
 }
 
-func (f *genericInformerFactory) getClient(gv schema.GroupVersion) (restclient.Interface, error) {
+func (f *genericInformerFactory) getClient(gv schema.GroupVersion, json bool) (restclient.Interface, error) {
+	if json {
+		return f.context.GetJSONClient(gv)
+	}
 	return f.context.GetClient(gv)
 }
 
 func (f *genericInformerFactory) newInformer(client restclient.Interface, res *Info, elemType reflect.Type, listType reflect.Type) GenericInformer {
 	logger.Infof("new generic informer for %s (%s) %s (%d seconds)", elemType, res.GroupVersionKind(), listType, f.defaultResync/time.Second)
-	indexers := cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}
+	indexers := f.context.getIndexers(res.GroupVersionKind().GroupKind())
+	indexers[cache.NamespaceIndex] = cache.MetaNamespaceIndexFunc
+	state := newWatchState(f.informerMetricLabels(res, elemType == unstructuredType)...)
 	informer := cache.NewSharedIndexInformer(
 		&cache.ListWatch{
 			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
 				if f.optionsFunc != nil {
 					f.optionsFunc(&options)
 				}
-				result := reflect.New(listType).Interface().(runtime.Object)
-				r := client.Get().
-					Resource(res.Name()).
-					VersionedParams(&options, f.context.Clients.parametercodec)
-				if res.Namespaced() {
-					r = r.Namespace(f.namespace)
+				sleep(f.context.ctx.Done(), state.relistDelay())
+				newList := func() runtime.Object {
+					return reflect.New(listType).Interface().(runtime.Object)
 				}
-
-				return result, r.Do().Into(result)
+				result, err := listChunked(f.context.listChunkSize, options, newList,
+					func(options metav1.ListOptions, result runtime.Object) error {
+						r := client.Get().
+							Resource(res.Name()).
+							VersionedParams(&options, f.context.Clients.parametercodec)
+						if res.Namespaced() {
+							r = r.Namespace(f.namespace)
+						}
+						return r.Do().Into(result)
+					})
+				if err != nil {
+					return result, err
+				}
+				if transform := f.context.getTransform(res.GroupKind()); transform != nil {
+					return result, transformList(result, transform)
+				}
+				return result, nil
 			},
 			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
 				options.Watch = true
@@ -179,12 +197,19 @@ func (f *genericInformerFactory) newInformer(client restclient.Interface, res *I
 					r = r.Namespace(f.namespace)
 				}
 
-				return r.Watch()
+				w, err := r.Watch()
+				if err != nil {
+					state.watchFailed(err)
+					return nil, err
+				}
+				state.watchAlive("")
+				return state.filter(w, f.context.getTransform(res.GroupKind())), nil
 			},
 		},
 		reflect.New(elemType).Interface().(runtime.Object),
 		f.defaultResync,
 		indexers,
 	)
-	return &genericInformer{informer, res}
+	state.registerMetrics(informer)
+	return &genericInformer{SharedIndexInformer: informer, resource: res, state: state}
 }
@@ -0,0 +1,172 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/metrics"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+var lagBuckets = []float64{.001, .005, .01, .05, .1, .5, 1, 5, 10, 30, 60, 300}
+
+var informerLabels = []string{"cluster", "resource", "namespace", "selection", "informer"}
+
+var (
+	informerLastWatch = metrics.NewGaugeFuncVec("informer_last_watch_timestamp_seconds",
+		"Time of the last successfully started watch or received watch event of an informer", informerLabels...)
+	informerWatchErrors = metrics.NewCounterVec("informer_watch_errors_total",
+		"Number of failed or aborted watches of an informer", informerLabels...)
+	informerRelists = metrics.NewCounterVec("informer_relists_total",
+		"Number of (re-)lists of an informer", informerLabels...)
+	informerCachedObjects = metrics.NewGaugeFuncVec("informer_cached_objects",
+		"Number of objects in the cache of an informer", informerLabels...)
+	informerHandlerLag = metrics.NewHistogramVec("informer_event_handler_lag_seconds",
+		"Time between the reception of a watch event and its delivery to an event handler", lagBuckets, informerLabels...)
+)
+
+func init() {
+	metrics.MustRegister(informerLastWatch, informerWatchErrors, informerRelists, informerCachedObjects, informerHandlerLag)
+}
+
+// maxLagTracking limits the number of watch events whose reception
+// time is kept to measure the event handler lag.
+const maxLagTracking = 10000
+
+var dedicatedInformers int64
+
+// informerMetricLabels returns the metric labels of an informer.
+func (f *genericInformerFactory) informerMetricLabels(res *Info, unstructured bool) []string {
+	selection := ""
+	if f.optionsFunc != nil {
+		opts := metav1.ListOptions{}
+		f.optionsFunc(&opts)
+		selection = strings.Trim(opts.LabelSelector+","+opts.FieldSelector, ",")
+	}
+	kind := "typed"
+	if unstructured {
+		kind = "unstructured"
+	}
+	if f.dedicated {
+		kind = fmt.Sprintf("%s-dedicated-%d", kind, atomic.AddInt64(&dedicatedInformers, 1))
+	}
+	cluster := f.context.GetName()
+	if user := f.context.Impersonation().UserName; user != "" {
+		cluster = fmt.Sprintf("%s(%s)", cluster, user)
+	}
+	return []string{cluster, res.GroupKind().String(), f.namespace, selection, kind}
+}
+
+func (this *watchState) registerMetrics(informer cache.SharedIndexInformer) {
+	if this.labels == nil {
+		return
+	}
+	informerLastWatch.Set(func() float64 {
+		this.lock.Lock()
+		defer this.lock.Unlock()
+		if this.lastWatch.IsZero() {
+			return 0
+		}
+		return float64(this.lastWatch.UnixNano()) / float64(time.Second)
+	}, this.labels...)
+	informerCachedObjects.Set(func() float64 { return float64(len(informer.GetStore().ListKeys())) }, this.labels...)
+	informerWatchErrors.WithLabelValues(this.labels...)
+	informerRelists.WithLabelValues(this.labels...)
+}
+
+func (this *watchState) unregisterMetrics() {
+	if this.labels == nil {
+		return
+	}
+	informerLastWatch.Delete(this.labels...)
+	informerCachedObjects.Delete(this.labels...)
+	informerWatchErrors.Delete(this.labels...)
+	informerRelists.Delete(this.labels...)
+	informerHandlerLag.Delete(this.labels...)
+}
+
+func (this *watchState) countRelist() {
+	if this.labels != nil {
+		informerRelists.WithLabelValues(this.labels...).Inc()
+	}
+}
+
+func (this *watchState) countWatchError() {
+	if this.labels != nil {
+		informerWatchErrors.WithLabelValues(this.labels...).Inc()
+	}
+}
+
+// watchAlive records a successfully started watch or a received event.
+// Events are recorded with their reception time to measure the lag of
+// the event handlers.
+func (this *watchState) watchAlive(rv string) {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	now := time.Now()
+	this.lastWatch = now
+	if rv == "" || this.labels == nil {
+		return
+	}
+	if this.received == nil || len(this.received) >= maxLagTracking {
+		this.received = map[string]time.Time{}
+	}
+	this.received[rv] = now
+}
+
+func (this *watchState) observeLag(obj interface{}) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+	this.lock.Lock()
+	t, ok := this.received[accessor.GetResourceVersion()]
+	this.lock.Unlock()
+	if ok {
+		informerHandlerLag.WithLabelValues(this.labels...).Observe(time.Now().Sub(t).Seconds())
+	}
+}
+
+// lagHandler measures the lag of the events delivered to an event handler.
+type lagHandler struct {
+	cache.ResourceEventHandler
+	state *watchState
+}
+
+func (this *lagHandler) OnAdd(obj interface{}) {
+	this.state.observeLag(obj)
+	this.ResourceEventHandler.OnAdd(obj)
+}
+
+func (this *lagHandler) OnUpdate(old, new interface{}) {
+	if resourceVersion(old) != resourceVersion(new) {
+		this.state.observeLag(new)
+	}
+	this.ResourceEventHandler.OnUpdate(old, new)
+}
+
+func (this *lagHandler) OnDelete(obj interface{}) {
+	this.state.observeLag(obj)
+	this.ResourceEventHandler.OnDelete(obj)
+}
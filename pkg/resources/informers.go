@@ -41,6 +41,15 @@ type GenericInformer interface {
 type genericInformer struct {
 	cache.SharedIndexInformer
 	resource *Info
+	state    *watchState
+}
+
+func (f *genericInformer) AddEventHandler(handler cache.ResourceEventHandler) {
+	f.SharedIndexInformer.AddEventHandler(&lagHandler{handler, f.state})
+}
+
+func (f *genericInformer) AddEventHandlerWithResyncPeriod(handler cache.ResourceEventHandler, resyncPeriod time.Duration) {
+	f.SharedIndexInformer.AddEventHandlerWithResyncPeriod(&lagHandler{handler, f.state}, resyncPeriod)
 }
 
 func (f *genericInformer) Informer() cache.SharedIndexInformer {
@@ -226,6 +235,11 @@ func (f *sharedFilteredInformerFactory) queryFactory(namespace string) *genericI
 }
 
 func (f *sharedFilteredInformerFactory) informerFor(informerType reflect.Type, gvk schema.GroupVersionKind, namespace string, optionsFunc TweakListOptionsFunc) (GenericInformer, error) {
+	if namespace == "" && len(f.context.namespaces) > 0 {
+		if info, err := f.context.Get(gvk); err == nil && info.Namespaced() {
+			return f.multiNamespaceInformerFor(informerType, gvk, optionsFunc)
+		}
+	}
 	return f.getFactory(namespace, optionsFunc).informerFor(informerType, gvk)
 }
 
@@ -247,7 +261,7 @@ func (f *sharedFilteredInformerFactory) lookupInformerFor(informerType reflect.T
 			return fac.informerFor(informerType, gvk)
 		}
 	}
-	return f.getFactory("", nil).informerFor(informerType, gvk)
+	return f.informerFor(informerType, gvk, "", nil)
 }
 
 ///////////////////////////////////////////////////////////////////////////////
@@ -17,10 +17,14 @@
 package resources
 
 import (
+	"context"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
@@ -92,6 +96,37 @@ type ResourceEventHandlerFuncs struct {
 
 type Modifier func(ObjectData) (bool, error)
 
+// ObjectCache provides access to the objects cached by a dedicated informer.
+type ObjectCache interface {
+	GetCached(key ObjectKey) (Object, error)
+}
+
+// ApplyOptions are the options of a server side apply request.
+type ApplyOptions struct {
+	// FieldManager is the name of the actor owning the applied fields,
+	// by default the event source of the resources (the name of the
+	// controller manager) is used.
+	FieldManager string
+	// Force acquires the ownership of fields conflicting with other managers.
+	Force bool
+}
+
+// DeleteOptions are the options of a delete request.
+type DeleteOptions struct {
+	// PropagationPolicy determines the garbage collection of dependents
+	// (metav1.DeletePropagationForeground, Background or Orphan). By default
+	// the policy of the resource is used.
+	PropagationPolicy metav1.DeletionPropagation
+	// GracePeriodSeconds overrides the grace period of the object,
+	// zero deletes immediately.
+	GracePeriodSeconds *int64
+	// UID is a precondition for the uid of the deleted object.
+	UID types.UID
+	// ResourceVersion is a precondition for the resource version of
+	// the deleted object.
+	ResourceVersion string
+}
+
 type Object interface {
 	metav1.Object
 	GroupKindProvider
@@ -113,6 +148,17 @@ type Object interface {
 	IsA(spec interface{}) bool
 	Create() error
 	CreateOrUpdate() error
+	Apply(opts ApplyOptions) error
+	ApplyStatus(opts ApplyOptions) error
+	DeleteWith(opts DeleteOptions) error
+	GetScale() (*autoscalingv1.Scale, error)
+	Scale(replicas int32) (*autoscalingv1.Scale, error)
+	Patch(pt types.PatchType, data []byte) error
+	PatchStatus(pt types.PatchType, data []byte) error
+	ModifyByPatch(modifier Modifier) (bool, error)
+	ModifyStatusByPatch(modifier Modifier) (bool, error)
+	ModifyByOptimisticPatch(modifier Modifier) (bool, error)
+	ModifyStatusByOptimisticPatch(modifier Modifier) (bool, error)
 	Delete() error
 	Update() error
 	UpdateStatus() error
@@ -125,6 +171,8 @@ type Object interface {
 	HasFinalizer(key string) bool
 	SetFinalizer(key string) error
 	RemoveFinalizer(key string) error
+	SetFinalizerByPatch(key string) error
+	RemoveFinalizerByPatch(key string) error
 
 	GetLabel(name string) string
 
@@ -174,6 +222,7 @@ type Interface interface {
 	AddSelectedEventHandler(eventHandlers ResourceEventHandlerFuncs, namespace string, optionsFunc TweakListOptionsFunc) error
 	AddEventHandler(eventHandlers ResourceEventHandlerFuncs) error
 	AddRawEventHandler(handlers cache.ResourceEventHandlerFuncs) error
+	AddDedicatedEventHandler(ctx context.Context, eventHandlers ResourceEventHandlerFuncs, namespace string, optionsFunc TweakListOptionsFunc) (ObjectCache, error)
 
 	Wrap(ObjectData) (Object, error)
 	New(ObjectName) Object
@@ -181,18 +230,34 @@ type Interface interface {
 	GetInto(ObjectName, ObjectData) (Object, error)
 
 	GetCached(interface{}) (Object, error)
+	GetSelectedCached(watchNamespace string, optionsFunc TweakListOptionsFunc, key ObjectKey) (Object, error)
 	Get_(obj interface{}) (Object, error)
+	Cached() Reader
+	Live() Reader
 	ListCached(selector labels.Selector) ([]Object, error)
+	ListCachedByIndex(name, value string) ([]Object, error)
+	AddIndexer(name string, f IndexFunc) error
+	AddTransform(f TransformFunc) error
+	SetValidator(v ObjectValidator)
 	List(opts metav1.ListOptions) (ret []Object, err error)
 	Create(ObjectData) (Object, error)
 	CreateOrUpdate(obj ObjectData) (Object, error)
+	Apply(obj ObjectData, opts ApplyOptions) (Object, error)
+	Patch(obj ObjectDataName, pt types.PatchType, data []byte) (Object, error)
+	PatchStatus(obj ObjectDataName, pt types.PatchType, data []byte) (Object, error)
 	Update(ObjectData) (Object, error)
+	UpdateStatus(ObjectData) (Object, error)
+	ApplyStatus(obj ObjectData, opts ApplyOptions) (Object, error)
+	GetScale(obj ObjectDataName) (*autoscalingv1.Scale, error)
+	UpdateScale(obj ObjectDataName, scale *autoscalingv1.Scale) (*autoscalingv1.Scale, error)
+	Scale(obj ObjectDataName, replicas int32) (*autoscalingv1.Scale, error)
 	Modify(obj ObjectData, modifier Modifier) (ObjectData, bool, error)
 	ModifyByName(obj ObjectDataName, modifier Modifier) (Object, bool, error)
 	ModifyStatus(obj ObjectData, modifier Modifier) (ObjectData, bool, error)
 	ModifyStatusByName(obj ObjectDataName, modifier Modifier) (Object, bool, error)
 	Delete(ObjectData) error
 	DeleteByName(ObjectDataName) error
+	DeleteWith(obj ObjectDataName, opts DeleteOptions) error
 
 	NormalEventf(name ObjectDataName, reason, msgfmt string, args ...interface{})
 	WarningEventf(name ObjectDataName, reason, msgfmt string, args ...interface{})
@@ -212,6 +277,7 @@ type Namespaced interface {
 type Resources interface {
 	ResourcesSource
 	record.EventRecorder
+	EventRecorderFor(name string) record.EventRecorder
 
 	Get(interface{}) (Interface, error)
 	GetByExample(obj runtime.Object) (Interface, error)
@@ -227,11 +293,15 @@ type Resources interface {
 
 	GetObject(spec interface{}) (Object, error)
 	GetCachedObject(spec interface{}) (Object, error)
+	Cached() ObjectReader
+	Live() ObjectReader
 
 	CreateObject(ObjectData) (Object, error)
 	CreateOrUpdateObject(obj ObjectData) (Object, error)
+	ApplyObject(obj ObjectData, opts ApplyOptions) (Object, error)
 
 	DeleteObject(obj ObjectData) error
+	DeleteObjectWith(obj ObjectData, opts DeleteOptions) error
 }
 
 // TweakListOptionsFunc defines the signature of a helper function
@@ -0,0 +1,100 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const ATTR_LIST_CHUNK_SIZE = "list-chunk-size"
+
+// DEFAULT_LIST_CHUNK_SIZE is the default number of objects requested
+// by a single list call.
+const DEFAULT_LIST_CHUNK_SIZE = 500
+
+// listChunkSize determines the chunk size for list calls from the context
+// attribute ATTR_LIST_CHUNK_SIZE. A negative size disables chunking.
+func listChunkSize(v interface{}) int64 {
+	switch n := v.(type) {
+	case int:
+		if n != 0 {
+			return int64(n)
+		}
+	case int64:
+		if n != 0 {
+			return n
+		}
+	}
+	return DEFAULT_LIST_CHUNK_SIZE
+}
+
+type listFunc func(opts metav1.ListOptions, result runtime.Object) error
+
+// listChunked lists objects in chunks of the given size using the
+// limit/continue mechanism and returns a single list containing all
+// objects. If the options already request a limit or any resource
+// version, a single list call is done. Lists with resource version 0 are
+// served from the watch cache of the api server, which ignores the limit.
+// If the continue token expires in between, a full list is done.
+func listChunked(chunk int64, opts metav1.ListOptions, newList func() runtime.Object, list listFunc) (runtime.Object, error) {
+	if chunk <= 0 || opts.Limit > 0 || opts.ResourceVersion != "" {
+		result := newList()
+		return result, list(opts, result)
+	}
+
+	opts.Limit = chunk
+
+	var items []runtime.Object
+	var result runtime.Object
+	for {
+		page := newList()
+		if err := list(opts, page); err != nil {
+			if opts.Continue != "" && errors.IsResourceExpired(err) {
+				opts.Continue = ""
+				opts.Limit = 0
+				result := newList()
+				return result, list(opts, result)
+			}
+			return page, err
+		}
+		pageItems, err := meta.ExtractList(page)
+		if err != nil {
+			return page, err
+		}
+		items = append(items, pageItems...)
+		if result == nil {
+			result = page
+		}
+		accessor, err := meta.ListAccessor(page)
+		if err != nil {
+			return page, err
+		}
+		if accessor.GetContinue() == "" {
+			break
+		}
+		opts.Continue = accessor.GetContinue()
+	}
+	accessor, err := meta.ListAccessor(result)
+	if err != nil {
+		return result, err
+	}
+	accessor.SetContinue("")
+	return result, meta.SetList(result, items)
+}
@@ -0,0 +1,204 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	"fmt"
+
+	"github.com/gardener/controller-manager-library/pkg/logger"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// DeletionProtection decides whether a leftover object of a managed
+// set must be kept.
+type DeletionProtection func(obj Object) bool
+
+// ManagedSet reconciles a set of child objects: the desired objects are
+// created or updated (by server side apply) and labeled with the labels of
+// the set. Previously created objects carrying these labels, which are not
+// desired any more, are deleted.
+type ManagedSet struct {
+	resources  Resources
+	labels     map[string]string
+	kinds      []schema.GroupKind
+	namespace  string
+	owner      Object
+	dryRun     bool
+	protection []DeletionProtection
+	applyOpts  ApplyOptions
+}
+
+// ManagedSetResult describes the (planned, for a dry run) changes of a
+// reconcilation of a managed set.
+type ManagedSetResult struct {
+	Created   []ObjectKey
+	Updated   []ObjectKey
+	Deleted   []ObjectKey
+	Protected []ObjectKey
+}
+
+// NewManagedSet creates a managed set identified by the given labels.
+// Leftovers are searched for the given kinds and the kinds of the
+// desired objects.
+func NewManagedSet(resources Resources, labels map[string]string, kinds ...schema.GroupKind) *ManagedSet {
+	if len(labels) == 0 {
+		panic("managed set requires labels")
+	}
+	return &ManagedSet{resources: resources, labels: labels, kinds: kinds}
+}
+
+// InNamespace restricts the search for leftovers to a namespace.
+func (this *ManagedSet) InNamespace(namespace string) *ManagedSet {
+	this.namespace = namespace
+	return this
+}
+
+// OwnedBy sets the given object as controller of the desired objects.
+func (this *ManagedSet) OwnedBy(owner Object) *ManagedSet {
+	this.owner = owner
+	return this
+}
+
+// DryRun only determines the changes without executing them.
+func (this *ManagedSet) DryRun(dryRun bool) *ManagedSet {
+	this.dryRun = dryRun
+	return this
+}
+
+// AddDeletionProtection adds a hook protecting leftovers from deletion.
+func (this *ManagedSet) AddDeletionProtection(p ...DeletionProtection) *ManagedSet {
+	this.protection = append(this.protection, p...)
+	return this
+}
+
+// WithApplyOptions sets the options used to apply the desired objects.
+func (this *ManagedSet) WithApplyOptions(opts ApplyOptions) *ManagedSet {
+	this.applyOpts = opts
+	return this
+}
+
+// Selector returns the label selector for the objects of the set.
+func (this *ManagedSet) Selector() labels.Selector {
+	return labels.SelectorFromSet(this.labels)
+}
+
+// Reconcile creates or updates the desired objects and deletes the leftovers.
+func (this *ManagedSet) Reconcile(desired ...ObjectData) (*ManagedSetResult, error) {
+	result := &ManagedSetResult{}
+	errs := []error{}
+	keys := map[ObjectKey]bool{}
+	kinds := map[schema.GroupKind]bool{}
+	for _, gk := range this.kinds {
+		kinds[gk] = true
+	}
+
+	for _, d := range desired {
+		r, err := this.resources.GetByExample(d)
+		if err != nil {
+			return nil, err
+		}
+		key := NewKey(r.GroupKind(), d.GetNamespace(), d.GetName())
+		keys[key] = true
+		kinds[r.GroupKind()] = true
+		created, err := this.apply(r, d)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot apply %s: %s", key, err))
+			continue
+		}
+		if created {
+			result.Created = append(result.Created, key)
+		} else {
+			result.Updated = append(result.Updated, key)
+		}
+	}
+
+	for gk := range kinds {
+		r, err := this.resources.GetByGK(gk)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var list []Object
+		if this.namespace != "" && r.Namespaced() {
+			list, err = r.Live().ListNamespace(this.namespace, this.Selector())
+		} else {
+			list, err = r.Live().List(this.Selector())
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cannot list %s: %s", gk, err))
+			continue
+		}
+		for _, o := range list {
+			key := o.Key()
+			if keys[key] || o.IsDeleting() {
+				continue
+			}
+			if this.isProtected(o) {
+				logger.Infof("managed object %s is protected from deletion", key)
+				result.Protected = append(result.Protected, key)
+				continue
+			}
+			if !this.dryRun {
+				opts := DeleteOptions{PropagationPolicy: metav1.DeletePropagationBackground}.WithPreconditions(o)
+				if err := o.DeleteWith(opts); err != nil && !errors.IsNotFound(err) {
+					errs = append(errs, fmt.Errorf("cannot delete %s: %s", key, err))
+					continue
+				}
+				logger.Infof("deleted leftover managed object %s", key)
+			}
+			result.Deleted = append(result.Deleted, key)
+		}
+	}
+	return result, utilerrors.NewAggregate(errs)
+}
+
+// apply applies a desired object and reports whether it has been created.
+func (this *ManagedSet) apply(r Interface, d ObjectData) (bool, error) {
+	d = d.DeepCopyObject().(ObjectData)
+	for k, v := range this.labels {
+		SetLabel(d, k, v)
+	}
+	if this.owner != nil {
+		if _, err := SetControllerReference(d, this.owner); err != nil {
+			return false, err
+		}
+	}
+	_, err := r.Live().Get(NewKey(r.GroupKind(), d.GetNamespace(), d.GetName()))
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	created := err != nil
+	if this.dryRun {
+		return created, nil
+	}
+	_, err = r.Apply(d, this.applyOpts)
+	return created, err
+}
+
+func (this *ManagedSet) isProtected(obj Object) bool {
+	for _, p := range this.protection {
+		if p(obj) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,288 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ATTR_NAMESPACES is the context attribute restricting the informers of a
+// cluster to a set of namespaces ([]string).
+const ATTR_NAMESPACES = "namespaces"
+
+// namespaces determines the namespace restriction from the context
+// attribute ATTR_NAMESPACES.
+func namespaces(v interface{}) []string {
+	var list []string
+	switch n := v.(type) {
+	case []string:
+		list = n
+	case string:
+		list = strings.Split(n, ",")
+	}
+	set := map[string]bool{}
+	result := []string{}
+	for _, ns := range list {
+		ns = strings.TrimSpace(ns)
+		if ns != "" && !set[ns] {
+			set[ns] = true
+			result = append(result, ns)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// multiNamespaceInformer provides a unified view on the informers of
+// a resource for a set of namespaces. The informers are run by their
+// factories, the view just dispatches to them.
+type multiNamespaceInformer struct {
+	resource  *Info
+	informers map[string]GenericInformer
+}
+
+var _ GenericInformer = &multiNamespaceInformer{}
+var _ cache.Indexer = &multiNamespaceIndexer{}
+
+func (f *sharedFilteredInformerFactory) multiNamespaceInformerFor(informerType reflect.Type, gvk schema.GroupVersionKind, optionsFunc TweakListOptionsFunc) (GenericInformer, error) {
+	info, err := f.context.Get(gvk)
+	if err != nil {
+		return nil, err
+	}
+	m := &multiNamespaceInformer{resource: info, informers: map[string]GenericInformer{}}
+	for _, ns := range f.context.namespaces {
+		informer, err := f.getFactory(ns, optionsFunc).informerFor(informerType, gvk)
+		if err != nil {
+			return nil, fmt.Errorf("informer for %s in namespace %q: %s", gvk, ns, err)
+		}
+		m.informers[ns] = informer
+	}
+	return m, nil
+}
+
+func (this *multiNamespaceInformer) Informer() cache.SharedIndexInformer {
+	return this
+}
+
+func (this *multiNamespaceInformer) Lister() Lister {
+	return NewLister(this.GetIndexer(), this.resource)
+}
+
+func (this *multiNamespaceInformer) AddEventHandler(handler cache.ResourceEventHandler) {
+	for _, i := range this.informers {
+		i.AddEventHandler(handler)
+	}
+}
+
+func (this *multiNamespaceInformer) AddEventHandlerWithResyncPeriod(handler cache.ResourceEventHandler, resyncPeriod time.Duration) {
+	for _, i := range this.informers {
+		i.AddEventHandlerWithResyncPeriod(handler, resyncPeriod)
+	}
+}
+
+func (this *multiNamespaceInformer) GetStore() cache.Store {
+	return this.GetIndexer()
+}
+
+func (this *multiNamespaceInformer) GetController() cache.Controller {
+	return this
+}
+
+// Run just waits for the stop channel, the informers are run
+// by their factories.
+func (this *multiNamespaceInformer) Run(stopCh <-chan struct{}) {
+	<-stopCh
+}
+
+func (this *multiNamespaceInformer) HasSynced() bool {
+	for _, i := range this.informers {
+		if !i.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+func (this *multiNamespaceInformer) LastSyncResourceVersion() string {
+	return ""
+}
+
+func (this *multiNamespaceInformer) AddIndexers(indexers cache.Indexers) error {
+	for _, i := range this.informers {
+		if err := i.AddIndexers(indexers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *multiNamespaceInformer) GetIndexer() cache.Indexer {
+	indexers := map[string]cache.Indexer{}
+	for ns, i := range this.informers {
+		indexers[ns] = i.GetIndexer()
+	}
+	return &multiNamespaceIndexer{indexers}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// multiNamespaceIndexer is a read only view on the indexers of the
+// informers for a set of namespaces.
+type multiNamespaceIndexer struct {
+	indexers map[string]cache.Indexer
+}
+
+func (this *multiNamespaceIndexer) Add(obj interface{}) error {
+	return fmt.Errorf("multi namespace cache is read only")
+}
+
+func (this *multiNamespaceIndexer) Update(obj interface{}) error {
+	return fmt.Errorf("multi namespace cache is read only")
+}
+
+func (this *multiNamespaceIndexer) Delete(obj interface{}) error {
+	return fmt.Errorf("multi namespace cache is read only")
+}
+
+func (this *multiNamespaceIndexer) Replace([]interface{}, string) error {
+	return fmt.Errorf("multi namespace cache is read only")
+}
+
+func (this *multiNamespaceIndexer) Resync() error {
+	return nil
+}
+
+func (this *multiNamespaceIndexer) List() []interface{} {
+	var result []interface{}
+	for _, i := range this.indexers {
+		result = append(result, i.List()...)
+	}
+	return result
+}
+
+func (this *multiNamespaceIndexer) ListKeys() []string {
+	var result []string
+	for _, i := range this.indexers {
+		result = append(result, i.ListKeys()...)
+	}
+	return result
+}
+
+func (this *multiNamespaceIndexer) Get(obj interface{}) (interface{}, bool, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, false, err
+	}
+	i := this.indexers[accessor.GetNamespace()]
+	if i == nil {
+		return nil, false, nil
+	}
+	return i.Get(obj)
+}
+
+func (this *multiNamespaceIndexer) GetByKey(key string) (interface{}, bool, error) {
+	ns, _, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return nil, false, err
+	}
+	i := this.indexers[ns]
+	if i == nil {
+		return nil, false, nil
+	}
+	return i.GetByKey(key)
+}
+
+func (this *multiNamespaceIndexer) Index(indexName string, obj interface{}) ([]interface{}, error) {
+	var result []interface{}
+	for _, i := range this.indexers {
+		list, err := i.Index(indexName, obj)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, list...)
+	}
+	return result, nil
+}
+
+func (this *multiNamespaceIndexer) IndexKeys(indexName, indexKey string) ([]string, error) {
+	var result []string
+	for _, i := range this.indexers {
+		keys, err := i.IndexKeys(indexName, indexKey)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, keys...)
+	}
+	return result, nil
+}
+
+func (this *multiNamespaceIndexer) ListIndexFuncValues(indexName string) []string {
+	set := map[string]bool{}
+	var result []string
+	for _, i := range this.indexers {
+		for _, v := range i.ListIndexFuncValues(indexName) {
+			if !set[v] {
+				set[v] = true
+				result = append(result, v)
+			}
+		}
+	}
+	return result
+}
+
+func (this *multiNamespaceIndexer) ByIndex(indexName, indexKey string) ([]interface{}, error) {
+	if indexName == cache.NamespaceIndex {
+		i := this.indexers[indexKey]
+		if i == nil {
+			return nil, nil
+		}
+		return i.ByIndex(indexName, indexKey)
+	}
+	var result []interface{}
+	for _, i := range this.indexers {
+		list, err := i.ByIndex(indexName, indexKey)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, list...)
+	}
+	return result, nil
+}
+
+func (this *multiNamespaceIndexer) GetIndexers() cache.Indexers {
+	for _, i := range this.indexers {
+		return i.GetIndexers()
+	}
+	return cache.Indexers{}
+}
+
+func (this *multiNamespaceIndexer) AddIndexers(indexers cache.Indexers) error {
+	for _, i := range this.indexers {
+		if err := i.AddIndexers(indexers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
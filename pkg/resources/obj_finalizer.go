@@ -16,7 +16,14 @@
 
 package resources
 
-import "github.com/gardener/controller-manager-library/pkg/logger"
+import (
+	"encoding/json"
+
+	"github.com/gardener/controller-manager-library/pkg/logger"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
 
 func hasFinalizer(key string, obj ObjectData) bool {
 	for _, name := range obj.GetFinalizers() {
@@ -64,3 +71,71 @@ func (this *_object) RemoveFinalizer(key string) error {
 	_, err := this.Modify(f)
 	return err
 }
+
+// SetFinalizerByPatch adds a finalizer using a JSON patch only touching
+// the finalizer list. Other fields of the object are never sent, so
+// it is safe for objects also maintained by other (server side apply) clients.
+func (this *_object) SetFinalizerByPatch(key string) error {
+	return this.patchFinalizers(func(list []string) ([]string, bool) {
+		for _, name := range list {
+			if name == key {
+				return list, false
+			}
+		}
+		logger.Infof("setting finalizer %q for %q (%s)", key, this.Description(), this.GetResourceVersion())
+		return append(list, key), true
+	})
+}
+
+// RemoveFinalizerByPatch removes a finalizer using a JSON patch only
+// touching the finalizer list.
+func (this *_object) RemoveFinalizerByPatch(key string) error {
+	return this.patchFinalizers(func(list []string) ([]string, bool) {
+		for i, name := range list {
+			if name == key {
+				logger.Infof("removing finalizer %q for %q (%s)", key, this.Description(), this.GetResourceVersion())
+				return append(list[:i:i], list[i+1:]...), true
+			}
+		}
+		return list, false
+	})
+}
+
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+func (this *_object) patchFinalizers(modify func(list []string) ([]string, bool)) error {
+	data := this.ObjectData
+	for cnt := 10; ; cnt-- {
+		list, mod := modify(append([]string{}, data.GetFinalizers()...))
+		if !mod {
+			return nil
+		}
+		if list == nil {
+			list = []string{}
+		}
+		patch, err := json.Marshal([]patchOperation{
+			{"test", "/metadata/resourceVersion", data.GetResourceVersion()},
+			{"add", "/metadata/finalizers", list},
+		})
+		if err != nil {
+			return err
+		}
+		result, err := this.resource.I_patch(data, types.JSONPatchType, patch)
+		if err == nil {
+			this.ObjectData = result
+			return nil
+		}
+		// a failed test operation is reported as conflict or invalid request
+		if cnt <= 1 || !(errors.IsConflict(err) || errors.IsInvalid(err)) {
+			return err
+		}
+		data = data.DeepCopyObject().(ObjectData)
+		if err := this.resource.I_get(data); err != nil {
+			return err
+		}
+	}
+}
@@ -51,8 +51,12 @@ func (this *_i_object) I_modify(status_only, create bool, modifier Modifier) (bo
 
 	cnt := 10
 
-	if create {
+	if create || this.resource.I_transform() != nil {
+		// cached objects of resources with transforms may lack content
 		err := this.resource.I_get(data)
+		if err != nil && !create {
+			return false, err
+		}
 		if err != nil {
 			if !errors.IsNotFound(err) {
 				return false, err
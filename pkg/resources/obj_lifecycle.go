@@ -36,6 +36,16 @@ func (this *AbstractObject) CreateOrUpdate() error {
 	return err
 }
 
+// Apply applies the object with server side apply and replaces it
+// by the result.
+func (this *AbstractObject) Apply(opts ApplyOptions) error {
+	o, err := this.self.GetResource().Apply(this.ObjectData, opts)
+	if err == nil {
+		this.ObjectData = o.Data()
+	}
+	return err
+}
+
 func (this *AbstractObject) IsDeleting() bool {
 	return this.GetDeletionTimestamp() != nil
 }
@@ -64,7 +74,11 @@ func (this *AbstractObject) modify(create bool, modifier Modifier) (bool, error)
 // Methods using internal Resource Interface
 
 func (this *AbstractObject) Update() error {
-	result, err := this.self.I_resource().I_update(this.ObjectData)
+	rsc := this.self.I_resource()
+	if err := checkUntransformed(rsc, this.ObjectData); err != nil {
+		return err
+	}
+	result, err := rsc.I_update(this.ObjectData)
 	if err == nil {
 		this.ObjectData = result
 	}
@@ -76,6 +90,9 @@ func (this *AbstractObject) UpdateStatus() error {
 	if !rsc.Info().HasStatusSubResource() {
 		return fmt.Errorf("resource %q has no status sub resource", rsc.GroupVersionKind())
 	}
+	if err := checkUntransformed(rsc, this.ObjectData); err != nil {
+		return err
+	}
 	result, err := rsc.I_updateStatus(this.ObjectData)
 	if err == nil {
 		this.ObjectData = result
@@ -84,7 +101,7 @@ func (this *AbstractObject) UpdateStatus() error {
 }
 
 func (this *AbstractObject) Delete() error {
-	return this.self.I_resource().I_delete(this)
+	return this.self.I_resource().I_delete(this, DeleteOptions{})
 }
 
 func (this *AbstractObject) UpdateFromCache() error {
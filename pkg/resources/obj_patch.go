@@ -0,0 +1,196 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	kscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+// CreatePatch computes the patch transforming the original object into the
+// modified one. For the types of the standard kubernetes API a strategic
+// merge patch is used, for all other types (like custom resources or
+// unstructured objects) a JSON merge patch. An empty patch is returned if
+// there are no differences.
+func CreatePatch(original, modified ObjectData) (types.PatchType, []byte, error) {
+	o, err := json.Marshal(original)
+	if err != nil {
+		return "", nil, err
+	}
+	m, err := json.Marshal(modified)
+	if err != nil {
+		return "", nil, err
+	}
+
+	_, unstructured := original.(runtime.Unstructured)
+	if !unstructured {
+		if _, _, err := kscheme.Scheme.ObjectKinds(original); err == nil {
+			patch, err := strategicpatch.CreateTwoWayMergePatch(o, m, original)
+			if err != nil {
+				return "", nil, err
+			}
+			if string(patch) == "{}" {
+				patch = nil
+			}
+			return types.StrategicMergePatchType, patch, nil
+		}
+	}
+
+	var om, mm map[string]interface{}
+	if err := json.Unmarshal(o, &om); err != nil {
+		return "", nil, err
+	}
+	if err := json.Unmarshal(m, &mm); err != nil {
+		return "", nil, err
+	}
+	diff := mergePatch(om, mm)
+	if len(diff) == 0 {
+		return types.MergePatchType, nil, nil
+	}
+	patch, err := json.Marshal(diff)
+	return types.MergePatchType, patch, err
+}
+
+// mergePatch creates a JSON merge patch (RFC 7386) for two JSON objects.
+func mergePatch(original, modified map[string]interface{}) map[string]interface{} {
+	patch := map[string]interface{}{}
+	for k, m := range modified {
+		o, ok := original[k]
+		if ok && reflect.DeepEqual(o, m) {
+			continue
+		}
+		om, ok1 := o.(map[string]interface{})
+		mm, ok2 := m.(map[string]interface{})
+		if ok1 && ok2 {
+			patch[k] = mergePatch(om, mm)
+		} else {
+			patch[k] = m
+		}
+	}
+	for k := range original {
+		if _, ok := modified[k]; !ok {
+			patch[k] = nil
+		}
+	}
+	return patch
+}
+
+// withResourceVersion adds the resource version to a patch. The request
+// then fails with a conflict if the object has been changed meanwhile.
+func withResourceVersion(patch []byte, version string) ([]byte, error) {
+	p := map[string]interface{}{}
+	if err := json.Unmarshal(patch, &p); err != nil {
+		return nil, err
+	}
+	meta, ok := p["metadata"].(map[string]interface{})
+	if !ok {
+		meta = map[string]interface{}{}
+		p["metadata"] = meta
+	}
+	meta["resourceVersion"] = version
+	return json.Marshal(p)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func (this *AbstractObject) Patch(pt types.PatchType, data []byte) error {
+	o, err := this.self.GetResource().Patch(this.ObjectData, pt, data)
+	if err == nil {
+		this.ObjectData = o.Data()
+	}
+	return err
+}
+
+func (this *AbstractObject) PatchStatus(pt types.PatchType, data []byte) error {
+	o, err := this.self.GetResource().PatchStatus(this.ObjectData, pt, data)
+	if err == nil {
+		this.ObjectData = o.Data()
+	}
+	return err
+}
+
+// ModifyByPatch applies the modifier to a copy of the object and sends
+// the differences as patch. Only the modified fields are written, so
+// concurrent changes of other fields are kept.
+func (this *AbstractObject) ModifyByPatch(modifier Modifier) (bool, error) {
+	return this.modifyByPatch(false, false, modifier)
+}
+
+// ModifyStatusByPatch is like ModifyByPatch for the status of the object.
+func (this *AbstractObject) ModifyStatusByPatch(modifier Modifier) (bool, error) {
+	return this.modifyByPatch(true, false, modifier)
+}
+
+// ModifyByOptimisticPatch is like ModifyByPatch, but the patch is only
+// applied if the object has not been changed meanwhile. Otherwise the
+// actual state is read and the modifier is applied again.
+func (this *AbstractObject) ModifyByOptimisticPatch(modifier Modifier) (bool, error) {
+	return this.modifyByPatch(false, true, modifier)
+}
+
+// ModifyStatusByOptimisticPatch is like ModifyByOptimisticPatch for the
+// status of the object.
+func (this *AbstractObject) ModifyStatusByOptimisticPatch(modifier Modifier) (bool, error) {
+	return this.modifyByPatch(true, true, modifier)
+}
+
+func (this *AbstractObject) modifyByPatch(status_only, lock bool, modifier Modifier) (bool, error) {
+	rsc := this.self.I_resource()
+	patch := rsc.Patch
+	if status_only {
+		patch = rsc.PatchStatus
+	}
+
+	original := this.ObjectData
+	for cnt := 10; ; cnt-- {
+		data := original.DeepCopyObject().(ObjectData)
+		mod, err := modifier(data)
+		if !mod || err != nil {
+			return mod, err
+		}
+		pt, p, err := CreatePatch(original, data)
+		if err != nil {
+			return mod, err
+		}
+		if p == nil {
+			return false, nil
+		}
+		if lock {
+			if p, err = withResourceVersion(p, original.GetResourceVersion()); err != nil {
+				return mod, err
+			}
+		}
+		result, err := patch(original, pt, p)
+		if err == nil {
+			this.ObjectData = result.Data()
+			return mod, nil
+		}
+		if !lock || cnt <= 1 || !errors.IsConflict(err) {
+			return mod, err
+		}
+		original = original.DeepCopyObject().(ObjectData)
+		if err := rsc.I_get(original); err != nil {
+			return mod, err
+		}
+	}
+}
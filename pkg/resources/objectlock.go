@@ -0,0 +1,223 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ObjectLockAnnotation is the annotation holding the lock of an object.
+const ObjectLockAnnotation = "resources.gardener.cloud/lock"
+
+// ObjectLockRecord is the content of the lock annotation.
+type ObjectLockRecord struct {
+	Holder      string      `json:"holder"`
+	AcquireTime metav1.Time `json:"acquireTime"`
+	RenewTime   metav1.Time `json:"renewTime"`
+	TTLSeconds  int64       `json:"ttlSeconds"`
+}
+
+// Expired reports whether the lock has not been renewed within its ttl.
+func (this *ObjectLockRecord) Expired() bool {
+	return time.Now().After(this.RenewTime.Add(time.Duration(this.TTLSeconds) * time.Second))
+}
+
+// GetObjectLockRecord returns the lock record of an object or nil if it
+// is not locked.
+func GetObjectLockRecord(data ObjectData) (*ObjectLockRecord, error) {
+	value, ok := GetAnnotation(data, ObjectLockAnnotation)
+	if !ok || value == "" {
+		return nil, nil
+	}
+	record := &ObjectLockRecord{}
+	if err := json.Unmarshal([]byte(value), record); err != nil {
+		return nil, fmt.Errorf("invalid lock annotation: %s", err)
+	}
+	return record, nil
+}
+
+func setObjectLockRecord(data ObjectData, record *ObjectLockRecord) error {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	SetAnnotation(data, ObjectLockAnnotation, string(value))
+	return nil
+}
+
+type lockError struct {
+	lost bool
+	msg  string
+}
+
+func (this *lockError) Error() string {
+	return this.msg
+}
+
+// IsLockHeld reports whether an error indicates that a lock is held by
+// another holder.
+func IsLockHeld(err error) bool {
+	e, ok := err.(*lockError)
+	return ok && !e.lost
+}
+
+// IsLockLost reports whether an error indicates that a lock has been taken
+// over by another holder.
+func IsLockLost(err error) bool {
+	e, ok := err.(*lockError)
+	return ok && e.lost
+}
+
+// ObjectLock is a lock on a single object held by a holder (for example
+// a controller replica) stored in an annotation of the object. It
+// serializes operations on the object across controllers and replicas.
+// A lock not renewed within its ttl may be acquired by other holders.
+//
+// The resource version of the locked object serves as fencing token:
+// updates of the object done with the object returned by Object fail
+// with a conflict if the object has been modified (or the lock has been
+// taken) in the meantime.
+type ObjectLock struct {
+	holder string
+	ttl    time.Duration
+	object Object
+	record *ObjectLockRecord
+}
+
+// Acquire acquires the lock of an object for a holder. If the lock is
+// already held by the holder it is renewed. If it is held by another
+// holder and not expired an error is returned that can be checked with
+// IsLockHeld.
+func Acquire(obj Object, holder string, ttl time.Duration) (*ObjectLock, error) {
+	if holder == "" {
+		return nil, fmt.Errorf("lock holder required")
+	}
+	o := obj.DeepCopy()
+	for cnt := 0; ; cnt++ {
+		record, err := GetObjectLockRecord(o.Data())
+		if err != nil {
+			return nil, err
+		}
+		// the annotation keeps the times with second precision
+		now := metav1.NewTime(time.Now().Truncate(time.Second))
+		if record != nil && record.Holder != holder && !record.Expired() {
+			return nil, &lockError{msg: fmt.Sprintf("%s is locked by %q", o.Description(), record.Holder)}
+		}
+		if record == nil || record.Holder != holder {
+			record = &ObjectLockRecord{Holder: holder, AcquireTime: now}
+		}
+		record.RenewTime = now
+		record.TTLSeconds = int64(ttl / time.Second)
+		if err = setObjectLockRecord(o.Data(), record); err != nil {
+			return nil, err
+		}
+		err = o.Update()
+		if err == nil {
+			return &ObjectLock{holder: holder, ttl: ttl, object: o, record: record}, nil
+		}
+		if cnt > 0 || !errors.IsConflict(err) {
+			return nil, err
+		}
+		// the given object may be outdated, retry once with the actual state
+		o, err = o.GetResource().Live().Get(o.ObjectName())
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Holder returns the holder of the lock.
+func (this *ObjectLock) Holder() string {
+	return this.holder
+}
+
+// Object returns the locked object as of the last lock operation.
+func (this *ObjectLock) Object() Object {
+	return this.object
+}
+
+// ResourceVersion returns the resource version of the locked object,
+// which serves as fencing token.
+func (this *ObjectLock) ResourceVersion() string {
+	return this.object.GetResourceVersion()
+}
+
+// Expired reports whether the lock has not been renewed within its ttl.
+func (this *ObjectLock) Expired() bool {
+	return this.record.Expired()
+}
+
+// Renew renews the lock. If the lock has been taken by another holder
+// meanwhile an error is returned that can be checked with IsLockLost.
+// The object is refreshed if it has been modified by others.
+func (this *ObjectLock) Renew() error {
+	return this.modify(func(o Object) error {
+		record := *this.record
+		record.RenewTime = metav1.Now()
+		if err := setObjectLockRecord(o.Data(), &record); err != nil {
+			return err
+		}
+		if err := o.Update(); err != nil {
+			return err
+		}
+		this.record = &record
+		return nil
+	})
+}
+
+// Release releases the lock. Releasing a lock already taken by another
+// holder is no error.
+func (this *ObjectLock) Release() error {
+	err := this.modify(func(o Object) error {
+		RemoveAnnotation(o.Data(), ObjectLockAnnotation)
+		return o.Update()
+	})
+	if IsLockLost(err) || errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (this *ObjectLock) modify(f func(o Object) error) error {
+	o := this.object.DeepCopy()
+	for cnt := 0; ; cnt++ {
+		record, err := GetObjectLockRecord(o.Data())
+		if err != nil {
+			return err
+		}
+		if record == nil || record.Holder != this.holder || !record.AcquireTime.Equal(&this.record.AcquireTime) {
+			return &lockError{lost: true, msg: fmt.Sprintf("lock of %s lost by %q", o.Description(), this.holder)}
+		}
+		err = f(o)
+		if err == nil {
+			this.object = o
+			return nil
+		}
+		if cnt > 0 || !errors.IsConflict(err) {
+			return err
+		}
+		o, err = o.GetResource().Live().Get(o.ObjectName())
+		if err != nil {
+			return err
+		}
+	}
+}
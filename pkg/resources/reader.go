@@ -0,0 +1,123 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Reader reads the objects of a resource, either from the cache
+// (Interface.Cached) or directly from the cluster (Interface.Live).
+type Reader interface {
+	IsLive() bool
+	Get(obj interface{}) (Object, error)
+	List(selector labels.Selector) ([]Object, error)
+	ListNamespace(namespace string, selector labels.Selector) ([]Object, error)
+}
+
+// ObjectReader reads objects of any resource of a cluster, either from
+// the cache (Resources.Cached) or directly from the cluster (Resources.Live).
+type ObjectReader interface {
+	IsLive() bool
+	GetObject(spec interface{}) (Object, error)
+}
+
+// Cached returns a reader serving objects from the cache.
+func (this *_resource) Cached() Reader {
+	return &cachedReader{this}
+}
+
+// Live returns a reader bypassing the cache. It should be used for reads
+// that must not be served from a potentially stale cache, for example
+// directly after a create or during a leader transition.
+func (this *_resource) Live() Reader {
+	return &liveReader{this}
+}
+
+type cachedReader struct {
+	resource Interface
+}
+
+func (this *cachedReader) IsLive() bool {
+	return false
+}
+
+func (this *cachedReader) Get(obj interface{}) (Object, error) {
+	return this.resource.GetCached(obj)
+}
+
+func (this *cachedReader) List(selector labels.Selector) ([]Object, error) {
+	return this.resource.ListCached(selector)
+}
+
+func (this *cachedReader) ListNamespace(namespace string, selector labels.Selector) ([]Object, error) {
+	return this.resource.Namespace(namespace).ListCached(selector)
+}
+
+type liveReader struct {
+	resource Interface
+}
+
+func (this *liveReader) IsLive() bool {
+	return true
+}
+
+func (this *liveReader) Get(obj interface{}) (Object, error) {
+	return this.resource.Get_(obj)
+}
+
+func (this *liveReader) List(selector labels.Selector) ([]Object, error) {
+	return this.resource.List(listOptions(selector))
+}
+
+func (this *liveReader) ListNamespace(namespace string, selector labels.Selector) ([]Object, error) {
+	return this.resource.Namespace(namespace).List(listOptions(selector))
+}
+
+func listOptions(selector labels.Selector) metav1.ListOptions {
+	opts := metav1.ListOptions{}
+	if selector != nil && !selector.Empty() {
+		opts.LabelSelector = selector.String()
+	}
+	return opts
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Cached returns a reader serving objects from the cache.
+func (this *_resources) Cached() ObjectReader {
+	return &objectReader{this.GetCachedObject, false}
+}
+
+// Live returns a reader bypassing the cache.
+func (this *_resources) Live() ObjectReader {
+	return &objectReader{this.GetObject, true}
+}
+
+type objectReader struct {
+	get  func(spec interface{}) (Object, error)
+	live bool
+}
+
+func (this *objectReader) IsLive() bool {
+	return this.live
+}
+
+func (this *objectReader) GetObject(spec interface{}) (Object, error) {
+	return this.get(spec)
+}
@@ -23,11 +23,30 @@ import (
 )
 
 func (this *_resource) getCached(namespace, name string) (Object, error) {
-	var obj ObjectData
 	informer, err := this.self.I_lookupInformer(namespace)
 	if err != nil {
 		return nil, err
 	}
+	return this.getFrom(informer, namespace, name)
+}
+
+// GetSelectedCached gets an object from the cache of the informer used for
+// watches with the given selection (see AddSelectedEventHandler). Objects
+// not matching the selection are not found.
+func (this *_resource) GetSelectedCached(watchNamespace string, optionsFunc TweakListOptionsFunc, key ObjectKey) (Object, error) {
+	if key.GroupKind() != this.GroupKind() {
+		return nil, fmt.Errorf("%s cannot handle group/kind '%s'", this.gvk, key.GroupKind())
+	}
+	informer, err := this.self.I_getInformer(watchNamespace, optionsFunc)
+	if err != nil {
+		return nil, err
+	}
+	return this.getFrom(informer, key.Namespace(), key.Name())
+}
+
+func (this *_resource) getFrom(informer GenericInformer, namespace, name string) (Object, error) {
+	var obj ObjectData
+	var err error
 	if this.info.Namespaced() {
 		if namespace == "" {
 			return nil, fmt.Errorf("resourcename %s (%s) is namespaced", this.Name(), this.GroupVersionKind())
@@ -17,15 +17,21 @@
 package resources
 
 import (
+	"encoding/json"
+	"fmt"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"reflect"
+	"strings"
 	"sync"
 
 	"github.com/gardener/controller-manager-library/pkg/informerfactories"
 
 	"github.com/gardener/controller-manager-library/pkg/logger"
 
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 type Internal interface {
@@ -38,14 +44,19 @@ type Internal interface {
 	I_get(data ObjectData) error
 	I_update(data ObjectData) (ObjectData, error)
 	I_updateStatus(data ObjectData) (ObjectData, error)
-	I_delete(data ObjectDataName) error
+	I_delete(data ObjectDataName, opts DeleteOptions) error
+	I_patch(data ObjectDataName, pt types.PatchType, patch []byte, sub ...string) (ObjectData, error)
+	I_apply(data ObjectData, opts ApplyOptions, sub ...string) (ObjectData, error)
+	I_scale(data ObjectDataName, scale *autoscalingv1.Scale) (*autoscalingv1.Scale, error)
+	I_transform() TransformFunc
 
 	I_modifyByName(name ObjectDataName, status_only, create bool, modifier Modifier) (Object, bool, error)
 	I_modify(data ObjectData, status_only, read, create bool, modifier Modifier) (ObjectData, bool, error)
 
 	I_getInformer(namespace string, optionsFunc TweakListOptionsFunc) (GenericInformer, error)
+	I_newInformer(namespace string, optionsFunc TweakListOptionsFunc) (GenericInformer, error)
 	I_lookupInformer(namespace string) (GenericInformer, error)
-	I_list(namespace string) ([]Object, error)
+	I_list(namespace string, opts metav1.ListOptions) ([]Object, error)
 }
 
 // _i_resource is the implementation of the internal resource interface used by
@@ -73,6 +84,9 @@ func (this *_i_resource) I_listType() reflect.Type {
 
 func (this *_i_resource) I_update(data ObjectData) (ObjectData, error) {
 	logger.Infof("UPDATE %s/%s/%s", this.GroupKind(), data.GetNamespace(), data.GetName())
+	if err := this.context.validate(this.GroupKind(), data); err != nil {
+		return nil, err
+	}
 	result := this.helper.CreateData()
 	return result, this.objectRequest(this.client.Put(), data).
 		Body(data).
@@ -90,6 +104,9 @@ func (this *_i_resource) I_updateStatus(data ObjectData) (ObjectData, error) {
 }
 
 func (this *_i_resource) I_create(data ObjectData) (ObjectData, error) {
+	if err := this.context.validate(this.GroupKind(), data); err != nil {
+		return nil, err
+	}
 	result := this.helper.CreateData()
 	return result, this.resourceRequest(this.client.Post(), data).
 		Body(data).
@@ -98,18 +115,59 @@ func (this *_i_resource) I_create(data ObjectData) (ObjectData, error) {
 }
 
 func (this *_i_resource) I_get(data ObjectData) error {
-	return this.objectRequest(this.client.Get(), data).
+	err := this.objectRequest(this.client.Get(), data).
 		Do().
 		Into(data)
+	if err != nil {
+		return err
+	}
+	return this.context.validate(this.GroupKind(), data)
 }
 
-func (this *_i_resource) I_delete(data ObjectDataName) error {
+func (this *_i_resource) I_delete(data ObjectDataName, opts DeleteOptions) error {
 	return this.objectRequest(this.client.Delete(), data).
-		Body(&metav1.DeleteOptions{}).
+		Body(opts.deleteOptions()).
 		Do().
 		Error()
 }
 
+func (this *_i_resource) I_patch(data ObjectDataName, pt types.PatchType, patch []byte, sub ...string) (ObjectData, error) {
+	logger.Infof("PATCH %s/%s/%s %s", this.GroupKind(), data.GetNamespace(), data.GetName(), strings.Join(sub, "/"))
+	result := this.helper.CreateData()
+	return result, this.objectRequest(this.client.Patch(pt), data, sub...).
+		Body(patch).
+		Do().
+		Into(result)
+}
+
+// I_apply sends the object as server side apply patch. The type meta is
+// set according to the resource, the resource version and the managed
+// fields are omitted.
+func (this *_i_resource) I_apply(data ObjectData, opts ApplyOptions, sub ...string) (ObjectData, error) {
+	manager := opts.FieldManager
+	if manager == "" {
+		manager = this.context.fieldManager()
+	}
+	if manager == "" {
+		return nil, fmt.Errorf("field manager required to apply %s/%s/%s", this.GroupKind(), data.GetNamespace(), data.GetName())
+	}
+	logger.Infof("APPLY %s/%s/%s %s (%s)", this.GroupKind(), data.GetNamespace(), data.GetName(), strings.Join(sub, "/"), manager)
+	obj := data.DeepCopyObject().(ObjectData)
+	obj.GetObjectKind().SetGroupVersionKind(this.GroupVersionKind())
+	obj.SetResourceVersion("")
+	obj.SetManagedFields(nil)
+	body, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	result := this.helper.CreateData()
+	return result, this.objectRequest(this.client.Patch(types.ApplyPatchType), data, sub...).
+		VersionedParams(&metav1.PatchOptions{FieldManager: manager, Force: &opts.Force}, metav1.ParameterCodec).
+		Body(body).
+		Do().
+		Into(result)
+}
+
 func (this *_i_resource) I_getInformer(namespace string, optionsFunc TweakListOptionsFunc) (GenericInformer, error) {
 	if this.cache != nil {
 		return this.cache, nil
@@ -139,6 +197,14 @@ func (this *_i_resource) I_getInformer(namespace string, optionsFunc TweakListOp
 	return informer, nil
 }
 
+// I_newInformer creates a new informer not shared with other users of the
+// resource. It must be run explicitly.
+func (this *_i_resource) I_newInformer(namespace string, optionsFunc TweakListOptionsFunc) (GenericInformer, error) {
+	factory := newGenericInformerFactory(this.context, this.context.defaultResync, namespace, optionsFunc)
+	factory.dedicated = true
+	return factory.informerFor(this.otype, this.gvk)
+}
+
 func (this *_i_resource) I_lookupInformer(namespace string) (GenericInformer, error) {
 	if this.cache != nil {
 		return this.cache, nil
@@ -165,11 +231,14 @@ func (this *_i_resource) I_lookupInformer(namespace string) (GenericInformer, er
 	return informer, nil
 }
 
-func (this *_i_resource) I_list(namespace string) ([]Object, error) {
-	result := this.helper.CreateListData()
-	err := this.namespacedRequest(this.client.Get(), namespace).
-		Do().
-		Into(result)
+func (this *_i_resource) I_list(namespace string, opts metav1.ListOptions) ([]Object, error) {
+	result, err := listChunked(this.context.listChunkSize, opts, this.helper.CreateListData,
+		func(opts metav1.ListOptions, result runtime.Object) error {
+			return this.namespacedRequest(this.client.Get(), namespace).
+				VersionedParams(&opts, this.context.Clients.parametercodec).
+				Do().
+				Into(result)
+		})
 	if err != nil {
 		return nil, err
 	}
@@ -192,8 +261,15 @@ func (this *_i_resource) I_modify(data ObjectData, status_only, read, create boo
 	var lasterr error
 	var err error
 
+	if this.I_transform() != nil {
+		// cached objects of resources with transforms may lack content
+		read = true
+	}
 	if read {
 		err = this.I_get(data)
+		if err != nil && !create {
+			return nil, false, err
+		}
 	}
 
 	cnt := 10
@@ -23,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 func (this *AbstractResource) Create(obj ObjectData) (Object, error) {
@@ -60,6 +61,48 @@ func (this *AbstractResource) CreateOrUpdate(obj ObjectData) (Object, error) {
 	return this.helper.ObjectAsResource(result), nil
 }
 
+// Apply applies the given object with server side apply. Besides objects
+// of the resource type apply configurations (see ApplyConfiguration)
+// containing only the fields owned by the field manager can be used.
+func (this *AbstractResource) Apply(obj ObjectData, opts ApplyOptions) (Object, error) {
+	if o, ok := obj.(Object); ok {
+		obj = o.Data()
+	}
+	if _, ok := obj.(runtime.Unstructured); !ok {
+		if err := this.helper.CheckOType(obj); err != nil {
+			return nil, err
+		}
+	}
+	result, err := this.self.I_apply(obj, opts)
+	if err != nil {
+		return nil, err
+	}
+	return this.helper.ObjectAsResource(result), nil
+}
+
+// Patch patches an object with the given patch of the given type.
+func (this *AbstractResource) Patch(obj ObjectDataName, pt types.PatchType, data []byte) (Object, error) {
+	result, err := this.self.I_patch(obj, pt, data)
+	if err != nil {
+		return nil, err
+	}
+	return this.helper.ObjectAsResource(result), nil
+}
+
+// PatchStatus patches the status of an object. For resources without
+// status sub resource the object itself is patched.
+func (this *AbstractResource) PatchStatus(obj ObjectDataName, pt types.PatchType, data []byte) (Object, error) {
+	var sub []string
+	if this.self.Info().HasStatusSubResource() {
+		sub = []string{"status"}
+	}
+	result, err := this.self.I_patch(obj, pt, data, sub...)
+	if err != nil {
+		return nil, err
+	}
+	return this.helper.ObjectAsResource(result), nil
+}
+
 func (this *AbstractResource) Update(obj ObjectData) (Object, error) {
 	if o, ok := obj.(Object); ok {
 		obj = o.Data()
@@ -67,6 +110,9 @@ func (this *AbstractResource) Update(obj ObjectData) (Object, error) {
 	if err := this.helper.CheckOType(obj); err != nil {
 		return nil, err
 	}
+	if err := checkUntransformed(this.self, obj); err != nil {
+		return nil, err
+	}
 	result, err := this.self.I_update(obj)
 	if err != nil {
 		return nil, err
@@ -109,7 +155,7 @@ func (this *AbstractResource) Delete(obj ObjectData) error {
 	if err := this.helper.CheckOType(obj); err != nil {
 		return err
 	}
-	err := this.self.I_delete(obj)
+	err := this.self.I_delete(obj, DeleteOptions{})
 	if err != nil {
 		return err
 	}
@@ -117,7 +163,7 @@ func (this *AbstractResource) Delete(obj ObjectData) error {
 }
 
 func (this *AbstractResource) DeleteByName(obj ObjectDataName) error {
-	return this.self.I_delete(obj)
+	return this.self.I_delete(obj, DeleteOptions{})
 }
 
 func (this *AbstractResource) handleList(result runtime.Object) (ret []Object, err error) {
@@ -183,7 +229,7 @@ func (this *AbstractResource) Get_(obj interface{}) (Object, error) {
 }
 
 func (this *AbstractResource) List(opts metav1.ListOptions) (ret []Object, err error) {
-	return this.self.I_list(metav1.NamespaceAll)
+	return this.self.I_list(metav1.NamespaceAll, opts)
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -206,5 +252,5 @@ func (this *namespacedResource) List(opts metav1.ListOptions) (ret []Object, err
 	if !this.resource.Namespaced() {
 		return nil, fmt.Errorf("resourcename %s (%s) is not namespaced", this.resource.Name(), this.resource.GroupVersionKind())
 	}
-	return this.resource.self.I_list(this.namespace)
+	return this.resource.self.I_list(this.namespace, opts)
 }
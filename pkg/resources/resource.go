@@ -17,6 +17,8 @@
 package resources
 
 import (
+	"context"
+	"fmt"
 	"k8s.io/api/core/v1"
 	"reflect"
 
@@ -110,6 +112,39 @@ func (this *_resource) AddRawSelectedEventHandler(handlers cache.ResourceEventHa
 	return nil
 }
 
+// AddDedicatedEventHandler adds an event handler using an informer
+// not shared with other handlers. The informer is stopped when the given
+// context is done. The objects of the informer can be accessed with the
+// returned cache.
+func (this *_resource) AddDedicatedEventHandler(ctx context.Context, handlers ResourceEventHandlerFuncs, namespace string, optionsFunc TweakListOptionsFunc) (ObjectCache, error) {
+	logger.Infof("adding dedicated watch for %s", this.gvk)
+	informer, err := this.self.I_newInformer(namespace, optionsFunc)
+	if err != nil {
+		return nil, err
+	}
+	informer.AddEventHandler(convert(this, &handlers))
+	go func() {
+		informer.Run(ctx.Done())
+		if i, ok := informer.(*genericInformer); ok {
+			i.state.unregisterMetrics()
+		}
+		logger.Infof("dedicated watch for %s stopped", this.gvk)
+	}()
+	return &dedicatedCache{this, informer}, nil
+}
+
+type dedicatedCache struct {
+	resource *_resource
+	informer GenericInformer
+}
+
+func (this *dedicatedCache) GetCached(key ObjectKey) (Object, error) {
+	if key.GroupKind() != this.resource.GroupKind() {
+		return nil, fmt.Errorf("%s cannot handle group/kind '%s'", this.resource.gvk, key.GroupKind())
+	}
+	return this.resource.getFrom(this.informer, key.Namespace(), key.Name())
+}
+
 func (this *_resource) AddEventHandler(handlers ResourceEventHandlerFuncs) error {
 	return this.AddRawEventHandler(*convert(this, &handlers))
 }
@@ -43,6 +43,8 @@ type _resources struct {
 	unstructuredHandlersByGroupVersionKind map[schema.GroupVersionKind]Interface
 
 	record.EventRecorder
+	eventBroadcaster record.EventBroadcaster
+	eventSource      string
 }
 
 var _ Resources = &_resources{}
@@ -64,6 +66,8 @@ func newResources(c *resourceContext, source string) *_resources {
 	eventBroadcaster.StartLogging(logger.Debugf)
 	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: typedcorev1.New(client).Events("")})
 	res.EventRecorder = eventBroadcaster.NewRecorder(c.scheme, corev1.EventSource{Component: source})
+	res.eventBroadcaster = eventBroadcaster
+	res.eventSource = source
 
 	return &res
 }
@@ -72,6 +76,14 @@ func (this *_resources) Resources() Resources {
 	return this
 }
 
+// EventRecorderFor returns an event recorder using the component
+// <event source>/<name> for the recorded events. It shares the event
+// broadcaster of the cluster, which aggregates similar events and limits
+// the rate of events per component and object.
+func (this *_resources) EventRecorderFor(name string) record.EventRecorder {
+	return this.eventBroadcaster.NewRecorder(this.ctx.scheme, corev1.EventSource{Component: this.eventSource + "/" + name})
+}
+
 func (this *_resources) Get(spec interface{}) (Interface, error) {
 	switch o := spec.(type) {
 	case GroupKindProvider:
@@ -117,6 +129,22 @@ func (this *_resources) CreateOrUpdateObject(obj ObjectData) (Object, error) {
 	return r.CreateOrUpdate(obj)
 }
 
+// ApplyObject applies an object with server side apply. The resource of
+// an apply configuration is taken from its type meta.
+func (this *_resources) ApplyObject(obj ObjectData, opts ApplyOptions) (Object, error) {
+	var r Interface
+	var err error
+	if _, ok := obj.(runtime.Unstructured); ok {
+		r, err = this.GetByGVK(obj.GetObjectKind().GroupVersionKind())
+	} else {
+		r, err = this.GetByExample(obj)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.Apply(obj, opts)
+}
+
 func (this *_resources) DeleteObject(obj ObjectData) error {
 	r, err := this.GetByExample(obj)
 	if err != nil {
@@ -298,14 +326,16 @@ func (this *_resources) GetCachedObject(spec interface{}) (Object, error) {
 
 func (r *_resources) newResource(gvk schema.GroupVersionKind, otype reflect.Type, info *Info) (Interface, error) {
 
-	client, err := r.ctx.GetClient(gvk.GroupVersion())
+	get := r.ctx.GetClient
+	if otype == nil {
+		otype = unstructuredType
+		get = r.ctx.GetJSONClient
+	}
+	client, err := get(gvk.GroupVersion())
 	if err != nil {
 		return nil, err
 	}
 
-	if otype == nil {
-		otype = unstructuredType
-	}
 	ltype := kutil.DetermineListType(r.ctx.scheme, gvk.GroupVersion(), otype)
 	if ltype == nil {
 		return nil, fmt.Errorf("cannot determine list type for %s", otype)
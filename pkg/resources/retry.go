@@ -0,0 +1,134 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	"context"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/logger"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
+)
+
+// UpdateRetryBackoff is the backoff used by UpdateWithRetry and
+// UpdateStatusWithRetry for retries after conflicts.
+var UpdateRetryBackoff = retry.DefaultBackoff
+
+// UpdateWithRetry applies the modifier to the object and updates it if the
+// modifier reports a modification. On a conflict the actual state of the
+// object is read, the modifier is applied again and the update is retried
+// according to UpdateRetryBackoff. The object is set to the final state.
+func UpdateWithRetry(obj Object, modifier Modifier) (bool, error) {
+	return updateWithRetry(obj, false, modifier)
+}
+
+// UpdateStatusWithRetry is like UpdateWithRetry for the status of the object.
+func UpdateStatusWithRetry(obj Object, modifier Modifier) (bool, error) {
+	return updateWithRetry(obj, true, modifier)
+}
+
+func updateWithRetry(obj Object, status_only bool, modifier Modifier) (bool, error) {
+	update := obj.Update
+	if status_only {
+		update = obj.UpdateStatus
+	}
+	ctx := context.TODO()
+	mod := false
+	read := false
+	err := retry.RetryOnConflict(UpdateRetryBackoff, func() error {
+		if read {
+			err := RetryOnThrottle(ctx, func() error {
+				_, err := obj.GetResource().GetInto(obj.ObjectName(), obj.Data())
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+		read = true
+		var err error
+		mod, err = modifier(obj.Data())
+		if !mod || err != nil {
+			return err
+		}
+		return RetryOnThrottle(ctx, update)
+	})
+	return mod, err
+}
+
+// ThrottleRetryBackoff is the backoff used by RetryOnThrottle if the
+// API server does not suggest a delay.
+var ThrottleRetryBackoff = wait.Backoff{
+	Steps:    5,
+	Duration: time.Second,
+	Factor:   2.0,
+	Jitter:   0.5,
+	Cap:      time.Minute,
+}
+
+// ThrottleJitter is the maximum factor of the jitter added to the
+// delays suggested by the API server, to avoid that all throttled
+// clients retry at the same time.
+var ThrottleJitter = 0.5
+
+// IsThrottled reports errors of an overloaded API server, for example
+// the 429 responses of the API priority and fairness.
+func IsThrottled(err error) bool {
+	return errors.IsTooManyRequests(err) || errors.IsServerTimeout(err)
+}
+
+// RetryAfter returns the jittered delay suggested by the API server
+// (the Retry-After header) for a throttled request.
+func RetryAfter(err error) (time.Duration, bool) {
+	if !IsThrottled(err) {
+		return 0, false
+	}
+	secs, ok := errors.SuggestsClientDelay(err)
+	if !ok || secs <= 0 {
+		return 0, false
+	}
+	return wait.Jitter(time.Duration(secs)*time.Second, ThrottleJitter), true
+}
+
+// RetryOnThrottle executes the given function and retries it as long as
+// it fails because of throttling, according to ThrottleRetryBackoff. A
+// delay suggested by the API server takes precedence if it is longer.
+// The waiting is aborted with the last error if the context is done.
+func RetryOnThrottle(ctx context.Context, f func() error) error {
+	backoff := ThrottleRetryBackoff
+	for {
+		err := f()
+		if err == nil || !IsThrottled(err) || backoff.Steps <= 0 {
+			return err
+		}
+		delay := backoff.Step()
+		if after, ok := RetryAfter(err); ok && after > delay {
+			delay = after
+		}
+		logger.Infof("request throttled, retry after %s: %s", delay, err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gardener/controller-manager-library/pkg/logger"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// GetScale reads the scale sub resource of an object.
+func (this *AbstractResource) GetScale(obj ObjectDataName) (*autoscalingv1.Scale, error) {
+	if !this.self.Info().HasSubResource("scale") {
+		return nil, fmt.Errorf("resource %q has no scale sub resource", this.GroupVersionKind())
+	}
+	return this.self.I_scale(obj, nil)
+}
+
+// UpdateScale updates the scale sub resource of an object.
+func (this *AbstractResource) UpdateScale(obj ObjectDataName, scale *autoscalingv1.Scale) (*autoscalingv1.Scale, error) {
+	if !this.self.Info().HasSubResource("scale") {
+		return nil, fmt.Errorf("resource %q has no scale sub resource", this.GroupVersionKind())
+	}
+	return this.self.I_scale(obj, scale)
+}
+
+// Scale sets the number of replicas of an object using the scale
+// sub resource. Conflicts are retried with the actual scale.
+func (this *AbstractResource) Scale(obj ObjectDataName, replicas int32) (*autoscalingv1.Scale, error) {
+	var scale *autoscalingv1.Scale
+	var err error
+	for cnt := 10; cnt > 0; cnt-- {
+		scale, err = this.GetScale(obj)
+		if err != nil {
+			return nil, err
+		}
+		if scale.Spec.Replicas == replicas {
+			return scale, nil
+		}
+		scale.Spec.Replicas = replicas
+		scale, err = this.UpdateScale(obj, scale)
+		if err == nil || !errors.IsConflict(err) {
+			return scale, err
+		}
+	}
+	return nil, err
+}
+
+// GetScale reads the scale sub resource of the object.
+func (this *AbstractObject) GetScale() (*autoscalingv1.Scale, error) {
+	return this.self.GetResource().GetScale(this.ObjectData)
+}
+
+// Scale sets the number of replicas of the object using the scale sub resource.
+func (this *AbstractObject) Scale(replicas int32) (*autoscalingv1.Scale, error) {
+	return this.self.GetResource().Scale(this.ObjectData, replicas)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// I_scale gets or, if a scale is given, updates the scale sub resource.
+// The scale is decoded explicitly, because the scale types do not belong
+// to the group of the resource.
+func (this *_i_resource) I_scale(data ObjectDataName, scale *autoscalingv1.Scale) (*autoscalingv1.Scale, error) {
+	req := this.objectRequest(this.client.Get(), data, "scale").SetHeader("Accept", ContentTypeJSON)
+	if scale != nil {
+		logger.Infof("UPDATE SCALE %s/%s/%s", this.GroupKind(), data.GetNamespace(), data.GetName())
+		body, err := json.Marshal(scale)
+		if err != nil {
+			return nil, err
+		}
+		req = this.objectRequest(this.client.Put(), data, "scale").
+			SetHeader("Accept", ContentTypeJSON).
+			SetHeader("Content-Type", ContentTypeJSON).
+			Body(body)
+	}
+	raw, err := req.Do().Raw()
+	if err != nil {
+		return nil, err
+	}
+	result := &autoscalingv1.Scale{}
+	if err := json.Unmarshal(raw, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
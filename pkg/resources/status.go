@@ -0,0 +1,105 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// UpdateStatus updates the status of an object using the status sub
+// resource. Resources without status sub resource are rejected, because
+// an update of the object itself would also change the spec.
+func (this *AbstractResource) UpdateStatus(obj ObjectData) (Object, error) {
+	if o, ok := obj.(Object); ok {
+		obj = o.Data()
+	}
+	if err := this.helper.CheckOType(obj); err != nil {
+		return nil, err
+	}
+	if !this.self.Info().HasStatusSubResource() {
+		return nil, fmt.Errorf("resource %q has no status sub resource", this.self.GroupVersionKind())
+	}
+	if err := checkUntransformed(this.self, obj); err != nil {
+		return nil, err
+	}
+	result, err := this.self.I_updateStatus(obj)
+	if err != nil {
+		return nil, err
+	}
+	return this.helper.ObjectAsResource(result), nil
+}
+
+// ApplyStatus applies the status of the given object with server side
+// apply on the status sub resource. Only the status is sent, so the field
+// manager never owns fields of the spec. Resources without status sub
+// resource are rejected, because applying a status-only object to the
+// object itself would remove the spec fields owned by the field manager.
+func (this *AbstractResource) ApplyStatus(obj ObjectData, opts ApplyOptions) (Object, error) {
+	if o, ok := obj.(Object); ok {
+		obj = o.Data()
+	}
+	if _, ok := obj.(runtime.Unstructured); !ok {
+		if err := this.helper.CheckOType(obj); err != nil {
+			return nil, err
+		}
+	}
+	if !this.self.Info().HasStatusSubResource() {
+		return nil, fmt.Errorf("resource %q has no status sub resource", this.self.GroupVersionKind())
+	}
+	status, err := statusOf(obj)
+	if err != nil {
+		return nil, err
+	}
+	data := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	data.SetNamespace(obj.GetNamespace())
+	data.SetName(obj.GetName())
+	if status != nil {
+		data.Object["status"] = status
+	}
+	result, err := this.self.I_apply(data, opts, "status")
+	if err != nil {
+		return nil, err
+	}
+	return this.helper.ObjectAsResource(result), nil
+}
+
+// ApplyStatus applies the status of the object with server side apply
+// and replaces the object by the result.
+func (this *AbstractObject) ApplyStatus(opts ApplyOptions) error {
+	o, err := this.self.GetResource().ApplyStatus(this.ObjectData, opts)
+	if err == nil {
+		this.ObjectData = o.Data()
+	}
+	return err
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// statusOf returns the unstructured status of an object.
+func statusOf(obj ObjectData) (interface{}, error) {
+	if u, ok := obj.(runtime.Unstructured); ok {
+		return u.UnstructuredContent()["status"], nil
+	}
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	return data["status"], nil
+}
@@ -0,0 +1,261 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// TransformFunc transforms an object before it enters the cache of an
+// informer. It may modify the given object and returns the object to cache.
+type TransformFunc func(obj ObjectData) (ObjectData, error)
+
+// StripManagedFields drops the managed fields of an object.
+func StripManagedFields(obj ObjectData) (ObjectData, error) {
+	obj.SetManagedFields(nil)
+	return obj, nil
+}
+
+// StripLastAppliedConfiguration drops the last applied configuration
+// annotation maintained by kubectl apply.
+var StripLastAppliedConfiguration = StripAnnotations(corev1.LastAppliedConfigAnnotation)
+
+// StripAnnotations returns a transform dropping the given annotations.
+func StripAnnotations(names ...string) TransformFunc {
+	return func(obj ObjectData) (ObjectData, error) {
+		annos := obj.GetAnnotations()
+		if annos == nil {
+			return obj, nil
+		}
+		for _, n := range names {
+			delete(annos, n)
+		}
+		obj.SetAnnotations(annos)
+		return obj, nil
+	}
+}
+
+// StripFields returns a transform dropping the fields with the given dot
+// separated paths (e.g. "spec.template"). Typed objects are converted
+// to their unstructured representation and back, so a specific transform
+// is cheaper for them.
+func StripFields(paths ...string) TransformFunc {
+	fields := make([][]string, len(paths))
+	for i, p := range paths {
+		fields[i] = strings.Split(p, ".")
+	}
+	return func(obj ObjectData) (ObjectData, error) {
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			for _, f := range fields {
+				unstructured.RemoveNestedField(u.Object, f...)
+			}
+			return obj, nil
+		}
+		data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range fields {
+			unstructured.RemoveNestedField(data, f...)
+		}
+		result := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(ObjectData)
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(data, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+}
+
+// ChainTransforms returns a transform executing the given transforms in order.
+func ChainTransforms(transforms ...TransformFunc) TransformFunc {
+	return func(obj ObjectData) (ObjectData, error) {
+		var err error
+		for _, t := range transforms {
+			obj, err = t(obj)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return obj, nil
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// AddTransform registers a transform for the objects of the resource
+// entering the caches of its informers. Transforms are shared by all
+// users of a cluster and must be registered before the first informer for
+// the resource is created. Because cached objects may lack the stripped
+// content, Modify re-reads the object from the server before modifying
+// it, and updates of objects lacking stripped content are rejected.
+func (this *_resource) AddTransform(f TransformFunc) error {
+	return this.context.addTransform(this.GroupKind(), f)
+}
+
+// I_transform returns the cache transform of the resource or nil.
+func (this *_i_resource) I_transform() TransformFunc {
+	return this.context.getTransform(this.GroupKind())
+}
+
+// checkUntransformed rejects the update of an object lacking content
+// removed by the cache transform of its resource, because the update
+// would delete this content on the server.
+func checkUntransformed(rsc Internal, data ObjectData) error {
+	transform := rsc.I_transform()
+	if transform == nil {
+		return nil
+	}
+	actual := data.DeepCopyObject().(ObjectData)
+	if err := rsc.I_get(actual); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	stripped, err := transform(actual.DeepCopyObject().(ObjectData))
+	if err != nil {
+		return err
+	}
+	if equalJSON(stripped, actual) {
+		// nothing removed from the actual object
+		return nil
+	}
+	check, err := transform(data.DeepCopyObject().(ObjectData))
+	if err != nil {
+		return err
+	}
+	if equalJSON(check, data) {
+		return fmt.Errorf("%s %s/%s lacks content removed by a cache transform: use Modify or a patch instead of an update",
+			rsc.GroupKind(), data.GetNamespace(), data.GetName())
+	}
+	return nil
+}
+
+func equalJSON(a, b ObjectData) bool {
+	da, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	db, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(da, db)
+}
+
+func (c *resourceContext) addTransform(gk schema.GroupKind, f TransformFunc) error {
+	c.lock.Lock()
+	factory := c.sharedInformerFactory
+	c.lock.Unlock()
+
+	if factory != nil && (factory.structured.hasInformers(gk) || factory.unstructured.hasInformers(gk)) {
+		return fmt.Errorf("informer for %s already created", gk)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.transforms == nil {
+		c.transforms = map[schema.GroupKind][]TransformFunc{}
+	}
+	c.transforms[gk] = append(c.transforms[gk], f)
+	return nil
+}
+
+// getTransform returns the transform for a group kind or nil.
+func (c *resourceContext) getTransform(gk schema.GroupKind) TransformFunc {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	switch len(c.transforms[gk]) {
+	case 0:
+		return nil
+	case 1:
+		return c.transforms[gk][0]
+	default:
+		return ChainTransforms(c.transforms[gk]...)
+	}
+}
+
+func (f *sharedFilteredInformerFactory) hasInformers(gk schema.GroupKind) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, factory := range f.filters {
+		if factory.hasInformers(gk) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *genericInformerFactory) hasInformers(gk schema.GroupKind) bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for gvk := range f.informers {
+		if gvk.GroupKind() == gk {
+			return true
+		}
+	}
+	return false
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func transformList(list runtime.Object, transform TransformFunc) error {
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return err
+	}
+	for i, item := range items {
+		data, ok := item.(ObjectData)
+		if !ok {
+			continue
+		}
+		if items[i], err = transform(data); err != nil {
+			return err
+		}
+	}
+	return meta.SetList(list, items)
+}
+
+func transformEvent(in watch.Event, transform TransformFunc) watch.Event {
+	if data, ok := in.Object.(ObjectData); ok {
+		obj, err := transform(data)
+		if err != nil {
+			return watch.Event{Type: watch.Error, Object: &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: fmt.Sprintf("transform failed: %s", err),
+			}}
+		}
+		in.Object = obj
+	}
+	return in
+}
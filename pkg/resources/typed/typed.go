@@ -0,0 +1,201 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+// Package typed provides a typed access to the resources of a cluster.
+// It requires a Go version with type parameters, the rest of the library
+// does not depend on it.
+package typed
+
+import (
+	"fmt"
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/gardener/controller-manager-library/pkg/resources"
+)
+
+// Modifier modifies a typed object and reports whether it has been changed.
+type Modifier[T resources.ObjectData] func(T) (bool, error)
+
+// Resource is the typed access to a resource given by a pointer to its
+// go type, for example Resource[*corev1.Secret].
+type Resource[T resources.ObjectData] struct {
+	resources.Interface
+}
+
+// For returns the typed resource for T from the given resources source,
+// for example a cluster.
+func For[T resources.ObjectData](src resources.ResourcesSource) (*Resource[T], error) {
+	resc, err := src.Resources().Get(newData[T]())
+	if err != nil {
+		return nil, err
+	}
+	return &Resource[T]{resc}, nil
+}
+
+// MustFor is like For but panics on error.
+func MustFor[T resources.ObjectData](src resources.ResourcesSource) *Resource[T] {
+	resc, err := For[T](src)
+	if err != nil {
+		panic(err)
+	}
+	return resc
+}
+
+// Data returns the typed data of a resources.Object. It returns the zero
+// value if the object is not of type T.
+func Data[T resources.ObjectData](obj resources.Object) (T, bool) {
+	var zero T
+	if obj == nil {
+		return zero, false
+	}
+	data, ok := obj.Data().(T)
+	return data, ok
+}
+
+func newData[T resources.ObjectData]() T {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() != reflect.Ptr {
+		panic(fmt.Sprintf("typed resources require a pointer type (got %s)", t))
+	}
+	return reflect.New(t.Elem()).Interface().(T)
+}
+
+func (this *Resource[T]) data(obj resources.Object, err error) (T, error) {
+	var zero T
+	if err != nil {
+		return zero, err
+	}
+	data, ok := Data[T](obj)
+	if !ok {
+		return zero, fmt.Errorf("unexpected type %T for resource %s", obj.Data(), this.GroupKind())
+	}
+	return data, nil
+}
+
+func (this *Resource[T]) list(objs []resources.Object, err error) ([]T, error) {
+	if err != nil {
+		return nil, err
+	}
+	result := make([]T, 0, len(objs))
+	for _, o := range objs {
+		data, err := this.data(o, nil)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, data)
+	}
+	return result, nil
+}
+
+// Get reads an object from the cluster. The namespace is ignored for
+// cluster scoped resources.
+func (this *Resource[T]) Get(namespace, name string) (T, error) {
+	return this.data(this.GetInto(resources.NewObjectName(namespace, name), newData[T]()))
+}
+
+// GetCached reads an object from the cache.
+func (this *Resource[T]) GetCached(namespace, name string) (T, error) {
+	return this.data(this.Interface.GetCached(resources.NewObjectName(namespace, name)))
+}
+
+// List lists the objects of all namespaces from the cluster.
+func (this *Resource[T]) List(opts metav1.ListOptions) ([]T, error) {
+	return this.list(this.Interface.List(opts))
+}
+
+// ListCached lists the objects of all namespaces from the cache.
+func (this *Resource[T]) ListCached(selector labels.Selector) ([]T, error) {
+	return this.list(this.Interface.ListCached(selector))
+}
+
+// ListCachedByIndex lists the cached objects with the given value for
+// the index registered under the given name.
+func (this *Resource[T]) ListCachedByIndex(name, value string) ([]T, error) {
+	return this.list(this.Interface.ListCachedByIndex(name, value))
+}
+
+// ListNamespace lists the objects of a namespace from the cluster.
+func (this *Resource[T]) ListNamespace(namespace string, opts metav1.ListOptions) ([]T, error) {
+	return this.list(this.Namespace(namespace).List(opts))
+}
+
+// ListNamespaceCached lists the objects of a namespace from the cache.
+func (this *Resource[T]) ListNamespaceCached(namespace string, selector labels.Selector) ([]T, error) {
+	return this.list(this.Namespace(namespace).ListCached(selector))
+}
+
+func (this *Resource[T]) Create(obj T) (T, error) {
+	return this.data(this.Interface.Create(obj))
+}
+
+func (this *Resource[T]) Update(obj T) (T, error) {
+	return this.data(this.Interface.Update(obj))
+}
+
+func (this *Resource[T]) CreateOrUpdate(obj T) (T, error) {
+	return this.data(this.Interface.CreateOrUpdate(obj))
+}
+
+// Apply applies the object with server side apply.
+func (this *Resource[T]) Apply(obj T, opts resources.ApplyOptions) (T, error) {
+	return this.data(this.Interface.Apply(obj, opts))
+}
+
+func (this *Resource[T]) Delete(obj T) error {
+	return this.Interface.Delete(obj)
+}
+
+// DeleteWith deletes the object using the given delete options.
+func (this *Resource[T]) DeleteWith(obj T, opts resources.DeleteOptions) error {
+	return this.Interface.DeleteWith(obj, opts)
+}
+
+// Modify reads the actual state of the given object, applies the
+// modifier and updates the object if it has been changed. Conflicts are
+// handled by retrying with the actual state.
+func (this *Resource[T]) Modify(obj T, modifier Modifier[T]) (T, bool, error) {
+	return this.modify(obj, modifier, this.Interface.Modify)
+}
+
+// ModifyStatus is like Modify but updates the status sub resource.
+func (this *Resource[T]) ModifyStatus(obj T, modifier Modifier[T]) (T, bool, error) {
+	return this.modify(obj, modifier, this.Interface.ModifyStatus)
+}
+
+func (this *Resource[T]) modify(obj T, modifier Modifier[T], f func(resources.ObjectData, resources.Modifier) (resources.ObjectData, bool, error)) (T, bool, error) {
+	var zero T
+	result, mod, err := f(obj, func(data resources.ObjectData) (bool, error) {
+		typed, ok := data.(T)
+		if !ok {
+			return false, fmt.Errorf("unexpected type %T for resource %s", data, this.GroupKind())
+		}
+		return modifier(typed)
+	})
+	if err != nil {
+		return zero, mod, err
+	}
+	typed, ok := result.(T)
+	if !ok {
+		return zero, mod, fmt.Errorf("unexpected type %T for resource %s", result, this.GroupKind())
+	}
+	return typed, mod, nil
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gardener/controller-manager-library/pkg/logger"
+
+	api "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type PodObject struct {
+	Object
+}
+
+func (this *PodObject) Pod() *api.Pod {
+	return this.Data().(*api.Pod)
+}
+
+// Evict evicts the pod (see EvictPod).
+func (this *PodObject) Evict(gracePeriod *int64) error {
+	return EvictPod(this, this.ObjectName(), gracePeriod)
+}
+
+func PodKey(namespace, name string) ObjectKey {
+	return NewKey(schema.GroupKind{Group: api.GroupName, Kind: "Pod"}, namespace, name)
+}
+
+func Pod(o Object) *PodObject {
+	if o.IsA(&api.Pod{}) {
+		return &PodObject{o}
+	}
+	return nil
+}
+
+// EvictPod evicts a pod using the eviction sub resource, which respects
+// the pod disruption budgets. If an eviction would violate a budget, an
+// error with status 429 (see errors.IsTooManyRequests) is returned and the
+// eviction should be retried later. A nil grace period uses the default
+// grace period of the pod.
+func EvictPod(src ResourcesSource, name ObjectName, gracePeriod *int64) error {
+	rsc, err := src.Resources().Get(&api.Pod{})
+	if err != nil {
+		return err
+	}
+	r, ok := rsc.(*_resource)
+	if !ok {
+		return fmt.Errorf("unexpected resource implementation %T for pods", rsc)
+	}
+	return r.evict(name, gracePeriod)
+}
+
+// evict sends an eviction with the preferred policy version.
+func (this *_resource) evict(name ObjectName, gracePeriod *int64) error {
+	gv := policy.SchemeGroupVersion
+	for _, g := range this.context.GetGroups() {
+		if g.Group == policy.GroupName && g.Version == "v1" {
+			gv = g
+			break
+		}
+	}
+	eviction := &policy.Eviction{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: gv.String(),
+			Kind:       "Eviction",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: name.Namespace(),
+			Name:      name.Name(),
+		},
+	}
+	if gracePeriod != nil {
+		eviction.DeleteOptions = &metav1.DeleteOptions{GracePeriodSeconds: gracePeriod}
+	}
+	body, err := json.Marshal(eviction)
+	if err != nil {
+		return err
+	}
+	logger.Infof("EVICT %s/%s (%s)", name.Namespace(), name.Name(), gv)
+	return this.client.Post().
+		Namespace(name.Namespace()).
+		Resource(this.Name()).
+		Name(name.Name()).
+		SubResource("eviction").
+		SetHeader("Content-Type", ContentTypeJSON).
+		Body(body).
+		Do().
+		Error()
+}
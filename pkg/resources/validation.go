@@ -0,0 +1,55 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ObjectValidator validates the objects of a resource, for example
+// against the schema of a custom resource definition.
+type ObjectValidator func(obj ObjectData) error
+
+// SetValidator sets a validator for the objects of the resource kind.
+// It is called for objects read directly from the cluster and before
+// objects are created or updated. A nil validator disables the validation.
+func (this *_resource) SetValidator(v ObjectValidator) {
+	this.context.setValidator(this.GroupKind(), v)
+}
+
+func (c *resourceContext) setValidator(gk schema.GroupKind, v ObjectValidator) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.validators == nil {
+		c.validators = map[schema.GroupKind]ObjectValidator{}
+	}
+	if v == nil {
+		delete(c.validators, gk)
+	} else {
+		c.validators[gk] = v
+	}
+}
+
+func (c *resourceContext) validate(gk schema.GroupKind, obj ObjectData) error {
+	c.lock.Lock()
+	v := c.validators[gk]
+	c.lock.Unlock()
+	if v == nil {
+		return nil
+	}
+	return v(obj)
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package resources
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// RelistJitter is the maximum delay of a list after a watch of an
+// informer expired. It spreads the relists of many informers after
+// an API server restart.
+var RelistJitter = 5 * time.Second
+
+// watchState keeps track of the watches of an informer.
+// Watch bookmarks are not requested, because the watch decoder of the
+// used client version rejects unknown event types.
+type watchState struct {
+	lock    sync.Mutex
+	expired bool
+
+	// metrics of the informer, no metrics are provided without labels
+	labels    []string
+	lastWatch time.Time
+	received  map[string]time.Time
+}
+
+func newWatchState(labels ...string) *watchState {
+	return &watchState{labels: labels}
+}
+
+// relistDelay resets the state for a new list and returns the delay
+// for the list.
+func (this *watchState) relistDelay() time.Duration {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+
+	this.countRelist()
+	if !this.expired || RelistJitter <= 0 {
+		return 0
+	}
+	this.expired = false
+	return time.Duration(rand.Int63n(int64(RelistJitter)))
+}
+
+func (this *watchState) watchFailed(err error) {
+	this.countWatchError()
+	if isExpired(err) {
+		this.lock.Lock()
+		this.expired = true
+		this.lock.Unlock()
+	}
+}
+
+func (this *watchState) filter(w watch.Interface, transform TransformFunc) watch.Interface {
+	return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+		switch in.Type {
+		case watch.Error:
+			this.watchFailed(errors.FromObject(in.Object))
+			return in, true
+		case watch.Added, watch.Modified, watch.Deleted:
+			if accessor, err := meta.Accessor(in.Object); err == nil {
+				this.watchAlive(accessor.GetResourceVersion())
+			}
+			if transform != nil {
+				return transformEvent(in, transform), true
+			}
+		}
+		return in, true
+	})
+}
+
+func isExpired(err error) bool {
+	if status, ok := err.(errors.APIStatus); ok {
+		return status.Status().Code == http.StatusGone
+	}
+	return false
+}
+
+func sleep(done <-chan struct{}, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package debug
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/logger"
+)
+
+var (
+	lock     sync.Mutex
+	handlers = map[string]http.HandlerFunc{}
+)
+
+// Register adds a handler to the endpoints of the debug server. It must be
+// called before the debug server is started.
+func Register(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	lock.Lock()
+	defer lock.Unlock()
+	handlers[pattern] = handler
+}
+
+// Serve starts a HTTP server exposing runtime debug information
+// (profiles, goroutine dumps and GC statistics) and the log levels until
+// the context is done.
+// If a token is given, requests must provide it as bearer token.
+func Serve(ctx context.Context, bindAddress string, port int, token string) error {
+	listenAddress := fmt.Sprintf("%s:%d", bindAddress, port)
+	listener, err := net.Listen("tcp", listenAddress)
+	if err != nil {
+		return fmt.Errorf("cannot listen on %s for debug server: %s", listenAddress, err)
+	}
+
+	var handler http.Handler = NewMux()
+	if token != "" {
+		handler = authenticated(handler, token)
+	} else {
+		logger.Warnf("debug server without authentication")
+	}
+	server := &http.Server{Handler: handler}
+
+	go func() {
+		<-ctx.Done()
+		logger.Infof("shutting down debug server")
+		sctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(sctx)
+	}()
+
+	go func() {
+		logger.Infof("debug server started (serving on %s)", listener.Addr())
+		err := server.Serve(listener)
+		if err != nil && err != http.ErrServerClosed {
+			logger.Errorf("debug server failed: %s", err)
+		}
+		logger.Infof("debug server stopped")
+	}()
+	return nil
+}
+
+// NewMux returns a handler serving the debug endpoints.
+func NewMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", Profiles)
+	mux.HandleFunc("/debug/pprof/profile", CPUProfile)
+	mux.HandleFunc("/debug/pprof/trace", Trace)
+	mux.HandleFunc("/debug/goroutines", Goroutines)
+	mux.HandleFunc("/debug/gcstats", GCStats)
+	mux.HandleFunc("/debug/loglevels", LogLevels)
+
+	lock.Lock()
+	defer lock.Unlock()
+	for pattern, handler := range handlers {
+		mux.HandleFunc(pattern, handler)
+	}
+	return mux
+}
+
+func authenticated(handler http.Handler, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") ||
+			subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package debug
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"runtime/pprof"
+	"runtime/trace"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxDuration = 5 * time.Minute
+
+// Profiles serves the named runtime profiles (/debug/pprof/<name>) and
+// an index of all available profiles (/debug/pprof/).
+func Profiles(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/debug/pprof/")
+	if name == "" {
+		profiles := pprof.Profiles()
+		sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name() < profiles[j].Name() })
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, p := range profiles {
+			fmt.Fprintf(w, "%s: %d\n", p.Name(), p.Count())
+		}
+		fmt.Fprintf(w, "profile: cpu profile (seconds=<n>)\n")
+		fmt.Fprintf(w, "trace: execution trace (seconds=<n>)\n")
+		return
+	}
+	p := pprof.Lookup(name)
+	if p == nil {
+		http.Error(w, fmt.Sprintf("unknown profile %q", name), http.StatusNotFound)
+		return
+	}
+	level, _ := strconv.Atoi(r.FormValue("debug"))
+	if level > 0 {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	}
+	if name == "heap" && r.FormValue("gc") != "" {
+		runtime.GC()
+	}
+	p.WriteTo(w, level)
+}
+
+// CPUProfile records a CPU profile for the given number of seconds
+// (default 30).
+func CPUProfile(w http.ResponseWriter, r *http.Request) {
+	d := duration(r)
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="profile"`)
+	if err := pprof.StartCPUProfile(w); err != nil {
+		w.Header().Del("Content-Disposition")
+		http.Error(w, fmt.Sprintf("cannot start cpu profile: %s", err), http.StatusInternalServerError)
+		return
+	}
+	wait(r, d)
+	pprof.StopCPUProfile()
+}
+
+// Trace records an execution trace for the given number of seconds
+// (default 1).
+func Trace(w http.ResponseWriter, r *http.Request) {
+	d := duration(r)
+	if r.FormValue("seconds") == "" {
+		d = time.Second
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="trace"`)
+	if err := trace.Start(w); err != nil {
+		w.Header().Del("Content-Disposition")
+		http.Error(w, fmt.Sprintf("cannot start trace: %s", err), http.StatusInternalServerError)
+		return
+	}
+	wait(r, d)
+	trace.Stop()
+}
+
+// Goroutines dumps the stacks of all goroutines.
+func Goroutines(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	pprof.Lookup("goroutine").WriteTo(w, 2)
+}
+
+// GCStats reports memory and garbage collection statistics.
+func GCStats(w http.ResponseWriter, r *http.Request) {
+	var mem runtime.MemStats
+	var gc debug.GCStats
+	runtime.ReadMemStats(&mem)
+	debug.ReadGCStats(&gc)
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "goroutines: %d\n", runtime.NumGoroutine())
+	fmt.Fprintf(w, "heap alloc: %d\n", mem.HeapAlloc)
+	fmt.Fprintf(w, "heap in use: %d\n", mem.HeapInuse)
+	fmt.Fprintf(w, "heap objects: %d\n", mem.HeapObjects)
+	fmt.Fprintf(w, "heap sys: %d\n", mem.HeapSys)
+	fmt.Fprintf(w, "total alloc: %d\n", mem.TotalAlloc)
+	fmt.Fprintf(w, "sys: %d\n", mem.Sys)
+	fmt.Fprintf(w, "next gc: %d\n", mem.NextGC)
+	fmt.Fprintf(w, "gc cpu fraction: %f\n", mem.GCCPUFraction)
+	fmt.Fprintf(w, "num gc: %d\n", gc.NumGC)
+	fmt.Fprintf(w, "last gc: %s\n", gc.LastGC)
+	fmt.Fprintf(w, "pause total: %s\n", gc.PauseTotal)
+	if len(gc.Pause) > 0 {
+		fmt.Fprintf(w, "last pause: %s\n", gc.Pause[0])
+	}
+}
+
+func duration(r *http.Request) time.Duration {
+	sec, err := strconv.ParseInt(r.FormValue("seconds"), 10, 64)
+	if err != nil || sec <= 0 {
+		sec = 30
+	}
+	d := time.Duration(sec) * time.Second
+	if d > maxDuration {
+		d = maxDuration
+	}
+	return d
+}
+
+// wait waits for the given duration or until the client is gone.
+func wait(r *http.Request, d time.Duration) {
+	select {
+	case <-time.After(d):
+	case <-r.Context().Done():
+	}
+}
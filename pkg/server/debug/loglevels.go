@@ -0,0 +1,71 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package debug
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gardener/controller-manager-library/pkg/logger"
+)
+
+// LogLevels is a HTTP handler listing the global log level and the levels
+// of the log level domains (GET) or changing them (POST). The parameter
+// domain selects the domain (the global level if not given), the parameter
+// level the new level. An empty level or "default" resets the level of
+// a domain to the global level.
+func LogLevels(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	switch r.Method {
+	case http.MethodGet:
+		fmt.Fprintf(w, "global: %s\n", logger.GetLevel())
+		levels := logger.DomainLevels()
+		for _, n := range logger.Domains() {
+			if l, ok := levels[n]; ok {
+				fmt.Fprintf(w, "%s: %s\n", n, l)
+			} else {
+				fmt.Fprintf(w, "%s: default\n", n)
+			}
+		}
+	case http.MethodPost:
+		name := r.FormValue("domain")
+		level := r.FormValue("level")
+		if name == "" {
+			if err := logger.SetLevel(level); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			io.WriteString(w, "global log level updated\n")
+			return
+		}
+		if level == "" || level == "default" {
+			logger.ResetDomainLevel(name)
+		} else {
+			l, err := logger.ParseLevel(level)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			logger.SetDomainLevel(name, l)
+		}
+		io.WriteString(w, fmt.Sprintf("log level of %s updated\n", name))
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -18,12 +18,31 @@ package healthz
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/gardener/controller-manager-library/pkg/logger"
 )
 
+// Check reports an error as long as a component is not healthy
+type Check func() error
+
+// Register adds a custom health check for the given key. In contrast
+// to ticked checks it is evaluated for every health request.
+func Register(key string, check Check) {
+	lock.Lock()
+	defer lock.Unlock()
+	registered[key] = check
+}
+
+// Unregister removes the custom health check for the given key
+func Unregister(key string) {
+	lock.Lock()
+	defer lock.Unlock()
+	delete(registered, key)
+}
+
 func Tick(key string) {
 	lock.Lock()
 	defer lock.Unlock()
@@ -51,8 +70,9 @@ type check struct {
 }
 
 var (
-	checks = map[string]*check{}
-	lock   sync.Mutex
+	checks     = map[string]*check{}
+	registered = map[string]Check{}
+	lock       sync.Mutex
 )
 
 func setCheck(key string) {
@@ -68,36 +88,47 @@ func removeCheck(key string) {
 }
 
 func IsHealthy() bool {
-	lock.Lock()
-	defer lock.Unlock()
-
-	now := time.Now()
-
-	for key, c := range checks {
-		limit := now.Add(-c.timeout)
-		if c.last.Before(limit) {
-			logger.Warnf("outdated health check '%s': %s", key, limit.Sub(c.last))
-			return false
-		}
-		logger.Debugf("%s: %s", key, c.last)
-	}
-	return true
+	ok, _ := HealthInfo()
+	return ok
 }
 
 func HealthInfo() (bool, string) {
 	lock.Lock()
 	defer lock.Unlock()
 
+	keys := make([]string, 0, len(checks)+len(registered))
+	for key := range checks {
+		keys = append(keys, key)
+	}
+	for key := range registered {
+		if checks[key] == nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	healthy := true
 	info := ""
 	now := time.Now()
-	for key, c := range checks {
-		limit := now.Add(-c.timeout)
-		info = fmt.Sprintf("%s%s: %s\n", info, key, c.last)
-		if c.last.Before(limit) {
-			logger.Warnf("outdated health check '%s': %s", key, limit.Sub(c.last))
-			return false, info
+	for _, key := range keys {
+		if c := checks[key]; c != nil {
+			limit := now.Add(-c.timeout)
+			info = fmt.Sprintf("%s%s: %s\n", info, key, c.last)
+			if c.last.Before(limit) {
+				logger.Warnf("outdated health check '%s': %s", key, limit.Sub(c.last))
+				healthy = false
+			} else {
+				logger.Debugf("%s: %s", key, c.last)
+			}
+			continue
+		}
+		if err := registered[key](); err != nil {
+			logger.Warnf("failed health check '%s': %s", key, err)
+			info = fmt.Sprintf("%s%s: not healthy: %s\n", info, key, err)
+			healthy = false
+		} else {
+			info = fmt.Sprintf("%s%s: ok\n", info, key)
 		}
-		logger.Debugf("%s: %s", key, c.last)
 	}
-	return true, info
+	return healthy, info
 }
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package readyz
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/gardener/controller-manager-library/pkg/server"
+)
+
+func init() {
+	server.Register("/readyz", Readyz)
+}
+
+// Check reports an error as long as a component is not ready
+type Check func() error
+
+var (
+	checks = map[string]Check{}
+	lock   sync.RWMutex
+)
+
+// Register adds a readiness check for the given key
+func Register(key string, check Check) {
+	lock.Lock()
+	defer lock.Unlock()
+	checks[key] = check
+}
+
+// Unregister removes the readiness check for the given key
+func Unregister(key string) {
+	lock.Lock()
+	defer lock.Unlock()
+	delete(checks, key)
+}
+
+// ReadyInfo executes all readiness checks and returns the overall
+// state and a description of all checks.
+func ReadyInfo() (bool, string) {
+	lock.RLock()
+	defer lock.RUnlock()
+
+	keys := make([]string, 0, len(checks))
+	for key := range checks {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	ready := true
+	info := ""
+	for _, key := range keys {
+		if err := checks[key](); err != nil {
+			ready = false
+			info = fmt.Sprintf("%s%s: not ready: %s\n", info, key, err)
+		} else {
+			info = fmt.Sprintf("%s%s: ok\n", info, key)
+		}
+	}
+	return ready, info
+}
+
+func IsReady() bool {
+	ready, _ := ReadyInfo()
+	return ready
+}
+
+// Readyz is a HTTP handler for the /readyz endpoint which responses with 200 OK status code
+// if all readiness checks succeed; and with 503 Service Unavailable status code otherwise.
+func Readyz(w http.ResponseWriter, r *http.Request) {
+	ok, info := ReadyInfo()
+	if ok {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	io.WriteString(w, info)
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/certs"
+	"github.com/gardener/controller-manager-library/pkg/logger"
+	"github.com/gardener/controller-manager-library/pkg/server/healthz"
+	"github.com/gardener/controller-manager-library/pkg/server/readyz"
+)
+
+// DefaultShutdownTimeout is the maximum time waiting for in-flight
+// requests when the server is shut down.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// Server is a HTTPS server serving the certificate of a certificate
+// source. Handlers are registered per path, the healthz endpoint is
+// served by default. The server is ready as soon as its listener is
+// bound and the certificate source provides a valid certificate.
+type Server struct {
+	lock            sync.Mutex
+	name            string
+	logger          logger.LogContext
+	source          certs.CertificateSource
+	tlsOptions      *certs.TLSOptions
+	shutdownTimeout time.Duration
+	mux             *http.ServeMux
+
+	listener net.Listener
+	ready    chan struct{}
+	done     chan struct{}
+}
+
+func NewServer(name string, logger logger.LogContext, source certs.CertificateSource) *Server {
+	this := &Server{
+		name:            name,
+		logger:          logger,
+		source:          source,
+		shutdownTimeout: DefaultShutdownTimeout,
+		mux:             http.NewServeMux(),
+		ready:           make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+	this.mux.HandleFunc("/healthz", healthz.Healthz)
+	return this
+}
+
+// SetTLSOptions sets the options used to create the TLS configuration.
+// It must be called before the server is started.
+func (this *Server) SetTLSOptions(opts *certs.TLSOptions) *Server {
+	this.tlsOptions = opts
+	return this
+}
+
+// SetShutdownTimeout sets the maximum time waiting for in-flight requests
+// on shutdown.
+func (this *Server) SetShutdownTimeout(timeout time.Duration) *Server {
+	this.shutdownTimeout = timeout
+	return this
+}
+
+func (this *Server) GetName() string {
+	return this.name
+}
+
+func (this *Server) Register(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	this.logger.Infof("adding %s endpoint to %s", pattern, this.name)
+	this.mux.HandleFunc(pattern, handler)
+}
+
+func (this *Server) RegisterHandler(pattern string, handler http.Handler) {
+	this.logger.Infof("adding %s endpoint to %s", pattern, this.name)
+	this.mux.Handle(pattern, handler)
+}
+
+// Start binds the listener and serves requests until the context is done.
+// On shutdown in-flight requests are drained for at most the shutdown
+// timeout. A readiness check for the server is registered for the
+// readyz endpoint as long as the server is running.
+func (this *Server) Start(ctx context.Context, bindAddress string, port int) error {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.listener != nil {
+		return fmt.Errorf("server %s already started", this.name)
+	}
+
+	opts := certs.TLSOptions{}
+	if this.tlsOptions != nil {
+		opts = *this.tlsOptions
+	}
+	if opts.Context == nil {
+		opts.Context = ctx
+	}
+	cfg := certs.TLSConfigFor(this.source, &opts)
+
+	listenAddress := fmt.Sprintf("%s:%d", bindAddress, port)
+	listener, err := net.Listen("tcp", listenAddress)
+	if err != nil {
+		return fmt.Errorf("cannot listen on %s: %s", listenAddress, err)
+	}
+	this.listener = listener
+	server := &http.Server{Handler: this.mux, TLSConfig: cfg}
+
+	key := "server/" + this.name
+	readyz.Register(key, this.Check)
+
+	// done is closed by the shutdown after all in-flight requests are
+	// drained, or by the serve loop if it fails without a shutdown.
+	once := sync.Once{}
+	finish := func() { once.Do(func() { close(this.done) }) }
+
+	go func() {
+		<-ctx.Done()
+		this.logger.Infof("shutting down %s with timeout %s", this.name, this.shutdownTimeout)
+		readyz.Unregister(key)
+		sctx, cancel := context.WithTimeout(context.Background(), this.shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(sctx); err != nil {
+			this.logger.Warnf("shutdown of %s incomplete: %s", this.name, err)
+		}
+		finish()
+	}()
+
+	go func() {
+		this.logger.Infof("HTTPS server %s started (serving on %s)", this.name, listener.Addr())
+		close(this.ready)
+		err := server.ServeTLS(listener, "", "")
+		if err != nil && err != http.ErrServerClosed {
+			this.logger.Errorf("HTTPS server %s failed: %s", this.name, err)
+			if ctx.Err() == nil {
+				finish()
+			}
+		}
+		this.logger.Infof("HTTPS server %s stopped", this.name)
+	}()
+	return nil
+}
+
+// Addr returns the address of the bound listener or nil if the server
+// is not started.
+func (this *Server) Addr() net.Addr {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	if this.listener == nil {
+		return nil
+	}
+	return this.listener.Addr()
+}
+
+// Ready is closed as soon as the listener is bound.
+func (this *Server) Ready() <-chan struct{} {
+	return this.ready
+}
+
+// Done is closed when the server is stopped and all in-flight requests
+// are drained.
+func (this *Server) Done() <-chan struct{} {
+	return this.done
+}
+
+// Check reports an error as long as the server cannot serve requests.
+func (this *Server) Check() error {
+	select {
+	case <-this.done:
+		return fmt.Errorf("server stopped")
+	default:
+	}
+	select {
+	case <-this.ready:
+	default:
+		return fmt.Errorf("server not listening")
+	}
+	return certs.CheckCertificate(this.source)
+}
+
+func (this *Server) IsReady() bool {
+	return this.Check() == nil
+}
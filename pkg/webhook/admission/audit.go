@@ -0,0 +1,142 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package admission
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gardener/controller-manager-library/pkg/logger"
+	"github.com/gardener/controller-manager-library/pkg/resources"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// AuditRecord describes an admission decision.
+type AuditRecord struct {
+	Webhook   string
+	UID       types.UID
+	User      string
+	Groups    []string
+	Operation Operation
+	Kind      schema.GroupVersionKind
+	Namespace string
+	Name      string
+	DryRun    bool
+	Allowed   bool
+	Patched   bool
+	Reason    string
+	// Object is the raw payload of the request, it is empty
+	// for redacted kinds.
+	Object runtime.RawExtension
+}
+
+func (this *AuditRecord) Decision() string {
+	if this.Allowed {
+		return "allowed"
+	}
+	return "denied"
+}
+
+// AuditSink records admission decisions.
+type AuditSink interface {
+	Record(record *AuditRecord)
+}
+
+type AuditSinkFunc func(record *AuditRecord)
+
+func (this AuditSinkFunc) Record(record *AuditRecord) {
+	this(record)
+}
+
+// DefaultRedactedKinds are the kinds whose payloads are never passed
+// to an audit sink.
+var DefaultRedactedKinds = []schema.GroupVersionKind{
+	corev1.SchemeGroupVersion.WithKind("Secret"),
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type logSink struct {
+	logger logger.LogContext
+}
+
+// NewLogSink creates an audit sink writing structured log entries.
+func NewLogSink(logger logger.LogContext) AuditSink {
+	return &logSink{logger}
+}
+
+func (this *logSink) Record(r *AuditRecord) {
+	fields := []string{
+		logField("webhook", r.Webhook),
+		logField("uid", string(r.UID)),
+		logField("user", r.User),
+		logField("groups", strings.Join(r.Groups, ",")),
+		logField("operation", string(r.Operation)),
+		logField("kind", r.Kind.GroupVersion().String()+"/"+r.Kind.Kind),
+		logField("namespace", r.Namespace),
+		logField("name", r.Name),
+		logField("dryrun", fmt.Sprintf("%t", r.DryRun)),
+		logField("decision", r.Decision()),
+		logField("patched", fmt.Sprintf("%t", r.Patched)),
+	}
+	if r.Reason != "" {
+		fields = append(fields, logField("reason", r.Reason))
+	}
+	if len(r.Object.Raw) > 0 {
+		fields = append(fields, logField("object", string(r.Object.Raw)))
+	}
+	this.logger.Infof("admission audit: %s", strings.Join(fields, " "))
+}
+
+func logField(key, value string) string {
+	return fmt.Sprintf("%s=%q", key, value)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type eventSink struct {
+	resources  resources.Resources
+	deniedOnly bool
+}
+
+// NewEventSink creates an audit sink recording admission decisions as
+// events for the requested objects. If deniedOnly is set only denied
+// requests are recorded.
+func NewEventSink(res resources.Resources, deniedOnly bool) AuditSink {
+	return &eventSink{resources: res, deniedOnly: deniedOnly}
+}
+
+func (this *eventSink) Record(r *AuditRecord) {
+	if r.Name == "" || (r.Allowed && this.deniedOnly) {
+		return
+	}
+	ref := &corev1.ObjectReference{
+		APIVersion: r.Kind.GroupVersion().String(),
+		Kind:       r.Kind.Kind,
+		Namespace:  r.Namespace,
+		Name:       r.Name,
+	}
+	if r.Allowed {
+		this.resources.Eventf(ref, corev1.EventTypeNormal, "AdmissionAllowed", "%s by %s allowed by %s", r.Operation, r.User, r.Webhook)
+	} else {
+		this.resources.Eventf(ref, corev1.EventTypeWarning, "AdmissionDenied", "%s by %s denied by %s: %s", r.Operation, r.User, r.Webhook, r.Reason)
+	}
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package admission
+
+import (
+	"fmt"
+
+	"github.com/gardener/controller-manager-library/pkg/resources"
+)
+
+// ErrDryRun is returned by the client of a dry-run request for all
+// write operations.
+var ErrDryRun = fmt.Errorf("write operations are not permitted for dry-run requests")
+
+// Client provides cluster access for admission handlers. For dry-run
+// requests all write operations are refused with ErrDryRun.
+type Client interface {
+	IsDryRun() bool
+
+	Get(spec interface{}) (resources.Object, error)
+	GetCached(spec interface{}) (resources.Object, error)
+
+	Create(obj resources.ObjectData) (resources.Object, error)
+	CreateOrUpdate(obj resources.ObjectData) (resources.Object, error)
+	Update(obj resources.ObjectData) (resources.Object, error)
+	Delete(obj resources.ObjectData) error
+}
+
+type client struct {
+	resources resources.Resources
+	dryrun    bool
+}
+
+var _ Client = &client{}
+
+func newClient(res resources.Resources, dryrun bool) Client {
+	return &client{resources: res, dryrun: dryrun}
+}
+
+func (this *client) IsDryRun() bool {
+	return this.dryrun
+}
+
+func (this *client) check(write bool) error {
+	if this.resources == nil {
+		return fmt.Errorf("no cluster access configured for admission webhook")
+	}
+	if write && this.dryrun {
+		return ErrDryRun
+	}
+	return nil
+}
+
+func (this *client) Get(spec interface{}) (resources.Object, error) {
+	if err := this.check(false); err != nil {
+		return nil, err
+	}
+	return this.resources.GetObject(spec)
+}
+
+func (this *client) GetCached(spec interface{}) (resources.Object, error) {
+	if err := this.check(false); err != nil {
+		return nil, err
+	}
+	return this.resources.GetCachedObject(spec)
+}
+
+func (this *client) Create(obj resources.ObjectData) (resources.Object, error) {
+	if err := this.check(true); err != nil {
+		return nil, err
+	}
+	return this.resources.CreateObject(obj)
+}
+
+func (this *client) CreateOrUpdate(obj resources.ObjectData) (resources.Object, error) {
+	if err := this.check(true); err != nil {
+		return nil, err
+	}
+	return this.resources.CreateOrUpdateObject(obj)
+}
+
+func (this *client) Update(obj resources.ObjectData) (resources.Object, error) {
+	if err := this.check(true); err != nil {
+		return nil, err
+	}
+	r, err := this.resources.Get(obj)
+	if err != nil {
+		return nil, err
+	}
+	return r.Update(obj)
+}
+
+func (this *client) Delete(obj resources.ObjectData) error {
+	if err := this.check(true); err != nil {
+		return err
+	}
+	return this.resources.DeleteObject(obj)
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package admission
+
+import (
+	"fmt"
+
+	"github.com/gardener/controller-manager-library/pkg/resources"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+)
+
+// Decoder decodes raw objects into their typed representation if
+// the kind is registered in the scheme and into unstructured objects
+// otherwise.
+type Decoder struct {
+	scheme  *runtime.Scheme
+	decoder runtime.Decoder
+}
+
+func NewDecoder(scheme *runtime.Scheme) *Decoder {
+	if scheme == nil {
+		scheme = resources.DefaultScheme()
+	}
+	return &Decoder{
+		scheme:  scheme,
+		decoder: serializer.NewCodecFactory(scheme).UniversalDeserializer(),
+	}
+}
+
+// Decode decodes a raw object of the given kind. It returns nil
+// for an empty raw object.
+func (this *Decoder) Decode(raw runtime.RawExtension, gvk schema.GroupVersionKind) (resources.ObjectData, error) {
+	if len(raw.Raw) == 0 {
+		return nil, nil
+	}
+	if this.scheme.Recognizes(gvk) {
+		obj, _, err := this.decoder.Decode(raw.Raw, &gvk, nil)
+		if err != nil {
+			return nil, err
+		}
+		data, ok := obj.(resources.ObjectData)
+		if !ok {
+			return nil, fmt.Errorf("unexpected object type %T", obj)
+		}
+		data.GetObjectKind().SetGroupVersionKind(gvk)
+		return data, nil
+	}
+	u := &unstructured.Unstructured{}
+	if err := u.UnmarshalJSON(raw.Raw); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
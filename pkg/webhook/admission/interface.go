@@ -0,0 +1,101 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package admission
+
+import (
+	"github.com/gardener/controller-manager-library/pkg/resources"
+
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+)
+
+// Request is an admission request with decoded objects. Objects of
+// kinds registered in the scheme are decoded into their typed
+// representation, all other objects are decoded as unstructured.
+type Request struct {
+	*AdmissionRequest
+	// Object is the new object, it is nil for DELETE operations.
+	// Mutators modify this object in place or replace it.
+	Object resources.ObjectData
+	// OldObject is the existing object for UPDATE and DELETE operations.
+	OldObject resources.ObjectData
+
+	client Client
+}
+
+// IsDryRun reports whether the request is a dry-run request. Handlers
+// must not cause side effects for dry-run requests.
+func (this *Request) IsDryRun() bool {
+	return this.DryRun != nil && *this.DryRun
+}
+
+// Client returns a client for the cluster of the webhook refusing
+// write operations for dry-run requests.
+func (this *Request) Client() Client {
+	return this.client
+}
+
+// Validator validates an admission request. A returned error denies
+// the request. Errors implementing the kubernetes APIStatus interface
+// (for example created by Invalid) are passed to the API server as they
+// are, preserving the status causes.
+type Validator interface {
+	Validate(req *Request) error
+}
+
+// Validation is a CEL validation of a ValidatingAdmissionPolicy
+// equivalent to the validation of a validator.
+type Validation struct {
+	Expression        string `json:"expression"`
+	Message           string `json:"message,omitempty"`
+	MessageExpression string `json:"messageExpression,omitempty"`
+	Reason            string `json:"reason,omitempty"`
+}
+
+// DeclarativeValidator is implemented by validators whose validation
+// can be expressed declaratively by CEL validations. It enables the
+// enforcement by a ValidatingAdmissionPolicy instead of the webhook.
+type DeclarativeValidator interface {
+	Validator
+	Validations() []Validation
+}
+
+// Mutator mutates the object of an admission request. The required
+// JSON patch is created automatically from the mutated object.
+// A returned error denies the request.
+type Mutator interface {
+	Mutate(req *Request) error
+}
+
+// SideEffectsDeclaration can be implemented by validators and mutators to
+// declare their side effects. Handlers not implementing this interface
+// are considered to be free of side effects. Handlers with side effects
+// should use the client of the request and declare NoneOnDryRun.
+type SideEffectsDeclaration interface {
+	SideEffects() admissionregistration.SideEffectClass
+}
+
+type ValidatorFunc func(req *Request) error
+
+func (this ValidatorFunc) Validate(req *Request) error {
+	return this(req)
+}
+
+type MutatorFunc func(req *Request) error
+
+func (this MutatorFunc) Mutate(req *Request) error {
+	return this(req)
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package admission
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PatchOperation is a single JSON patch (RFC 6902) operation.
+type PatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+func (this PatchOperation) MarshalJSON() ([]byte, error) {
+	if this.Op == "remove" {
+		return json.Marshal(map[string]string{"op": this.Op, "path": this.Path})
+	}
+	type op PatchOperation
+	return json.Marshal(op(this))
+}
+
+// CreateJSONPatch creates the JSON patch transforming the JSON document
+// from into the document to. Arrays differing in length are replaced
+// as a whole.
+func CreateJSONPatch(from, to []byte) ([]PatchOperation, error) {
+	var a, b interface{}
+	if err := json.Unmarshal(from, &a); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(to, &b); err != nil {
+		return nil, err
+	}
+	return diff(nil, "", a, b), nil
+}
+
+func diff(ops []PatchOperation, path string, a, b interface{}) []PatchOperation {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		if bv, ok := b.(map[string]interface{}); ok {
+			return diffMap(ops, path, av, bv)
+		}
+	case []interface{}:
+		if bv, ok := b.([]interface{}); ok && len(av) == len(bv) {
+			for i := range av {
+				ops = diff(ops, path+"/"+strconv.Itoa(i), av[i], bv[i])
+			}
+			return ops
+		}
+	}
+	if !reflect.DeepEqual(a, b) {
+		ops = append(ops, PatchOperation{Op: "replace", Path: path, Value: b})
+	}
+	return ops
+}
+
+func diffMap(ops []PatchOperation, path string, a, b map[string]interface{}) []PatchOperation {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		p := path + "/" + escapePointer(k)
+		av, aok := a[k]
+		bv, bok := b[k]
+		switch {
+		case !bok:
+			ops = append(ops, PatchOperation{Op: "remove", Path: p})
+		case !aok:
+			ops = append(ops, PatchOperation{Op: "add", Path: p, Value: bv})
+		default:
+			ops = diff(ops, p, av, bv)
+		}
+	}
+	return ops
+}
+
+func escapePointer(s string) string {
+	return strings.Replace(strings.Replace(s, "~", "~0", -1), "/", "~1", -1)
+}
+
+// CreateMutationPatch creates the JSON patch applying the changes from
+// the document orig to the document mod to the document raw. orig is
+// the serialized decoded form of raw, so it may lack fields unknown to
+// the decoding types or contain additional empty fields. Only the
+// changed fields are patched, fields of raw untouched by the change are
+// kept.
+func CreateMutationPatch(raw, orig, mod []byte) ([]PatchOperation, error) {
+	var r, a, b interface{}
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(orig, &a); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(mod, &b); err != nil {
+		return nil, err
+	}
+	return mergeDiff(nil, "", r, a, b), nil
+}
+
+func mergeDiff(ops []PatchOperation, path string, r, a, b interface{}) []PatchOperation {
+	if reflect.DeepEqual(a, b) {
+		return ops
+	}
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, bok := b.(map[string]interface{})
+		rv, rok := r.(map[string]interface{})
+		if bok && rok {
+			keys := make([]string, 0, len(av)+len(bv))
+			for k := range av {
+				keys = append(keys, k)
+			}
+			for k := range bv {
+				if _, ok := av[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				p := path + "/" + escapePointer(k)
+				v, bok := bv[k]
+				old, rok := rv[k]
+				switch {
+				case reflect.DeepEqual(av[k], v):
+				case !bok:
+					if rok {
+						ops = append(ops, PatchOperation{Op: "remove", Path: p})
+					}
+				case !rok:
+					ops = append(ops, PatchOperation{Op: "add", Path: p, Value: v})
+				default:
+					ops = mergeDiff(ops, p, old, av[k], v)
+				}
+			}
+			return ops
+		}
+	case []interface{}:
+		bv, bok := b.([]interface{})
+		rv, rok := r.([]interface{})
+		if bok && rok && len(av) == len(rv) {
+			// common elements are patched, so that fields of raw elements
+			// are kept, additional elements are appended or removed
+			for i := 0; i < len(av) && i < len(bv); i++ {
+				ops = mergeDiff(ops, path+"/"+strconv.Itoa(i), rv[i], av[i], bv[i])
+			}
+			for i := len(av); i < len(bv); i++ {
+				ops = append(ops, PatchOperation{Op: "add", Path: path + "/" + strconv.Itoa(i), Value: bv[i]})
+			}
+			for i := len(av) - 1; i >= len(bv); i-- {
+				ops = append(ops, PatchOperation{Op: "remove", Path: path + "/" + strconv.Itoa(i)})
+			}
+			return ops
+		}
+	}
+	return append(ops, PatchOperation{Op: "replace", Path: path, Value: b})
+}
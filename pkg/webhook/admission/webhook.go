@@ -0,0 +1,354 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package admission
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/gardener/controller-manager-library/pkg/logger"
+	"github.com/gardener/controller-manager-library/pkg/resources"
+	"github.com/gardener/controller-manager-library/pkg/webhook"
+
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Webhook is a HTTP handler for admission reviews dispatching the
+// requests to the validators and mutators registered for the kind
+// of the requested object. Mutators are called first, in the order
+// of their registration, validators see the mutated object.
+// Requests for kinds without registered handlers are allowed.
+type Webhook struct {
+	lock       sync.RWMutex
+	name       string
+	logger     logger.LogContext
+	decoder    *Decoder
+	resources  resources.Resources
+	audit      AuditSink
+	redacted   map[schema.GroupVersionKind]bool
+	validators map[schema.GroupVersionKind][]Validator
+	mutators   map[schema.GroupVersionKind][]Mutator
+}
+
+var _ http.Handler = &Webhook{}
+
+// New creates an admission webhook decoding objects with the given
+// scheme. If no scheme is given the default scheme of the resources
+// layer is used.
+func New(name string, logger logger.LogContext, scheme *runtime.Scheme) *Webhook {
+	this := &Webhook{
+		name:       name,
+		logger:     logger,
+		decoder:    NewDecoder(scheme),
+		validators: map[schema.GroupVersionKind][]Validator{},
+		mutators:   map[schema.GroupVersionKind][]Mutator{},
+		redacted:   map[schema.GroupVersionKind]bool{},
+	}
+	return this.RedactPayload(DefaultRedactedKinds...)
+}
+
+func (this *Webhook) GetName() string {
+	return this.name
+}
+
+// SetResources sets the cluster access offered to the handlers by
+// the client of the requests.
+func (this *Webhook) SetResources(res resources.Resources) *Webhook {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.resources = res
+	return this
+}
+
+// SetAuditSink sets an optional sink recording all admission decisions.
+func (this *Webhook) SetAuditSink(sink AuditSink) *Webhook {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.audit = sink
+	return this
+}
+
+// RedactPayload excludes the object payloads of the given kinds from
+// the audit records.
+func (this *Webhook) RedactPayload(gvks ...schema.GroupVersionKind) *Webhook {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	for _, gvk := range gvks {
+		this.redacted[gvk] = true
+	}
+	return this
+}
+
+func (this *Webhook) AddValidator(gvk schema.GroupVersionKind, v Validator) *Webhook {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.validators[gvk] = append(this.validators[gvk], v)
+	return this
+}
+
+func (this *Webhook) AddMutator(gvk schema.GroupVersionKind, m Mutator) *Webhook {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.mutators[gvk] = append(this.mutators[gvk], m)
+	return this
+}
+
+// SideEffects returns the side effect class of the webhook determined
+// by the most significant side effects declared by its handlers.
+func (this *Webhook) SideEffects() admissionregistration.SideEffectClass {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	result := admissionregistration.SideEffectClassNone
+	add := func(h interface{}) {
+		if d, ok := h.(SideEffectsDeclaration); ok {
+			if c := d.SideEffects(); sideEffectsOrder[c] > sideEffectsOrder[result] {
+				result = c
+			}
+		}
+	}
+	for _, list := range this.mutators {
+		for _, m := range list {
+			add(m)
+		}
+	}
+	for _, list := range this.validators {
+		for _, v := range list {
+			add(v)
+		}
+	}
+	return result
+}
+
+// Validations returns the CEL validations of all declarative validators.
+// Additionally it reports whether the webhook can be completely replaced
+// by these validations, which requires all handlers to be declarative
+// validators. If validators are registered for multiple kinds, the
+// expressions are restricted to the kind of their validator.
+func (this *Webhook) Validations() ([]Validation, bool) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+
+	gvks := make([]schema.GroupVersionKind, 0, len(this.validators))
+	kinds := map[schema.GroupKind]bool{}
+	for gvk := range this.validators {
+		gvks = append(gvks, gvk)
+		kinds[gvk.GroupKind()] = true
+	}
+	sort.Slice(gvks, func(i, j int) bool { return gvks[i].String() < gvks[j].String() })
+
+	var result []Validation
+	complete := len(this.mutators) == 0
+	for _, gvk := range gvks {
+		for _, v := range this.validators[gvk] {
+			d, ok := v.(DeclarativeValidator)
+			if !ok {
+				complete = false
+				continue
+			}
+			for _, validation := range d.Validations() {
+				if len(kinds) > 1 {
+					validation.Expression = fmt.Sprintf("request.kind.group != %q || request.kind.kind != %q || (%s)",
+						gvk.Group, gvk.Kind, validation.Expression)
+				}
+				result = append(result, validation)
+			}
+		}
+	}
+	return result, complete && len(result) > 0
+}
+
+var sideEffectsOrder = map[admissionregistration.SideEffectClass]int{
+	admissionregistration.SideEffectClassNone:         0,
+	admissionregistration.SideEffectClassNoneOnDryRun: 1,
+	admissionregistration.SideEffectClassSome:         2,
+	admissionregistration.SideEffectClassUnknown:      3,
+}
+
+func (this *Webhook) handlers(gvk schema.GroupVersionKind) ([]Mutator, []Validator, resources.Resources) {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.mutators[gvk], this.validators[gvk], this.resources
+}
+
+func (this *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	webhook.Instrument(webhook.Admission, w, r, this.serveHTTP)
+}
+
+func (this *Webhook) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, fmt.Sprintf("method %s not supported", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+		http.Error(w, fmt.Sprintf("content type %q not supported", ct), http.StatusUnsupportedMediaType)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("cannot read request: %s", err), http.StatusBadRequest)
+		return
+	}
+	review := &AdmissionReview{}
+	if err := json.Unmarshal(body, review); err != nil {
+		http.Error(w, fmt.Sprintf("invalid admission review: %s", err), http.StatusBadRequest)
+		return
+	}
+	if !isSupported(review.APIVersion) || review.Kind != KindAdmissionReview {
+		http.Error(w, fmt.Sprintf("unsupported admission review version %s", review.GroupVersionKind()), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "admission review without request", http.StatusBadRequest)
+		return
+	}
+
+	resp := this.Admit(review.Request)
+	webhook.ReportAdmission(r.URL.Path, resp.Allowed, resp.Patch)
+	result := &AdmissionReview{TypeMeta: review.TypeMeta, Response: resp}
+	data, err := json.Marshal(result)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("cannot marshal admission review: %s", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// Admit handles a single admission request.
+func (this *Webhook) Admit(req *AdmissionRequest) *AdmissionResponse {
+	resp := this.admit(req)
+	resp.UID = req.UID
+	op := string(req.Operation)
+	if req.DryRun != nil && *req.DryRun {
+		op += " (dry-run)"
+	}
+	this.record(req, resp)
+	if resp.Allowed {
+		this.logger.Debugf("%s: %s %s %s/%s allowed", this.name, op, req.Kind.Kind, req.Namespace, req.Name)
+	} else {
+		this.logger.Infof("%s: %s %s %s/%s denied: %s", this.name, op, req.Kind.Kind, req.Namespace, req.Name, resp.Result.Message)
+	}
+	return resp
+}
+
+func (this *Webhook) admit(req *AdmissionRequest) *AdmissionResponse {
+	gvk := schema.GroupVersionKind{Group: req.Kind.Group, Version: req.Kind.Version, Kind: req.Kind.Kind}
+	mutators, validators, res := this.handlers(gvk)
+	if len(mutators) == 0 && len(validators) == 0 {
+		return &AdmissionResponse{Allowed: true}
+	}
+
+	obj, err := this.decoder.Decode(req.Object, gvk)
+	if err != nil {
+		return &AdmissionResponse{Result: badRequest("cannot decode object: %s", err)}
+	}
+	old, err := this.decoder.Decode(req.OldObject, gvk)
+	if err != nil {
+		return &AdmissionResponse{Result: badRequest("cannot decode old object: %s", err)}
+	}
+	request := &Request{AdmissionRequest: req, Object: obj, OldObject: old}
+	request.client = newClient(res, request.IsDryRun())
+
+	var patch []PatchOperation
+	if len(mutators) > 0 && obj != nil {
+		// the patch contains only the changes of the mutators, applied to
+		// the raw object: decoding may drop fields unknown to the types
+		// or add empty fields not present in the request
+		orig, err := json.Marshal(obj)
+		if err != nil {
+			return &AdmissionResponse{Result: internalError("cannot marshal object: %s", err)}
+		}
+		for _, m := range mutators {
+			if err := m.Mutate(request); err != nil {
+				return &AdmissionResponse{Result: statusFor(err)}
+			}
+		}
+		if request.Object == nil {
+			return &AdmissionResponse{Result: internalError("mutated object must not be nil")}
+		}
+		if request.Object.GetObjectKind().GroupVersionKind().Empty() {
+			request.Object.GetObjectKind().SetGroupVersionKind(gvk)
+		}
+		mod, err := json.Marshal(request.Object)
+		if err != nil {
+			return &AdmissionResponse{Result: internalError("cannot marshal mutated object: %s", err)}
+		}
+		if !bytes.Equal(orig, mod) {
+			patch, err = CreateMutationPatch(req.Object.Raw, orig, mod)
+			if err != nil {
+				return &AdmissionResponse{Result: internalError("cannot create patch: %s", err)}
+			}
+		}
+	}
+
+	for _, v := range validators {
+		if err := v.Validate(request); err != nil {
+			return &AdmissionResponse{Result: statusFor(err)}
+		}
+	}
+
+	resp := &AdmissionResponse{Allowed: true}
+	if len(patch) > 0 {
+		data, err := json.Marshal(patch)
+		if err != nil {
+			return &AdmissionResponse{Result: internalError("cannot marshal patch: %s", err)}
+		}
+		pt := PatchTypeJSONPatch
+		resp.Patch = data
+		resp.PatchType = &pt
+	}
+	return resp
+}
+
+func (this *Webhook) record(req *AdmissionRequest, resp *AdmissionResponse) {
+	this.lock.RLock()
+	sink := this.audit
+	gvk := schema.GroupVersionKind{Group: req.Kind.Group, Version: req.Kind.Version, Kind: req.Kind.Kind}
+	redacted := this.redacted[gvk]
+	this.lock.RUnlock()
+	if sink == nil {
+		return
+	}
+	record := &AuditRecord{
+		Webhook:   this.name,
+		UID:       req.UID,
+		User:      req.UserInfo.Username,
+		Groups:    req.UserInfo.Groups,
+		Operation: req.Operation,
+		Kind:      gvk,
+		Namespace: req.Namespace,
+		Name:      req.Name,
+		DryRun:    req.DryRun != nil && *req.DryRun,
+		Allowed:   resp.Allowed,
+		Patched:   len(resp.Patch) > 0,
+	}
+	if resp.Result != nil {
+		record.Reason = resp.Result.Message
+	}
+	if !redacted {
+		record.Object = req.Object
+	}
+	sink.Record(record)
+}
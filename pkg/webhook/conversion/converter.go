@@ -0,0 +1,67 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package conversion
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Converter converts an object into another version of its group.
+type Converter interface {
+	Convert(obj *unstructured.Unstructured, to schema.GroupVersion) (*unstructured.Unstructured, error)
+}
+
+type ConverterFunc func(obj *unstructured.Unstructured, to schema.GroupVersion) (*unstructured.Unstructured, error)
+
+func (this ConverterFunc) Convert(obj *unstructured.Unstructured, to schema.GroupVersion) (*unstructured.Unstructured, error) {
+	return this(obj, to)
+}
+
+type schemeConverter struct {
+	scheme *runtime.Scheme
+}
+
+// NewSchemeConverter creates a converter using the conversion functions
+// registered in the given scheme for the typed versions of the objects.
+func NewSchemeConverter(scheme *runtime.Scheme) Converter {
+	return &schemeConverter{scheme}
+}
+
+func (this *schemeConverter) Convert(obj *unstructured.Unstructured, to schema.GroupVersion) (*unstructured.Unstructured, error) {
+	typed, err := this.scheme.New(obj.GroupVersionKind())
+	if err != nil {
+		return nil, err
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, typed); err != nil {
+		return nil, fmt.Errorf("cannot decode %s: %s", obj.GroupVersionKind(), err)
+	}
+	converted, err := this.scheme.ConvertToVersion(typed, to)
+	if err != nil {
+		return nil, err
+	}
+	data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(converted)
+	if err != nil {
+		return nil, err
+	}
+	result := &unstructured.Unstructured{Object: data}
+	result.SetGroupVersionKind(to.WithKind(obj.GetKind()))
+	return result, nil
+}
@@ -0,0 +1,204 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package conversion
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/gardener/controller-manager-library/pkg/logger"
+	"github.com/gardener/controller-manager-library/pkg/webhook"
+
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// The conversion review types are structurally identical for the
+// v1 and v1beta1 versions of the apiextensions.k8s.io API group,
+// responses are sent in the version of the request.
+var supportedVersions = map[string]bool{
+	v1beta1.GroupName + "/v1beta1": true,
+	v1beta1.GroupName + "/v1":      true,
+}
+
+const kindConversionReview = "ConversionReview"
+
+type conversionKey struct {
+	from schema.GroupVersionKind
+	to   string
+}
+
+// Webhook is a HTTP handler for conversion reviews of custom resources.
+// Objects are converted by converters registered for dedicated pairs of
+// source kind and target version. Other conversions are handled by the
+// converter registered for the group kind, if present.
+type Webhook struct {
+	lock        sync.RWMutex
+	name        string
+	logger      logger.LogContext
+	conversions map[conversionKey]Converter
+	converters  map[schema.GroupKind]Converter
+}
+
+var _ http.Handler = &Webhook{}
+
+func New(name string, logger logger.LogContext) *Webhook {
+	return &Webhook{
+		name:        name,
+		logger:      logger,
+		conversions: map[conversionKey]Converter{},
+		converters:  map[schema.GroupKind]Converter{},
+	}
+}
+
+func (this *Webhook) GetName() string {
+	return this.name
+}
+
+// AddConversion registers a converter for objects of the given kind
+// and version to be converted into the given version.
+func (this *Webhook) AddConversion(from schema.GroupVersionKind, toVersion string, c Converter) *Webhook {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.conversions[conversionKey{from, toVersion}] = c
+	return this
+}
+
+// SetConverter registers the default converter for a group kind,
+// for example a scheme based converter.
+func (this *Webhook) SetConverter(gk schema.GroupKind, c Converter) *Webhook {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.converters[gk] = c
+	return this
+}
+
+func (this *Webhook) converter(from schema.GroupVersionKind, to schema.GroupVersion) Converter {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	if c := this.conversions[conversionKey{from, to.Version}]; c != nil {
+		return c
+	}
+	return this.converters[from.GroupKind()]
+}
+
+func (this *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	webhook.Instrument(webhook.Conversion, w, r, this.serveHTTP)
+}
+
+func (this *Webhook) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, fmt.Sprintf("method %s not supported", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+		http.Error(w, fmt.Sprintf("content type %q not supported", ct), http.StatusUnsupportedMediaType)
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("cannot read request: %s", err), http.StatusBadRequest)
+		return
+	}
+	review := &v1beta1.ConversionReview{}
+	if err := json.Unmarshal(body, review); err != nil {
+		http.Error(w, fmt.Sprintf("invalid conversion review: %s", err), http.StatusBadRequest)
+		return
+	}
+	if !supportedVersions[review.APIVersion] || review.Kind != kindConversionReview {
+		http.Error(w, fmt.Sprintf("unsupported conversion review version %s", review.GroupVersionKind()), http.StatusBadRequest)
+		return
+	}
+	if review.Request == nil {
+		http.Error(w, "conversion review without request", http.StatusBadRequest)
+		return
+	}
+
+	resp := this.Convert(review.Request)
+	webhook.ReportConversion(r.URL.Path, resp.Result.Status == metav1.StatusSuccess)
+	result := &v1beta1.ConversionReview{TypeMeta: review.TypeMeta, Response: resp}
+	data, err := json.Marshal(result)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("cannot marshal conversion review: %s", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// Convert handles a single conversion request.
+func (this *Webhook) Convert(req *v1beta1.ConversionRequest) *v1beta1.ConversionResponse {
+	objects, err := this.convert(req)
+	if err != nil {
+		this.logger.Warnf("%s: conversion to %s failed: %s", this.name, req.DesiredAPIVersion, err)
+		return &v1beta1.ConversionResponse{
+			UID:              req.UID,
+			ConvertedObjects: []runtime.RawExtension{},
+			Result: metav1.Status{
+				Status:  metav1.StatusFailure,
+				Message: err.Error(),
+			},
+		}
+	}
+	return &v1beta1.ConversionResponse{
+		UID:              req.UID,
+		ConvertedObjects: objects,
+		Result:           metav1.Status{Status: metav1.StatusSuccess},
+	}
+}
+
+func (this *Webhook) convert(req *v1beta1.ConversionRequest) ([]runtime.RawExtension, error) {
+	to, err := schema.ParseGroupVersion(req.DesiredAPIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid desired api version: %s", err)
+	}
+	result := make([]runtime.RawExtension, 0, len(req.Objects))
+	for i, raw := range req.Objects {
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(raw.Raw); err != nil {
+			return nil, fmt.Errorf("cannot decode object %d: %s", i, err)
+		}
+		from := obj.GroupVersionKind()
+		if from.Group != to.Group {
+			return nil, fmt.Errorf("cannot convert %s into group %s", from, to.Group)
+		}
+		if from.Version != to.Version {
+			c := this.converter(from, to)
+			if c == nil {
+				return nil, fmt.Errorf("no conversion from %s to %s", from, to)
+			}
+			converted, err := c.Convert(obj.DeepCopy(), to)
+			if err != nil {
+				return nil, fmt.Errorf("conversion of %s %s failed: %s", from.Kind, obj.GetName(), err)
+			}
+			converted.SetAPIVersion(to.String())
+			obj = converted
+		}
+		data, err := obj.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("cannot marshal object %d: %s", i, err)
+		}
+		result = append(result, runtime.RawExtension{Raw: data})
+	}
+	return result, nil
+}
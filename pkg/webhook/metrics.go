@@ -0,0 +1,89 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package webhook
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/metrics"
+)
+
+const (
+	Admission  = "admission"
+	Conversion = "conversion"
+)
+
+var (
+	requests = metrics.NewCounterVec("webhook_requests_total",
+		"Number of webhook requests", "type", "path", "code")
+	latency = metrics.NewHistogramVec("webhook_request_duration_seconds",
+		"Latency of webhook requests", nil, "type", "path")
+	admissions = metrics.NewCounterVec("webhook_admission_decisions_total",
+		"Number of admission decisions", "path", "decision")
+	patchSizes = metrics.NewHistogramVec("webhook_admission_patch_bytes",
+		"Size of admission patches", []float64{0, 64, 256, 1024, 4096, 16384, 65536}, "path")
+	conversions = metrics.NewCounterVec("webhook_conversions_total",
+		"Number of conversion requests", "path", "result")
+)
+
+func init() {
+	metrics.MustRegister(requests, latency, admissions, patchSizes, conversions)
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (this *statusRecorder) WriteHeader(code int) {
+	this.code = code
+	this.ResponseWriter.WriteHeader(code)
+}
+
+// Instrument calls a webhook handler of the given type and records the
+// request count and latency per path.
+func Instrument(wtype string, w http.ResponseWriter, r *http.Request, handler http.HandlerFunc) {
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+	handler(rec, r)
+	requests.WithLabelValues(wtype, r.URL.Path, strconv.Itoa(rec.code)).Inc()
+	latency.WithLabelValues(wtype, r.URL.Path).Observe(time.Since(start).Seconds())
+}
+
+// ReportAdmission records an admission decision and the size of the patch
+// for mutating webhooks.
+func ReportAdmission(path string, allowed bool, patch []byte) {
+	if allowed {
+		admissions.WithLabelValues(path, "allowed").Inc()
+	} else {
+		admissions.WithLabelValues(path, "denied").Inc()
+	}
+	if patch != nil {
+		patchSizes.WithLabelValues(path).Observe(float64(len(patch)))
+	}
+}
+
+// ReportConversion records the result of a conversion request.
+func ReportConversion(path string, success bool) {
+	if success {
+		conversions.WithLabelValues(path, "success").Inc()
+	} else {
+		conversions.WithLabelValues(path, "failure").Inc()
+	}
+}
@@ -0,0 +1,215 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package registration
+
+import (
+	"fmt"
+
+	"github.com/gardener/controller-manager-library/pkg/webhook/admission"
+
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type Kind string
+
+const (
+	Mutating   Kind = "MutatingWebhookConfiguration"
+	Validating Kind = "ValidatingWebhookConfiguration"
+)
+
+func (this Kind) GroupKind() schema.GroupKind {
+	return schema.GroupKind{Group: admissionregistration.GroupName, Kind: string(this)}
+}
+
+// Service describes the in-cluster service serving the webhooks.
+// A port other than 443 requires kubernetes 1.15 or newer.
+type Service struct {
+	Namespace string
+	Name      string
+	Port      int32
+}
+
+// Handler is implemented by webhook handlers declaring their side effects,
+// for example by admission webhooks.
+type Handler interface {
+	SideEffects() admissionregistration.SideEffectClass
+}
+
+// MatchCondition is a CEL expression which must evaluate to true for
+// a request to be sent to the webhook. Match conditions require
+// kubernetes 1.27 or newer.
+type MatchCondition struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+}
+
+// Webhook declares a single webhook of a webhook configuration.
+// Object selectors require kubernetes 1.15 or newer.
+type Webhook struct {
+	Name string
+	// Path is the path of the webhook handler on the webhook server.
+	Path          string
+	Rules         []admissionregistration.RuleWithOperations
+	FailurePolicy admissionregistration.FailurePolicyType
+	// SideEffects defaults to the side effects declared by the Handler
+	// or None if no handler is given.
+	SideEffects             admissionregistration.SideEffectClass
+	Handler                 Handler
+	NamespaceSelector       *metav1.LabelSelector
+	ObjectSelector          *metav1.LabelSelector
+	MatchConditions         []MatchCondition
+	TimeoutSeconds          *int32
+	AdmissionReviewVersions []string
+
+	// Policy enables the generation of a ValidatingAdmissionPolicy for
+	// validating webhooks. The Validations default to the validations
+	// declared by the Handler.
+	Policy      PolicyMode
+	Validations []admission.Validation
+}
+
+// Configuration declares a webhook configuration. The webhooks are
+// either served by an in-cluster service or by a base URL.
+type Configuration struct {
+	Name     string
+	Kind     Kind
+	Service  *Service
+	URL      string
+	Webhooks []Webhook
+}
+
+func (this *Configuration) validate() error {
+	if this.Name == "" {
+		return fmt.Errorf("webhook configuration name missing")
+	}
+	if this.Kind != Mutating && this.Kind != Validating {
+		return fmt.Errorf("invalid webhook configuration kind %q for %s", this.Kind, this.Name)
+	}
+	if (this.Service == nil) == (this.URL == "") {
+		return fmt.Errorf("either service or url required for webhook configuration %s", this.Name)
+	}
+	for _, w := range this.Webhooks {
+		if w.Name == "" {
+			return fmt.Errorf("webhook name missing in webhook configuration %s", this.Name)
+		}
+		names := map[string]bool{}
+		for _, c := range w.MatchConditions {
+			if c.Name == "" || c.Expression == "" {
+				return fmt.Errorf("match condition of webhook %s requires name and expression", w.Name)
+			}
+			if names[c.Name] {
+				return fmt.Errorf("duplicate match condition %q for webhook %s", c.Name, w.Name)
+			}
+			names[c.Name] = true
+		}
+	}
+	return nil
+}
+
+// The webhook specs are written in the wire format, which is identical for
+// the v1beta1 and v1 versions of the admissionregistration API group, to
+// support fields not present in the vendored API version.
+
+type webhookSpec struct {
+	Name                    string                                     `json:"name"`
+	ClientConfig            clientConfig                               `json:"clientConfig"`
+	Rules                   []admissionregistration.RuleWithOperations `json:"rules,omitempty"`
+	FailurePolicy           *admissionregistration.FailurePolicyType   `json:"failurePolicy,omitempty"`
+	NamespaceSelector       *metav1.LabelSelector                      `json:"namespaceSelector,omitempty"`
+	ObjectSelector          *metav1.LabelSelector                      `json:"objectSelector,omitempty"`
+	MatchConditions         []MatchCondition                           `json:"matchConditions,omitempty"`
+	SideEffects             *admissionregistration.SideEffectClass     `json:"sideEffects,omitempty"`
+	TimeoutSeconds          *int32                                     `json:"timeoutSeconds,omitempty"`
+	AdmissionReviewVersions []string                                   `json:"admissionReviewVersions,omitempty"`
+}
+
+type clientConfig struct {
+	URL      *string           `json:"url,omitempty"`
+	Service  *serviceReference `json:"service,omitempty"`
+	CABundle []byte            `json:"caBundle,omitempty"`
+}
+
+type serviceReference struct {
+	Namespace string  `json:"namespace"`
+	Name      string  `json:"name"`
+	Path      *string `json:"path,omitempty"`
+	Port      *int32  `json:"port,omitempty"`
+}
+
+// DefaultAdmissionReviewVersions are used for webhooks not declaring
+// the accepted admission review versions.
+var DefaultAdmissionReviewVersions = []string{"v1", "v1beta1"}
+
+func (this *Configuration) webhooks(bundle []byte, skip map[string]bool) ([]interface{}, error) {
+	result := []interface{}{}
+	for _, w := range this.Webhooks {
+		if skip[w.Name] {
+			continue
+		}
+		spec := &webhookSpec{
+			Name:                    w.Name,
+			Rules:                   w.Rules,
+			NamespaceSelector:       w.NamespaceSelector,
+			ObjectSelector:          w.ObjectSelector,
+			MatchConditions:         w.MatchConditions,
+			TimeoutSeconds:          w.TimeoutSeconds,
+			AdmissionReviewVersions: w.AdmissionReviewVersions,
+		}
+		spec.ClientConfig.CABundle = bundle
+		if this.Service != nil {
+			spec.ClientConfig.Service = &serviceReference{
+				Namespace: this.Service.Namespace,
+				Name:      this.Service.Name,
+			}
+			if w.Path != "" {
+				path := w.Path
+				spec.ClientConfig.Service.Path = &path
+			}
+			if this.Service.Port != 0 && this.Service.Port != 443 {
+				port := this.Service.Port
+				spec.ClientConfig.Service.Port = &port
+			}
+		} else {
+			url := this.URL + w.Path
+			spec.ClientConfig.URL = &url
+		}
+		if w.FailurePolicy != "" {
+			policy := w.FailurePolicy
+			spec.FailurePolicy = &policy
+		}
+		sideEffects := w.SideEffects
+		if sideEffects == "" && w.Handler != nil {
+			sideEffects = w.Handler.SideEffects()
+		}
+		if sideEffects == "" {
+			sideEffects = admissionregistration.SideEffectClassNone
+		}
+		spec.SideEffects = &sideEffects
+		if len(spec.AdmissionReviewVersions) == 0 {
+			spec.AdmissionReviewVersions = DefaultAdmissionReviewVersions
+		}
+		data, err := runtime.DefaultUnstructuredConverter.ToUnstructured(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook %s: %s", w.Name, err)
+		}
+		result = append(result, data)
+	}
+	return result, nil
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package registration
+
+import (
+	"fmt"
+
+	"github.com/gardener/controller-manager-library/pkg/webhook/admission"
+
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type PolicyMode string
+
+const (
+	// PolicyAdditional creates a ValidatingAdmissionPolicy in addition
+	// to the webhook.
+	PolicyAdditional PolicyMode = "Additional"
+	// PolicyPreferred replaces the webhook by a ValidatingAdmissionPolicy
+	// if the cluster supports them and the validations are complete. The
+	// webhook is kept as fallback for older clusters.
+	PolicyPreferred PolicyMode = "Preferred"
+)
+
+// PolicyHandler is implemented by webhook handlers declaring CEL
+// validations, for example by admission webhooks. It additionally
+// reports whether the validations completely replace the handler.
+type PolicyHandler interface {
+	Validations() ([]admission.Validation, bool)
+}
+
+var (
+	policyGroupKind  = schema.GroupKind{Group: admissionregistration.GroupName, Kind: "ValidatingAdmissionPolicy"}
+	bindingGroupKind = schema.GroupKind{Group: admissionregistration.GroupName, Kind: "ValidatingAdmissionPolicyBinding"}
+)
+
+type policy struct {
+	name    string
+	replace bool
+	spec    map[string]interface{}
+	binding map[string]interface{}
+}
+
+type policySpec struct {
+	FailurePolicy    *admissionregistration.FailurePolicyType `json:"failurePolicy,omitempty"`
+	MatchConstraints matchResources                           `json:"matchConstraints"`
+	MatchConditions  []MatchCondition                         `json:"matchConditions,omitempty"`
+	Validations      []admission.Validation                   `json:"validations"`
+}
+
+type matchResources struct {
+	NamespaceSelector *metav1.LabelSelector                      `json:"namespaceSelector,omitempty"`
+	ObjectSelector    *metav1.LabelSelector                      `json:"objectSelector,omitempty"`
+	ResourceRules     []admissionregistration.RuleWithOperations `json:"resourceRules,omitempty"`
+}
+
+type bindingSpec struct {
+	PolicyName        string   `json:"policyName"`
+	ValidationActions []string `json:"validationActions"`
+}
+
+// policies determines the validating admission policies for the
+// webhooks of a validating webhook configuration. The policies and
+// their bindings are named like the webhooks.
+func (this *Configuration) policies() ([]*policy, error) {
+	if this.Kind != Validating {
+		return nil, nil
+	}
+	var result []*policy
+	for _, w := range this.Webhooks {
+		if w.Policy == "" {
+			continue
+		}
+		validations, complete := w.Validations, true
+		if len(validations) == 0 {
+			if h, ok := w.Handler.(PolicyHandler); ok {
+				validations, complete = h.Validations()
+			}
+		}
+		if len(validations) == 0 {
+			continue
+		}
+		spec := &policySpec{
+			MatchConstraints: matchResources{
+				NamespaceSelector: w.NamespaceSelector,
+				ObjectSelector:    w.ObjectSelector,
+				ResourceRules:     w.Rules,
+			},
+			MatchConditions: w.MatchConditions,
+			Validations:     validations,
+		}
+		if w.FailurePolicy != "" {
+			failurePolicy := w.FailurePolicy
+			spec.FailurePolicy = &failurePolicy
+		}
+		p, err := runtime.DefaultUnstructuredConverter.ToUnstructured(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy for webhook %s: %s", w.Name, err)
+		}
+		b, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&bindingSpec{
+			PolicyName:        w.Name,
+			ValidationActions: []string{"Deny"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy binding for webhook %s: %s", w.Name, err)
+		}
+		result = append(result, &policy{
+			name:    w.Name,
+			replace: w.Policy == PolicyPreferred && complete,
+			spec:    p,
+			binding: b,
+		})
+	}
+	return result, nil
+}
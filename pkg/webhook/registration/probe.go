@@ -0,0 +1,143 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package registration
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gardener/controller-manager-library/pkg/logger"
+	"github.com/gardener/controller-manager-library/pkg/server/readyz"
+)
+
+const probeTimeout = 10 * time.Second
+
+// probe performs the self-test of the webhook endpoints and keeps
+// the results for the readiness checks.
+type probe struct {
+	lock    sync.RWMutex
+	logger  logger.LogContext
+	local   string
+	results map[string]error
+}
+
+func newProbe(logger logger.LogContext, local string) *probe {
+	return &probe{
+		logger:  logger,
+		local:   local,
+		results: map[string]error{},
+	}
+}
+
+func (this *probe) key(cfg *Configuration) string {
+	return "webhook/" + cfg.Name
+}
+
+func (this *probe) register(cfg *Configuration) {
+	key := this.key(cfg)
+	this.lock.Lock()
+	this.results[key] = fmt.Errorf("self-test pending")
+	this.lock.Unlock()
+	readyz.Register(key, func() error {
+		this.lock.RLock()
+		defer this.lock.RUnlock()
+		return this.results[key]
+	})
+}
+
+func (this *probe) check(cfg *Configuration, bundle []byte) error {
+	key := this.key(cfg)
+	this.lock.RLock()
+	done := this.results[key] == nil
+	this.lock.RUnlock()
+	if done {
+		return nil
+	}
+
+	var err error
+	for _, w := range cfg.Webhooks {
+		if err = this.request(cfg, w.Path, bundle); err != nil {
+			err = fmt.Errorf("self-test of webhook %s failed: %s", w.Name, err)
+			break
+		}
+	}
+	this.lock.Lock()
+	this.results[key] = err
+	this.lock.Unlock()
+	if err == nil {
+		this.logger.Infof("self-test of webhooks of %s %s succeeded", cfg.Kind, cfg.Name)
+	}
+	return err
+}
+
+// request sends a request to a webhook endpoint. The request is not
+// a valid review, any HTTP response proves a working TLS setup.
+func (this *probe) request(cfg *Configuration, path string, bundle []byte) error {
+	var target *url.URL
+	if cfg.Service != nil {
+		port := cfg.Service.Port
+		if port == 0 {
+			port = 443
+		}
+		target = &url.URL{
+			Scheme: "https",
+			Host:   net.JoinHostPort(cfg.Service.Name+"."+cfg.Service.Namespace+".svc", strconv.Itoa(int(port))),
+			Path:   path,
+		}
+	} else {
+		u, err := url.Parse(cfg.URL + path)
+		if err != nil {
+			return fmt.Errorf("invalid url: %s", err)
+		}
+		target = u
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bundle) {
+		return fmt.Errorf("invalid CA bundle")
+	}
+	dialer := &net.Dialer{Timeout: probeTimeout}
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{
+			RootCAs:    pool,
+			ServerName: target.Hostname(),
+		},
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if this.local != "" {
+				addr = this.local
+			}
+			return dialer.DialContext(ctx, network, addr)
+		},
+	}
+	defer transport.CloseIdleConnections()
+	client := &http.Client{Transport: transport, Timeout: probeTimeout}
+
+	resp, err := client.Get(target.String())
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,324 @@
+/*
+ * Copyright 2019 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ *
+ */
+
+package registration
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/gardener/controller-manager-library/pkg/certs"
+	"github.com/gardener/controller-manager-library/pkg/controllermanager/cluster"
+	"github.com/gardener/controller-manager-library/pkg/logger"
+	"github.com/gardener/controller-manager-library/pkg/resources"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/util/workqueue"
+)
+
+type key struct {
+	kind Kind
+	name string
+}
+
+// Reconciler creates and maintains the declared webhook configurations
+// including the CA bundle of a CA bundle source. The configurations
+// are reconciled on startup, on every CA rotation and whenever a drift
+// is detected. Fields defaulted by the API server are not considered
+// as drift.
+type Reconciler struct {
+	lock    sync.RWMutex
+	logger  logger.LogContext
+	cluster cluster.Interface
+	source  certs.CABundleSource
+	configs map[key]*Configuration
+	queue   workqueue.RateLimitingInterface
+	probe   *probe
+}
+
+func New(logger logger.LogContext, cluster cluster.Interface, source certs.CABundleSource) *Reconciler {
+	return &Reconciler{
+		logger:  logger,
+		cluster: cluster,
+		source:  source,
+		configs: map[key]*Configuration{},
+		queue:   workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "webhookregistration"),
+	}
+}
+
+// Add declares a webhook configuration. Configurations added after
+// the reconciler has been started are reconciled immediately.
+func (this *Reconciler) Add(cfg *Configuration) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	k := key{cfg.Kind, cfg.Name}
+	this.configs[k] = cfg
+	if this.probe != nil {
+		this.probe.register(cfg)
+	}
+	this.queue.Add(k)
+	return nil
+}
+
+// EnableSelfTest enables a self-test of the webhook endpoints after
+// the webhook configurations are reconciled. The test performs a TLS
+// request with the actual CA bundle and the service DNS name as server
+// name to detect broken certificate chains or subject alternative names.
+// The requests are sent to the given local address of the webhook server
+// if set and through the service otherwise. The readiness of the
+// controller manager is reported as failed until the test succeeds.
+// It must be called before configurations are added.
+func (this *Reconciler) EnableSelfTest(local string) *Reconciler {
+	this.lock.Lock()
+	defer this.lock.Unlock()
+	this.probe = newProbe(this.logger, local)
+	return this
+}
+
+func (this *Reconciler) config(k key) *Configuration {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	return this.configs[k]
+}
+
+func (this *Reconciler) resource(kind Kind) (resources.Interface, error) {
+	return this.cluster.Resources().GetUnstructuredByGK(kind.GroupKind())
+}
+
+// Start watches the webhook configurations and the CA bundle source
+// and processes the required updates until the context is done.
+func (this *Reconciler) Start(ctx context.Context) error {
+	for _, kind := range []Kind{Mutating, Validating} {
+		r, err := this.resource(kind)
+		if err != nil {
+			return fmt.Errorf("cannot get resource %s: %s", kind, err)
+		}
+		k := kind
+		handler := func(obj resources.Object) {
+			if this.config(key{k, obj.GetName()}) != nil {
+				this.queue.Add(key{k, obj.GetName()})
+			}
+		}
+		err = r.AddEventHandler(resources.ResourceEventHandlerFuncs{
+			AddFunc:    handler,
+			UpdateFunc: func(old, new resources.Object) { handler(new) },
+			DeleteFunc: handler,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot watch %s: %s", kind, err)
+		}
+	}
+
+	changes := this.source.CAChanges()
+	go func() {
+		<-ctx.Done()
+		this.queue.ShutDown()
+	}()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-changes:
+				this.logger.Infof("CA bundle changed: updating all webhook configurations")
+				this.enqueueAll()
+			}
+		}
+	}()
+	go this.work()
+	return nil
+}
+
+func (this *Reconciler) enqueueAll() {
+	this.lock.RLock()
+	defer this.lock.RUnlock()
+	for k := range this.configs {
+		this.queue.Add(k)
+	}
+}
+
+func (this *Reconciler) work() {
+	for {
+		item, shutdown := this.queue.Get()
+		if shutdown {
+			return
+		}
+		k := item.(key)
+		if err := this.reconcile(k); err != nil {
+			this.logger.Warnf("reconciliation of %s %s failed (retrying): %s", k.kind, k.name, err)
+			this.queue.AddRateLimited(item)
+		} else {
+			this.queue.Forget(item)
+		}
+		this.queue.Done(item)
+	}
+}
+
+func (this *Reconciler) reconcile(k key) error {
+	cfg := this.config(k)
+	if cfg == nil {
+		return nil
+	}
+	bundle := this.source.GetCABundle()
+	if len(bundle) == 0 {
+		return fmt.Errorf("no CA bundle available")
+	}
+	if err := this.update(k, cfg, bundle); err != nil {
+		return err
+	}
+	if this.probe != nil {
+		return this.probe.check(cfg, bundle)
+	}
+	return nil
+}
+
+func (this *Reconciler) update(k key, cfg *Configuration, bundle []byte) error {
+	policies, err := cfg.policies()
+	if err != nil {
+		return err
+	}
+	// webhooks are only replaced by successfully applied policies,
+	// failed policies must not block the webhook configuration
+	skip := map[string]bool{}
+	var errs []error
+	for name, err := range this.applyPolicies(policies) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("policy %s: %s", name, err))
+		} else {
+			skip[name] = true
+		}
+	}
+
+	webhooks, err := cfg.webhooks(bundle, skip)
+	if err != nil {
+		return err
+	}
+	r, err := this.resource(k.kind)
+	if err != nil {
+		return err
+	}
+	if err := this.apply(r, k.name, "webhooks", webhooks); err != nil {
+		errs = append(errs, err)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// applyPolicies creates or updates the given validating admission policies
+// and their bindings. It returns the result for every failed policy and
+// every policy replacing its webhook. Nothing is returned if the cluster
+// does not support them.
+func (this *Reconciler) applyPolicies(policies []*policy) map[string]error {
+	if len(policies) == 0 {
+		return nil
+	}
+	pr, err := this.cluster.Resources().GetUnstructuredByGK(policyGroupKind)
+	if err != nil {
+		return nil
+	}
+	br, err := this.cluster.Resources().GetUnstructuredByGK(bindingGroupKind)
+	if err != nil {
+		return nil
+	}
+	result := map[string]error{}
+	for _, p := range policies {
+		err := this.apply(pr, p.name, "spec", p.spec)
+		if err == nil {
+			err = this.apply(br, p.name, "spec", p.binding)
+		}
+		if p.replace || err != nil {
+			result[p.name] = err
+		}
+	}
+	return result
+}
+
+// apply creates or updates a cluster scoped object to contain
+// the desired value for the given top level field.
+func (this *Reconciler) apply(r resources.Interface, name string, field string, value interface{}) error {
+	o, err := r.GetCached(name)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		obj := r.New(resources.NewObjectName(name))
+		data := obj.Data().(*unstructured.Unstructured)
+		data.Object[field] = value
+		if _, err := r.Create(data); err != nil {
+			return err
+		}
+		this.logger.Infof("created %s %s", r.GroupKind().Kind, name)
+		return nil
+	}
+
+	// check the cached state first to avoid unnecessary requests
+	if covers(o.Data().(*unstructured.Unstructured).Object[field], value) {
+		return nil
+	}
+	mod, err := resources.Modify(o, func(mod *resources.ModificationState) error {
+		data := mod.Data().(*unstructured.Unstructured)
+		if !covers(data.Object[field], value) {
+			data.Object[field] = value
+			mod.Modify(true)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if mod {
+		this.logger.Infof("updated %s %s", r.GroupKind().Kind, name)
+	}
+	return nil
+}
+
+// covers checks whether the actual state contains all fields of
+// the desired state. Additional fields in maps are ignored to
+// tolerate defaulting, lists must match element by element.
+func covers(actual, desired interface{}) bool {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range d {
+			if !covers(a[k], v) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok || len(a) != len(d) {
+			return false
+		}
+		for i := range d {
+			if !covers(a[i], d[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(actual, desired)
+	}
+}